@@ -29,3 +29,30 @@ func (family AddressFamily) String() string {
 		return fmt.Sprintf("ADDRESS_FAMILY_UNKNOWN(%d)", family)
 	}
 }
+
+// Returns distinct AF_INET and AF_INET6 families of 'families', IPv4 first; other families are left out.
+func distinctFamilies(families []AddressFamily) []AddressFamily {
+
+	var v4, v6 bool
+
+	for _, family := range families {
+		switch family {
+		case AF_INET:
+			v4 = true
+		case AF_INET6:
+			v6 = true
+		}
+	}
+
+	distinct := make([]AddressFamily, 0, 2)
+
+	if v4 {
+		distinct = append(distinct, AF_INET)
+	}
+
+	if v6 {
+		distinct = append(distinct, AF_INET6)
+	}
+
+	return distinct
+}
@@ -0,0 +1,172 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+type managedAddress struct {
+	interfaceLuid uint64
+	ip            net.IP
+}
+
+// Keeps unicast IP addresses alive by periodically extending their lifetimes. Addresses are created with a finite
+// lifetime and refreshed well before it expires, so if the process dies (and stops refreshing) the system removes
+// them automatically once the lifetime runs out.
+type AddressLifetimeRefresher struct {
+	lifetime uint32
+	extend   func(interfaceLuid uint64, ip net.IP, lifetime uint32) error
+
+	mutex     sync.Mutex
+	addresses map[string]managedAddress
+
+	runner periodicRunner
+}
+
+// Creates a new refresher which sets valid and preferred lifetimes of managed addresses to 'lifetime' (rounded up to
+// whole seconds) on every refresh. Refreshing starts only after Start() or Refresh() is called.
+func NewAddressLifetimeRefresher(lifetime time.Duration) (*AddressLifetimeRefresher, error) {
+
+	seconds := (lifetime + time.Second - 1) / time.Second
+
+	if seconds <= 0 || seconds >= time.Duration(InfiniteAddressLifetime) {
+		return nil, newKindError(ErrInvalidParameter, "NewAddressLifetimeRefresher() - lifetime %s is out of range",
+			lifetime)
+	}
+
+	return &AddressLifetimeRefresher{
+		lifetime:  uint32(seconds),
+		extend:    extendUnicastAddressLifetime,
+		addresses: make(map[string]managedAddress),
+	}, nil
+}
+
+func managedAddressKey(interfaceLuid uint64, ip net.IP) string {
+	return fmt.Sprintf("%d/%s", interfaceLuid, ip.String())
+}
+
+func extendUnicastAddressLifetime(interfaceLuid uint64, ip net.IP, lifetime uint32) error {
+
+	row, err := getWtMibUnicastipaddressRow(interfaceLuid, &ip)
+
+	if err != nil {
+		return err
+	}
+
+	row.ValidLifetime = lifetime
+	row.PreferredLifetime = lifetime
+
+	return row.set()
+}
+
+// Adds the address to the interface with the refresher's lifetime (other options are taken from 'options', which
+// may be nil), and starts managing it.
+func (r *AddressLifetimeRefresher) AddAddress(ifc *Interface, address *net.IPNet, options *AddressOptions) error {
+
+	opts := AddressOptions{}
+
+	if options != nil {
+		opts = *options
+	}
+
+	opts.ValidLifetime = r.lifetime
+	opts.PreferredLifetime = r.lifetime
+
+	if err := ifc.AddAddressWithOptions(address, &opts); err != nil {
+		return err
+	}
+
+	r.Manage(ifc.Luid, address.IP)
+
+	return nil
+}
+
+// Starts managing an existing address. Its lifetime is extended on the next refresh.
+func (r *AddressLifetimeRefresher) Manage(interfaceLuid uint64, ip net.IP) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	copied := make(net.IP, len(ip))
+	copy(copied, ip)
+
+	r.addresses[managedAddressKey(interfaceLuid, ip)] = managedAddress{interfaceLuid: interfaceLuid, ip: copied}
+}
+
+// Stops managing the address. The address itself isn't touched, so it expires once its current lifetime runs out.
+func (r *AddressLifetimeRefresher) Unmanage(interfaceLuid uint64, ip net.IP) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.addresses, managedAddressKey(interfaceLuid, ip))
+}
+
+// Returns the number of managed addresses.
+func (r *AddressLifetimeRefresher) Len() int {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return len(r.addresses)
+}
+
+// Extends lifetimes of all managed addresses immediately. Addresses which can't be refreshed (i.e. because they were
+// deleted) stay managed; all errors are returned together.
+func (r *AddressLifetimeRefresher) Refresh() error {
+
+	r.mutex.Lock()
+
+	addresses := make([]managedAddress, 0, len(r.addresses))
+
+	for _, a := range r.addresses {
+		addresses = append(addresses, a)
+	}
+
+	r.mutex.Unlock()
+
+	var errs []error
+
+	for _, a := range addresses {
+		if err := r.extend(a.interfaceLuid, a.ip, r.lifetime); err != nil {
+			errs = append(errs, fmt.Errorf("refreshing %s on interface %d: %w", a.ip.String(), a.interfaceLuid, err))
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return multiError(errs)
+	}
+}
+
+// Starts refreshing every 'interval' in a background goroutine. The first refresh is done immediately. 'interval' has
+// to be shorter than the lifetime (a third of it is a reasonable choice, so that a couple of failed refreshes are
+// tolerated). Refresh errors are passed to 'onError' if it isn't nil; the refresher keeps running regardless.
+func (r *AddressLifetimeRefresher) Start(interval time.Duration, onError func(error)) error {
+
+	if interval <= 0 || interval >= time.Duration(r.lifetime)*time.Second {
+		return newKindError(ErrInvalidParameter,
+			"AddressLifetimeRefresher.Start() - interval %s has to be shorter than lifetime %ds", interval, r.lifetime)
+	}
+
+	r.runner.start(interval, r.Refresh, onError)
+
+	return nil
+}
+
+// Stops background refreshing started by Start() and waits for it to finish. Managed addresses are kept, and expire
+// once their current lifetime runs out unless refreshing is started again.
+func (r *AddressLifetimeRefresher) Stop() {
+	r.runner.stop()
+}
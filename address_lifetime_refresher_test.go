@@ -0,0 +1,228 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddressOptionsLifetimes(t *testing.T) {
+
+	tests := []struct {
+		options   *AddressOptions
+		valid     uint32
+		preferred uint32
+		fails     bool
+	}{
+		{nil, InfiniteAddressLifetime, InfiniteAddressLifetime, false},
+		{&AddressOptions{}, InfiniteAddressLifetime, InfiniteAddressLifetime, false},
+		{&AddressOptions{ValidLifetime: 60}, 60, 60, false},
+		{&AddressOptions{ValidLifetime: 60, PreferredLifetime: 30}, 60, 30, false},
+		{&AddressOptions{PreferredLifetime: 30}, InfiniteAddressLifetime, 30, false},
+		{&AddressOptions{ValidLifetime: 30, PreferredLifetime: 60}, 0, 0, true},
+	}
+
+	for _, test := range tests {
+
+		valid, preferred, err := test.options.lifetimes()
+
+		if test.fails {
+			if err == nil {
+				t.Errorf("lifetimes() for %+v didn't return an error, although it's expected.", test.options)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("lifetimes() returned an error: %v", err)
+			continue
+		}
+
+		if valid != test.valid || preferred != test.preferred {
+			t.Errorf("lifetimes() for %+v returned %d/%d, although %d/%d is expected.", test.options, valid,
+				preferred, test.valid, test.preferred)
+		}
+	}
+}
+
+func TestAddressOptionsCopyTo(t *testing.T) {
+
+	row := wtMibUnicastipaddressRow{
+		PrefixOrigin: IpPrefixOriginManual,
+		SuffixOrigin: IpSuffixOriginManual,
+		DadState:     IpDadStateTentative,
+	}
+
+	options := AddressOptions{ValidLifetime: 120, SkipAsSource: true, DadState: IpDadStatePreferred}
+
+	if err := options.copyTo(&row); err != nil {
+		t.Fatalf("copyTo() returned an error: %v", err)
+	}
+
+	if row.ValidLifetime != 120 || row.PreferredLifetime != 120 {
+		t.Errorf("Lifetimes are %d/%d, although 120/120 is expected.", row.ValidLifetime, row.PreferredLifetime)
+	}
+
+	if row.SkipAsSource == 0 {
+		t.Error("SkipAsSource isn't set, although it's expected.")
+	}
+
+	if row.PrefixOrigin != IpPrefixOriginManual || row.SuffixOrigin != IpSuffixOriginManual {
+		t.Errorf("Origins are %s/%s, although defaults are expected to be kept.", row.PrefixOrigin.String(),
+			row.SuffixOrigin.String())
+	}
+
+	if row.DadState != IpDadStatePreferred {
+		t.Errorf("DadState is %s, although IpDadStatePreferred is expected.", row.DadState.String())
+	}
+
+	prefixOrigin := IpPrefixOriginOther
+	suffixOrigin := IpSuffixOriginOther
+	options = AddressOptions{PrefixOrigin: &prefixOrigin, SuffixOrigin: &suffixOrigin}
+
+	if err := options.copyTo(&row); err != nil {
+		t.Fatalf("copyTo() returned an error: %v", err)
+	}
+
+	if row.PrefixOrigin != IpPrefixOriginOther || row.SuffixOrigin != IpSuffixOriginOther {
+		t.Errorf("Origins are %s/%s, although IpPrefixOriginOther/IpSuffixOriginOther is expected.",
+			row.PrefixOrigin.String(), row.SuffixOrigin.String())
+	}
+}
+
+type fakeLifetimeExtender struct {
+	mutex    sync.Mutex
+	extended map[string]uint32
+	failing  map[string]bool
+}
+
+func (f *fakeLifetimeExtender) extend(interfaceLuid uint64, ip net.IP, lifetime uint32) error {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := managedAddressKey(interfaceLuid, ip)
+
+	if f.failing[key] {
+		return errors.New("element not found")
+	}
+
+	f.extended[key] = lifetime
+
+	return nil
+}
+
+func (f *fakeLifetimeExtender) count() int {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return len(f.extended)
+}
+
+func newFakeRefresher(t *testing.T, lifetime time.Duration) (*AddressLifetimeRefresher, *fakeLifetimeExtender) {
+
+	r, err := NewAddressLifetimeRefresher(lifetime)
+
+	if err != nil {
+		t.Fatalf("NewAddressLifetimeRefresher() returned an error: %v", err)
+	}
+
+	f := &fakeLifetimeExtender{extended: make(map[string]uint32), failing: make(map[string]bool)}
+	r.extend = f.extend
+
+	return r, f
+}
+
+func TestNewAddressLifetimeRefresherRange(t *testing.T) {
+
+	if _, err := NewAddressLifetimeRefresher(0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("NewAddressLifetimeRefresher(0) returned %v, although ErrInvalidParameter is expected.", err)
+	}
+
+	r, err := NewAddressLifetimeRefresher(1500 * time.Millisecond)
+
+	if err != nil {
+		t.Fatalf("NewAddressLifetimeRefresher() returned an error: %v", err)
+	}
+
+	if r.lifetime != 2 {
+		t.Errorf("Lifetime is %d, although 2 is expected.", r.lifetime)
+	}
+}
+
+func TestAddressLifetimeRefresherRefresh(t *testing.T) {
+
+	r, f := newFakeRefresher(t, time.Minute)
+
+	ip1 := net.ParseIP("10.0.0.1")
+	ip2 := net.ParseIP("fd00::1")
+
+	r.Manage(1, ip1)
+	r.Manage(1, ip2)
+	r.Manage(1, ip2)
+
+	if r.Len() != 2 {
+		t.Fatalf("Len() returned %d, although 2 is expected.", r.Len())
+	}
+
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh() returned an error: %v", err)
+	}
+
+	if f.extended[managedAddressKey(1, ip1)] != 60 || f.extended[managedAddressKey(1, ip2)] != 60 {
+		t.Errorf("Unexpected extended lifetimes: %v", f.extended)
+	}
+
+	f.failing[managedAddressKey(1, ip1)] = true
+
+	if err := r.Refresh(); err == nil {
+		t.Error("Refresh() didn't return an error, although it's expected.")
+	}
+
+	if r.Len() != 2 {
+		t.Errorf("Len() after failed refresh returned %d, although 2 is expected.", r.Len())
+	}
+
+	r.Unmanage(1, ip1)
+
+	if err := r.Refresh(); err != nil {
+		t.Errorf("Refresh() after Unmanage() returned an error: %v", err)
+	}
+}
+
+func TestAddressLifetimeRefresherStartStop(t *testing.T) {
+
+	r, f := newFakeRefresher(t, time.Minute)
+
+	if err := r.Start(time.Minute, nil); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Start() with interval equal to lifetime returned %v, although ErrInvalidParameter is expected.",
+			err)
+	}
+
+	r.Manage(1, net.ParseIP("10.0.0.1"))
+
+	if err := r.Start(time.Millisecond, nil); err != nil {
+		t.Fatalf("Start() returned an error: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for f.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	r.Stop()
+	r.Stop()
+
+	if f.count() != 1 {
+		t.Errorf("%d addresses were extended, although 1 is expected.", f.count())
+	}
+}
@@ -0,0 +1,91 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"net"
+)
+
+// Lifetime value (in seconds) meaning the address never expires.
+const InfiniteAddressLifetime uint32 = 0xffffffff
+
+// Options of a unicast IP address created by Interface.AddAddressWithOptions(). Zero value (nil for the origins) of any
+// field means that the default set by InitializeUnicastIpAddressEntry is used, which is the same as what
+// Interface.AddAddress() does.
+type AddressOptions struct {
+	// Valid lifetime in seconds. After it expires the address is removed by the system. Zero means infinite.
+	ValidLifetime uint32
+	// Preferred lifetime in seconds. After it expires the address becomes deprecated, and isn't used as a source for
+	// new connections. Zero means the same as ValidLifetime. It can't be greater than ValidLifetime.
+	PreferredLifetime uint32
+	// If true, the address isn't used as a source address (unless explicitly bound).
+	SkipAsSource bool
+	// Origins are pointers, because IpPrefixOriginOther and IpSuffixOriginOther are zero values.
+	PrefixOrigin *NlPrefixOrigin
+	SuffixOrigin *NlSuffixOrigin
+	// Initial DAD state. Setting IpDadStatePreferred skips duplicate address detection, so the address is usable
+	// immediately.
+	DadState NlDadState
+}
+
+// Returns ValidLifetime and PreferredLifetime with defaults applied, or an error if they're inconsistent.
+func (options *AddressOptions) lifetimes() (valid, preferred uint32, err error) {
+
+	valid = InfiniteAddressLifetime
+	preferred = 0
+
+	if options != nil {
+		if options.ValidLifetime != 0 {
+			valid = options.ValidLifetime
+		}
+		preferred = options.PreferredLifetime
+	}
+
+	if preferred == 0 {
+		preferred = valid
+	}
+
+	if preferred > valid {
+		return 0, 0, newKindError(ErrInvalidParameter,
+			"AddressOptions - PreferredLifetime (%d) is greater than ValidLifetime (%d)", preferred, valid)
+	}
+
+	return valid, preferred, nil
+}
+
+func (options *AddressOptions) copyTo(row *wtMibUnicastipaddressRow) error {
+
+	valid, preferred, err := options.lifetimes()
+
+	if err != nil {
+		return err
+	}
+
+	row.ValidLifetime = valid
+	row.PreferredLifetime = preferred
+	row.SkipAsSource = boolToUint8(options.SkipAsSource)
+
+	if options.PrefixOrigin != nil {
+		row.PrefixOrigin = *options.PrefixOrigin
+	}
+
+	if options.SuffixOrigin != nil {
+		row.SuffixOrigin = *options.SuffixOrigin
+	}
+
+	if options.DadState != 0 {
+		row.DadState = options.DadState
+	}
+
+	return nil
+}
+
+// Adds new unicast IP address to the interface, using specified options. If 'options' is nil, it's the same as
+// AddAddress(). Corresponds to CreateUnicastIpAddressEntry function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-createunicastipaddressentry).
+func (ifc *Interface) AddAddressWithOptions(address *net.IPNet, options *AddressOptions) error {
+	return createAndAddWtMibUnicastipaddressRowWithOptions(ifc.Luid, address, options)
+}
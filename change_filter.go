@@ -0,0 +1,135 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "net"
+
+// Restricts which change notifications a callback receives. Zero value (or nil) matches everything. Filtering is
+// done before notifications are queued for the callback, so filtered out changes cost almost nothing.
+type ChangeFilter struct {
+	// AF_INET or AF_INET6 to receive only changes of that family; AF_UNSPEC for both. The OS registration is made
+	// only for families some callback is interested in.
+	Family AddressFamily
+	// If not empty, only changes of these interfaces are delivered.
+	InterfaceLuids []uint64
+	// If not nil, only route changes whose destination prefix lies within this prefix (i.e. is equal to it or more
+	// specific) are delivered. Can be used with route change callbacks only. If Family is AF_UNSPEC, it's implied by
+	// the prefix.
+	DestinationPrefix *net.IPNet
+	// If not empty, only these notification types are delivered.
+	NotificationTypes []MibNotificationType
+}
+
+type compiledChangeFilter struct {
+	family            AddressFamily
+	interfaceLuids    map[uint64]bool
+	destinationPrefix *net.IPNet
+	notificationTypes map[MibNotificationType]bool
+}
+
+func (filter *ChangeFilter) compile(allowDestinationPrefix bool) (*compiledChangeFilter, error) {
+
+	compiled := &compiledChangeFilter{family: AF_UNSPEC}
+
+	if filter == nil {
+		return compiled, nil
+	}
+
+	switch filter.Family {
+	case AF_UNSPEC, AF_INET, AF_INET6:
+		compiled.family = filter.Family
+	default:
+		return nil, newKindError(ErrInvalidParameter, "ChangeFilter - unsupported family %s",
+			filter.Family.String())
+	}
+
+	if filter.DestinationPrefix != nil {
+
+		if !allowDestinationPrefix {
+			return nil, newKindError(ErrInvalidParameter,
+				"ChangeFilter - DestinationPrefix is supported for route changes only")
+		}
+
+		prefixFamily := ipFamily(filter.DestinationPrefix.IP)
+
+		if prefixFamily == AF_UNSPEC {
+			return nil, newKindError(ErrInvalidParameter, "ChangeFilter - invalid DestinationPrefix")
+		}
+
+		if compiled.family != AF_UNSPEC && compiled.family != prefixFamily {
+			return nil, newKindError(ErrInvalidParameter,
+				"ChangeFilter - DestinationPrefix %s doesn't belong to family %s", filter.DestinationPrefix.String(),
+				compiled.family.String())
+		}
+
+		compiled.family = prefixFamily
+		compiled.destinationPrefix = filter.DestinationPrefix
+	}
+
+	if len(filter.InterfaceLuids) > 0 {
+		compiled.interfaceLuids = make(map[uint64]bool, len(filter.InterfaceLuids))
+		for _, luid := range filter.InterfaceLuids {
+			compiled.interfaceLuids[luid] = true
+		}
+	}
+
+	if len(filter.NotificationTypes) > 0 {
+		compiled.notificationTypes = make(map[MibNotificationType]bool, len(filter.NotificationTypes))
+		for _, t := range filter.NotificationTypes {
+			compiled.notificationTypes[t] = true
+		}
+	}
+
+	return compiled, nil
+}
+
+// Returns families for which the OS registration is needed.
+func (filter *compiledChangeFilter) families() []uint16 {
+
+	if filter.family == AF_UNSPEC {
+		return []uint16{uint16(AF_INET), uint16(AF_INET6)}
+	}
+
+	return []uint16{uint16(filter.family)}
+}
+
+// Changes of unknown family (AF_UNSPEC) match only filters which don't restrict the family.
+func (filter *compiledChangeFilter) matches(family AddressFamily, interfaceLuid uint64,
+	notificationType MibNotificationType) bool {
+
+	if filter.family != AF_UNSPEC && filter.family != family {
+		return false
+	}
+
+	if filter.interfaceLuids != nil && !filter.interfaceLuids[interfaceLuid] {
+		return false
+	}
+
+	if filter.notificationTypes != nil && !filter.notificationTypes[notificationType] {
+		return false
+	}
+
+	return true
+}
+
+func (filter *compiledChangeFilter) matchesRoute(route *Route, notificationType MibNotificationType) bool {
+
+	if route == nil || !filter.matches(route.DestinationPrefix.Prefix.Family, route.InterfaceLuid, notificationType) {
+		return false
+	}
+
+	if filter.destinationPrefix == nil {
+		return true
+	}
+
+	filterLength, _ := filter.destinationPrefix.Mask.Size()
+
+	if int(route.DestinationPrefix.PrefixLength) < filterLength {
+		return false
+	}
+
+	return filter.destinationPrefix.Contains(route.DestinationPrefix.Prefix.Address)
+}
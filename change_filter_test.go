@@ -0,0 +1,133 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestChangeFilterCompile(t *testing.T) {
+
+	_, v4Prefix, _ := net.ParseCIDR("10.0.0.0/8")
+
+	tests := []struct {
+		filter      *ChangeFilter
+		allowPrefix bool
+		families    []AddressFamily
+		fails       bool
+	}{
+		{nil, false, []AddressFamily{AF_INET, AF_INET6}, false},
+		{&ChangeFilter{}, false, []AddressFamily{AF_INET, AF_INET6}, false},
+		{&ChangeFilter{Family: AF_INET6}, false, []AddressFamily{AF_INET6}, false},
+		{&ChangeFilter{Family: AddressFamily(99)}, false, nil, true},
+		{&ChangeFilter{DestinationPrefix: v4Prefix}, false, nil, true},
+		{&ChangeFilter{DestinationPrefix: v4Prefix}, true, []AddressFamily{AF_INET}, false},
+		{&ChangeFilter{Family: AF_INET6, DestinationPrefix: v4Prefix}, true, nil, true},
+	}
+
+	for i, test := range tests {
+
+		compiled, err := test.filter.compile(test.allowPrefix)
+
+		if test.fails {
+			if !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("compile() of filter #%d returned %v, although an ErrInvalidParameter error is expected.", i,
+					err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("compile() of filter #%d returned an error: %v", i, err)
+			continue
+		}
+
+		families := compiled.families()
+
+		if len(families) != len(test.families) {
+			t.Errorf("families() of filter #%d returned %v, although %v is expected.", i, families, test.families)
+			continue
+		}
+
+		for j := range families {
+			if families[j] != uint16(test.families[j]) {
+				t.Errorf("families() of filter #%d returned %v, although %v is expected.", i, families,
+					test.families)
+			}
+		}
+	}
+}
+
+func TestChangeFilterMatches(t *testing.T) {
+
+	compiled, err := (&ChangeFilter{
+		Family:            AF_INET,
+		InterfaceLuids:    []uint64{1, 2},
+		NotificationTypes: []MibNotificationType{MibAddInstance, MibDeleteInstance},
+	}).compile(false)
+
+	if err != nil {
+		t.Fatalf("compile() returned an error: %v", err)
+	}
+
+	tests := []struct {
+		family           AddressFamily
+		luid             uint64
+		notificationType MibNotificationType
+		matches          bool
+	}{
+		{AF_INET, 1, MibAddInstance, true},
+		{AF_INET, 2, MibDeleteInstance, true},
+		{AF_INET6, 1, MibAddInstance, false},
+		{AF_INET, 3, MibAddInstance, false},
+		{AF_INET, 1, MibParameterNotification, false},
+		{AF_UNSPEC, 1, MibAddInstance, false},
+	}
+
+	for _, test := range tests {
+		if compiled.matches(test.family, test.luid, test.notificationType) != test.matches {
+			t.Errorf("matches(%s, %d, %s) didn't return %v.", test.family.String(), test.luid,
+				test.notificationType.String(), test.matches)
+		}
+	}
+
+	all, _ := (*ChangeFilter)(nil).compile(false)
+
+	if !all.matches(AF_UNSPEC, 42, MibInitialNotification) {
+		t.Error("Empty filter doesn't match everything.")
+	}
+}
+
+func TestChangeFilterMatchesRoute(t *testing.T) {
+
+	_, prefix, _ := net.ParseCIDR("10.0.0.0/8")
+
+	compiled, err := (&ChangeFilter{DestinationPrefix: prefix}).compile(true)
+
+	if err != nil {
+		t.Fatalf("compile() returned an error: %v", err)
+	}
+
+	tests := []struct {
+		route   *Route
+		matches bool
+	}{
+		{fakeRoute(1, "10.0.0.0/8", 0), true},
+		{fakeRoute(1, "10.1.2.0/24", 0), true},
+		{fakeRoute(1, "0.0.0.0/0", 0), false},
+		{fakeRoute(1, "192.168.0.0/16", 0), false},
+		{fakeRoute(1, "fd00::/8", 0), false},
+		{nil, false},
+	}
+
+	for _, test := range tests {
+		if compiled.matchesRoute(test.route, MibAddInstance) != test.matches {
+			t.Errorf("matchesRoute(%v) didn't return %v.", test.route, test.matches)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// NET_IF_COMPARTMENT_ID_UNSPECIFIED defined in ifdef.h
+	CompartmentIdUnspecified uint32 = 0
+	// NET_IF_COMPARTMENT_ID_PRIMARY defined in ifdef.h
+	CompartmentIdPrimary uint32 = 1
+)
+
+// Returns the network compartment of the calling OS thread. Corresponds to GetCurrentThreadCompartmentId function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getcurrentthreadcompartmentid).
+// Note that goroutines may migrate between OS threads; use WithCompartment() to run code in a specific compartment.
+func GetCurrentThreadCompartmentId() uint32 {
+	return getCurrentThreadCompartmentId()
+}
+
+// Runs 'fn' on a dedicated OS thread switched to the network compartment 'compartmentId', and returns the error
+// returned by 'fn'. All table queries and mutations done by 'fn' on the calling goroutine are scoped to that
+// compartment; goroutines started by 'fn' aren't. The thread's original compartment is restored afterwards. If it
+// can't be restored, the thread is terminated rather than returned to the scheduler. Panics in 'fn' are propagated
+// to the caller.
+func WithCompartment(compartmentId uint32, fn func() error) error {
+
+	if compartmentId == CompartmentIdUnspecified {
+		return newKindError(ErrInvalidParameter, "WithCompartment() - compartment ID has to be specified")
+	}
+
+	type outcome struct {
+		err       error
+		panicked  bool
+		panicData interface{}
+	}
+
+	done := make(chan outcome, 1)
+
+	go func() {
+
+		// The goroutine is never unlocked if restoring the compartment fails, so the runtime terminates the thread
+		// when the goroutine exits, instead of reusing it in the wrong compartment.
+		runtime.LockOSThread()
+
+		original := getCurrentThreadCompartmentId()
+
+		if result := setCurrentThreadCompartmentId(compartmentId); result != 0 {
+			runtime.UnlockOSThread()
+			done <- outcome{err: newOperationError("iphlpapi.SetCurrentThreadCompartmentId", 0, "", windows.Errno(result))}
+			return
+		}
+
+		o := outcome{panicked: true}
+
+		func() {
+			defer func() {
+				if o.panicked {
+					o.panicData = recover()
+				}
+			}()
+			o.err = fn()
+			o.panicked = false
+		}()
+
+		if result := setCurrentThreadCompartmentId(original); result == 0 {
+			runtime.UnlockOSThread()
+		} else if o.err == nil && !o.panicked {
+			o.err = fmt.Errorf("WithCompartment() - restoring compartment %d failed: %w", original,
+				newOperationError("iphlpapi.SetCurrentThreadCompartmentId", 0, "", windows.Errno(result)))
+		}
+
+		done <- o
+	}()
+
+	o := <-done
+
+	if o.panicked {
+		panic(o.panicData)
+	}
+
+	return o.err
+}
+
+// The same as GetRoutes(), but queries the network compartment 'compartmentId'.
+func GetRoutesInCompartment(compartmentId uint32, family AddressFamily) ([]*Route, error) {
+
+	var routes []*Route
+
+	err := WithCompartment(compartmentId, func() (err error) {
+		routes, err = GetRoutes(family)
+		return
+	})
+
+	return routes, err
+}
+
+// The same as GetUnicastAddresses(), but queries the network compartment 'compartmentId'.
+func GetUnicastAddressesInCompartment(compartmentId uint32, family AddressFamily) ([]*UnicastIpAddressRow, error) {
+
+	var addresses []*UnicastIpAddressRow
+
+	err := WithCompartment(compartmentId, func() (err error) {
+		addresses, err = GetUnicastAddresses(family)
+		return
+	})
+
+	return addresses, err
+}
+
+// The same as GetIpInterfaces(), but queries the network compartment 'compartmentId'.
+func GetIpInterfacesInCompartment(compartmentId uint32, family AddressFamily) ([]*IpInterface, error) {
+
+	var ipifcs []*IpInterface
+
+	err := WithCompartment(compartmentId, func() (err error) {
+		ipifcs, err = GetIpInterfaces(family)
+		return
+	})
+
+	return ipifcs, err
+}
@@ -0,0 +1,76 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithCompartment(t *testing.T) {
+
+	current := GetCurrentThreadCompartmentId()
+
+	if current == CompartmentIdUnspecified {
+		t.Errorf("GetCurrentThreadCompartmentId() returned unspecified compartment ID.")
+		return
+	}
+
+	var inside uint32
+
+	err := WithCompartment(current, func() error {
+		inside = GetCurrentThreadCompartmentId()
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("WithCompartment() returned an error: %v", err)
+	} else if inside != current {
+		t.Errorf("Compartment inside WithCompartment() is %d, although %d is expected.", inside, current)
+	}
+
+	expected := errors.New("expected")
+
+	if err := WithCompartment(current, func() error { return expected }); err != expected {
+		t.Errorf("WithCompartment() returned %v, although the error returned by fn is expected.", err)
+	}
+
+	if err := WithCompartment(CompartmentIdUnspecified, func() error { return nil }); !errors.Is(err,
+		ErrInvalidParameter) {
+		t.Errorf("WithCompartment() with unspecified compartment ID returned %v, although ErrInvalidParameter is "+
+			"expected.", err)
+	}
+}
+
+func TestWithCompartmentPanic(t *testing.T) {
+
+	defer func() {
+		if r := recover(); r != "expected" {
+			t.Errorf("Recovered %v, although the panic from fn is expected.", r)
+		}
+	}()
+
+	_ = WithCompartment(CompartmentIdPrimary, func() error {
+		panic("expected")
+	})
+
+	t.Error("WithCompartment() didn't propagate the panic.")
+}
+
+func TestGetInCompartment(t *testing.T) {
+
+	if _, err := GetRoutesInCompartment(CompartmentIdPrimary, AF_UNSPEC); err != nil {
+		t.Errorf("GetRoutesInCompartment() returned an error: %v", err)
+	}
+
+	if _, err := GetUnicastAddressesInCompartment(CompartmentIdPrimary, AF_UNSPEC); err != nil {
+		t.Errorf("GetUnicastAddressesInCompartment() returned an error: %v", err)
+	}
+
+	if _, err := GetIpInterfacesInCompartment(CompartmentIdPrimary, AF_UNSPEC); err != nil {
+		t.Errorf("GetIpInterfacesInCompartment() returned an error: %v", err)
+	}
+}
@@ -77,6 +77,32 @@ const (
 
 	wtMibIfTable2_Table_Offset = 8
 
+	wtMibMulticastipaddressRow_Size = 48
+
+	wtMibMulticastipaddressRow_InterfaceIndex_Offset = 28
+	wtMibMulticastipaddressRow_InterfaceLuid_Offset  = 32
+	wtMibMulticastipaddressRow_ScopeId_Offset        = 40
+
+	wtMibMulticastipaddressTable_Size = 56
+
+	wtMibMulticastipaddressTable_Table_Offset = 8
+
+	wtMibIfstackRow_Size = 8
+
+	wtMibIfstackRow_LowerLayerInterfaceIndex_Offset = 4
+
+	wtMibIfstackTable_Size = 12
+
+	wtMibIfstackTable_Table_Offset = 4
+
+	wtMibInvertedifstackRow_Size = 8
+
+	wtMibInvertedifstackRow_HigherLayerInterfaceIndex_Offset = 4
+
+	wtMibInvertedifstackTable_Size = 12
+
+	wtMibInvertedifstackTable_Table_Offset = 4
+
 	wtMibIpinterfaceRow_Size = 168
 
 	wtMibIpinterfaceRow_InterfaceLuid_Offset                        = 8
@@ -139,6 +165,60 @@ const (
 
 	wtMibIpforwardTable2_Table_Offset = 8
 
+	wtMibIppathRow_Size = 136
+
+	wtMibIppathRow_Destination_Offset       = 28
+	wtMibIppathRow_InterfaceLuid_Offset     = 56
+	wtMibIppathRow_InterfaceIndex_Offset    = 64
+	wtMibIppathRow_CurrentNextHop_Offset    = 68
+	wtMibIppathRow_PathMtu_Offset           = 96
+	wtMibIppathRow_RttMean_Offset           = 100
+	wtMibIppathRow_RttDeviation_Offset      = 104
+	wtMibIppathRow_LastReachable_Offset     = 108
+	wtMibIppathRow_IsReachable_Offset       = 112
+	wtMibIppathRow_LinkTransmitSpeed_Offset = 120
+	wtMibIppathRow_LinkReceiveSpeed_Offset  = 128
+
+	wtMibIppathTable_Size = 144
+
+	wtMibIppathTable_Table_Offset = 8
+
+	wtMibIpstats_Size = 92
+
+	wtMibIpstats_DefaultTTL_Offset    = 4
+	wtMibIpstats_InReceives_Offset    = 8
+	wtMibIpstats_ForwDatagrams_Offset = 20
+	wtMibIpstats_OutRequests_Offset   = 36
+	wtMibIpstats_ReasmTimeout_Offset  = 52
+	wtMibIpstats_ReasmFails_Offset    = 64
+	wtMibIpstats_FragCreates_Offset   = 76
+	wtMibIpstats_NumRoutes_Offset     = 88
+
+	wtMibIcmpEx_Size = 2064
+
+	wtMibIcmpEx_icmpOutStats_Offset = 1032
+
+	wtMibIcmpstatsEx_Size = 1032
+
+	wtMibIcmpstatsEx_Errors_Offset    = 4
+	wtMibIcmpstatsEx_TypeCount_Offset = 8
+
+	wtMibTcpstats2_Size = 72
+
+	wtMibTcpstats2_RtoMin_Offset      = 4
+	wtMibTcpstats2_CurrEstab_Offset   = 32
+	wtMibTcpstats2_InSegs_Offset      = 40
+	wtMibTcpstats2_OutSegs_Offset     = 48
+	wtMibTcpstats2_RetransSegs_Offset = 56
+	wtMibTcpstats2_NumConns_Offset    = 68
+
+	wtMibUdpstats2_Size = 32
+
+	wtMibUdpstats2_NoPorts_Offset      = 8
+	wtMibUdpstats2_InErrors_Offset     = 12
+	wtMibUdpstats2_OutDatagrams_Offset = 16
+	wtMibUdpstats2_NumAddrs_Offset     = 24
+
 	wtMibUnicastipaddressRow_Size = 80
 
 	wtMibUnicastipaddressRow_InterfaceLuid_Offset      = 32
@@ -101,4 +101,8 @@ const (
 	wtIpAdapterWinsServerAddressLh_Reserved_Offset = 4
 	wtIpAdapterWinsServerAddressLh_Next_Offset     = 8
 	wtIpAdapterWinsServerAddressLh_Address_Offset  = 12
+
+	wtSockaddrIn6Pair_Size = 8
+
+	wtSockaddrIn6Pair_DestinationAddress_Offset = 4
 )
@@ -102,4 +102,8 @@ const (
 	wtIpAdapterWinsServerAddressLh_Reserved_Offset = 4
 	wtIpAdapterWinsServerAddressLh_Next_Offset     = 8
 	wtIpAdapterWinsServerAddressLh_Address_Offset  = 16
+
+	wtSockaddrIn6Pair_Size = 16
+
+	wtSockaddrIn6Pair_DestinationAddress_Offset = 8
 )
@@ -0,0 +1,161 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// Registry keys holding per-interface IPv4 and IPv6 parameters, including DNS servers (both static and DHCP
+// assigned). Windows doesn't notify DNS server changes otherwise.
+var dnsServerRegistryKeys = []string{
+	`SYSTEM\CurrentControlSet\Services\Tcpip\Parameters\Interfaces`,
+	`SYSTEM\CurrentControlSet\Services\Tcpip6\Parameters\Interfaces`,
+}
+
+type dnsChangeWatcher struct {
+	changed func()
+	onError func(err error)
+
+	keys []registry.Key
+	// Change events of keys (in the same order), followed by the stop event.
+	events []windows.Handle
+	done   chan struct{}
+}
+
+// Watches registry keys holding DNS servers of interfaces, and calls 'changed' from its own goroutine whenever any
+// value below them changes. 'onError' (may be nil) receives errors which stop watching. Call the returned function to
+// stop watching.
+func watchDnsServerChanges(changed func(), onError func(err error)) (stop func() error, err error) {
+
+	w := &dnsChangeWatcher{changed: changed, onError: onError, done: make(chan struct{})}
+
+	for _, path := range dnsServerRegistryKeys {
+
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.NOTIFY)
+
+		if err != nil {
+			w.close()
+			return nil, fmt.Errorf("watchDnsServerChanges() - opening %s failed: %w", path, err)
+		}
+
+		w.keys = append(w.keys, key)
+	}
+
+	for i := 0; i <= len(w.keys); i++ {
+
+		// Auto-reset events.
+		event, err := windows.CreateEvent(nil, 0, 0, nil)
+
+		if err != nil {
+			w.close()
+			return nil, fmt.Errorf("watchDnsServerChanges() - CreateEvent failed: %w", err)
+		}
+
+		w.events = append(w.events, event)
+	}
+
+	started := make(chan error)
+	go w.run(started)
+
+	if err := <-started; err != nil {
+		<-w.done
+		w.close()
+		return nil, err
+	}
+
+	return w.stop, nil
+}
+
+// Asks for the key's event to be signaled on the next change below it.
+func (w *dnsChangeWatcher) arm(i int) error {
+
+	err := windows.RegNotifyChangeKeyValue(windows.Handle(w.keys[i]), true,
+		windows.REG_NOTIFY_CHANGE_NAME|windows.REG_NOTIFY_CHANGE_LAST_SET, w.events[i], true)
+
+	if err != nil {
+		return fmt.Errorf("watchDnsServerChanges() - RegNotifyChangeKeyValue failed: %w", err)
+	}
+
+	return nil
+}
+
+func (w *dnsChangeWatcher) run(started chan<- error) {
+
+	defer close(w.done)
+
+	// Notification requests are canceled when the thread which made them exits, so they're all made on this thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	for i := range w.keys {
+		if err := w.arm(i); err != nil {
+			started <- err
+			return
+		}
+	}
+
+	close(started)
+
+	for {
+
+		result, err := windows.WaitForMultipleObjects(w.events, false, windows.INFINITE)
+
+		if err != nil {
+			w.fail(fmt.Errorf("watchDnsServerChanges() - WaitForMultipleObjects failed: %w", err))
+			return
+		}
+
+		i := int(result - windows.WAIT_OBJECT_0)
+
+		if i < 0 || i >= len(w.keys) {
+			// The stop event.
+			return
+		}
+
+		if err := w.arm(i); err != nil {
+			w.fail(err)
+			return
+		}
+
+		w.changed()
+	}
+}
+
+func (w *dnsChangeWatcher) fail(err error) {
+
+	if w.onError != nil {
+		w.onError(err)
+	}
+}
+
+// Stops watching, and waits until 'changed' isn't running. It must not be called from 'changed'.
+func (w *dnsChangeWatcher) stop() error {
+
+	if err := windows.SetEvent(w.events[len(w.keys)]); err != nil {
+		return fmt.Errorf("watchDnsServerChanges() - SetEvent failed: %w", err)
+	}
+
+	<-w.done
+	w.close()
+
+	return nil
+}
+
+func (w *dnsChangeWatcher) close() {
+
+	for _, key := range w.keys {
+		_ = key.Close()
+	}
+
+	for _, event := range w.events {
+		_ = windows.CloseHandle(event)
+	}
+}
@@ -0,0 +1,217 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// What an Ensure... call had to do to reach the desired state.
+type EnsureResult uint32
+
+const (
+	// The desired state was already in place.
+	EnsureUnchanged EnsureResult = iota
+	// The row was created.
+	EnsureCreated
+	// The row existed, but its mutable fields (metric, lifetimes...) were updated.
+	EnsureUpdated
+	// The row was deleted.
+	EnsureDeleted
+)
+
+func (result EnsureResult) String() string {
+	switch result {
+	case EnsureUnchanged:
+		return "EnsureUnchanged"
+	case EnsureCreated:
+		return "EnsureCreated"
+	case EnsureUpdated:
+		return "EnsureUpdated"
+	case EnsureDeleted:
+		return "EnsureDeleted"
+	default:
+		return fmt.Sprintf("EnsureResult_UNKNOWN(%d)", result)
+	}
+}
+
+// Makes sure the interface has unicast IP address 'address' with 'options' (which may be nil, see
+// AddAddressWithOptions()). Unlike AddAddress(), it's not an error if the address already exists; if its prefix
+// length, lifetimes, SkipAsSource or origins differ, it's updated with SetUnicastIpAddressEntry. Note that lifetimes of
+// an existing address count down, so an address with finite lifetimes is always updated, which restarts them.
+func (ifc *Interface) EnsureAddress(address *net.IPNet, options *AddressOptions) (EnsureResult, error) {
+
+	desired, err := newWtMibUnicastipaddressRow(ifc.Luid, address, options)
+
+	if err != nil {
+		return EnsureUnchanged, err
+	}
+
+	// Creating first (rather than looking the address up first) leaves no window for another process to add it.
+	err = desired.add()
+
+	if err == nil {
+		return EnsureCreated, nil
+	}
+
+	if !errors.Is(err, ErrAlreadyExists) {
+		return EnsureUnchanged, err
+	}
+
+	existing, gerr := getWtMibUnicastipaddressRow(ifc.Luid, &address.IP)
+
+	if gerr != nil {
+		// The address exists on another interface.
+		return EnsureUnchanged, err
+	}
+
+	if !existing.updateFrom(desired) {
+		return EnsureUnchanged, nil
+	}
+
+	if err := existing.set(); err != nil {
+		return EnsureUnchanged, err
+	}
+
+	return EnsureUpdated, nil
+}
+
+// Copies read-write fields of 'desired' which differ into the row, and returns whether there were any. Origins equal
+// to IpPrefixOriginUnchanged/IpSuffixOriginUnchanged (the defaults) aren't compared.
+func (row *wtMibUnicastipaddressRow) updateFrom(desired *wtMibUnicastipaddressRow) bool {
+
+	updated := false
+
+	if desired.PrefixOrigin != IpPrefixOriginUnchanged && row.PrefixOrigin != desired.PrefixOrigin {
+		row.PrefixOrigin = desired.PrefixOrigin
+		updated = true
+	}
+
+	if desired.SuffixOrigin != IpSuffixOriginUnchanged && row.SuffixOrigin != desired.SuffixOrigin {
+		row.SuffixOrigin = desired.SuffixOrigin
+		updated = true
+	}
+
+	if row.ValidLifetime != desired.ValidLifetime || row.PreferredLifetime != desired.PreferredLifetime {
+		row.ValidLifetime = desired.ValidLifetime
+		row.PreferredLifetime = desired.PreferredLifetime
+		updated = true
+	}
+
+	if row.OnLinkPrefixLength != desired.OnLinkPrefixLength {
+		row.OnLinkPrefixLength = desired.OnLinkPrefixLength
+		updated = true
+	}
+
+	if row.SkipAsSource != desired.SkipAsSource {
+		row.SkipAsSource = desired.SkipAsSource
+		updated = true
+	}
+
+	return updated
+}
+
+// Makes sure the interface doesn't have unicast IP address 'ip'. Unlike DeleteAddress(), it's not an error if the
+// address (or the interface) doesn't exist.
+func (ifc *Interface) EnsureAddressAbsent(ip *net.IP) (EnsureResult, error) {
+
+	row, err := getWtMibUnicastipaddressRow(ifc.Luid, ip)
+
+	if err == nil {
+		err = row.delete()
+	}
+
+	return ensureDeleted(err)
+}
+
+// Makes sure the interface has the route described by 'routeData'. Unlike AddRoute(), it's not an error if the route
+// already exists; if its metric differs, it's updated with SetIpForwardEntry2.
+func (ifc *Interface) EnsureRoute(routeData *RouteData) (EnsureResult, error) {
+
+	desired, err := newWtMibIpforwardRow2(ifc.Luid, routeData)
+
+	if err != nil {
+		return EnsureUnchanged, err
+	}
+
+	err = desired.add()
+
+	if err == nil {
+		return EnsureCreated, nil
+	}
+
+	if !errors.Is(err, ErrAlreadyExists) {
+		return EnsureUnchanged, err
+	}
+
+	existing, err := getWtMibIpforwardRow2(ifc.Luid, &desired.DestinationPrefix, &desired.NextHop)
+
+	if err != nil {
+		return EnsureUnchanged, err
+	}
+
+	if existing.Metric == desired.Metric {
+		return EnsureUnchanged, nil
+	}
+
+	existing.Metric = desired.Metric
+
+	if err := existing.set(); err != nil {
+		return EnsureUnchanged, err
+	}
+
+	return EnsureUpdated, nil
+}
+
+// Makes sure the interface doesn't have the route determined by 'destination' and 'nextHop'. Unlike DeleteRoute(),
+// it's not an error if the route (or the interface) doesn't exist.
+func (ifc *Interface) EnsureRouteAbsent(destination *net.IPNet, nextHop *net.IP) (EnsureResult, error) {
+
+	row, err := getWtMibIpforwardRow2Alt(ifc.Luid, destination, nextHop)
+
+	if err == nil {
+		err = row.delete()
+	}
+
+	return ensureDeleted(err)
+}
+
+// Makes sure the anycast IP address exists. Unlike Add(), it's not an error if it already does. Anycast rows have no
+// mutable fields, so there's nothing to update.
+func (aia *AnycastIpAddressRow) Ensure() (EnsureResult, error) {
+
+	err := aia.Add()
+
+	switch {
+	case err == nil:
+		return EnsureCreated, nil
+	case errors.Is(err, ErrAlreadyExists):
+		return EnsureUnchanged, nil
+	default:
+		return EnsureUnchanged, err
+	}
+}
+
+// Makes sure the anycast IP address doesn't exist. Unlike Delete(), it's not an error if it (or the interface)
+// doesn't.
+func (aia *AnycastIpAddressRow) EnsureAbsent() (EnsureResult, error) {
+	return ensureDeleted(aia.Delete())
+}
+
+// Interprets the error of looking a row up and deleting it, for which a missing row means success.
+func ensureDeleted(err error) (EnsureResult, error) {
+
+	switch {
+	case err == nil:
+		return EnsureDeleted, nil
+	case errors.Is(err, ErrNotFound):
+		return EnsureUnchanged, nil
+	default:
+		return EnsureUnchanged, err
+	}
+}
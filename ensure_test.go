@@ -0,0 +1,206 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+func TestUnicastRowUpdateFrom(t *testing.T) {
+
+	existing := func() *wtMibUnicastipaddressRow {
+		return &wtMibUnicastipaddressRow{
+			PrefixOrigin:       IpPrefixOriginManual,
+			SuffixOrigin:       IpSuffixOriginManual,
+			ValidLifetime:      InfiniteAddressLifetime,
+			PreferredLifetime:  InfiniteAddressLifetime,
+			OnLinkPrefixLength: 24,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		desired wtMibUnicastipaddressRow
+		updated bool
+	}{
+		{"same", wtMibUnicastipaddressRow{PrefixOrigin: IpPrefixOriginUnchanged,
+			SuffixOrigin: IpSuffixOriginUnchanged, ValidLifetime: InfiniteAddressLifetime,
+			PreferredLifetime: InfiniteAddressLifetime, OnLinkPrefixLength: 24}, false},
+		{"prefix length", wtMibUnicastipaddressRow{PrefixOrigin: IpPrefixOriginUnchanged,
+			SuffixOrigin: IpSuffixOriginUnchanged, ValidLifetime: InfiniteAddressLifetime,
+			PreferredLifetime: InfiniteAddressLifetime, OnLinkPrefixLength: 16}, true},
+		{"lifetimes", wtMibUnicastipaddressRow{PrefixOrigin: IpPrefixOriginUnchanged,
+			SuffixOrigin: IpSuffixOriginUnchanged, ValidLifetime: 600, PreferredLifetime: 300,
+			OnLinkPrefixLength: 24}, true},
+		{"SkipAsSource", wtMibUnicastipaddressRow{PrefixOrigin: IpPrefixOriginUnchanged,
+			SuffixOrigin: IpSuffixOriginUnchanged, ValidLifetime: InfiniteAddressLifetime,
+			PreferredLifetime: InfiniteAddressLifetime, OnLinkPrefixLength: 24, SkipAsSource: 1}, true},
+		{"origin", wtMibUnicastipaddressRow{PrefixOrigin: IpPrefixOriginDhcp, SuffixOrigin: IpSuffixOriginUnchanged,
+			ValidLifetime: InfiniteAddressLifetime, PreferredLifetime: InfiniteAddressLifetime,
+			OnLinkPrefixLength: 24}, true},
+	}
+
+	for _, test := range tests {
+
+		row := existing()
+
+		if updated := row.updateFrom(&test.desired); updated != test.updated {
+			t.Errorf("%s: updateFrom() returned %v, although %v is expected.", test.name, updated, test.updated)
+		}
+
+		if !test.updated {
+			continue
+		}
+
+		if row.ValidLifetime != test.desired.ValidLifetime || row.PreferredLifetime != test.desired.PreferredLifetime ||
+			row.OnLinkPrefixLength != test.desired.OnLinkPrefixLength ||
+			row.SkipAsSource != test.desired.SkipAsSource {
+			t.Errorf("%s: updated row is %+v, although desired fields are expected.", test.name, row)
+		}
+
+		if test.desired.PrefixOrigin == IpPrefixOriginUnchanged && row.PrefixOrigin != IpPrefixOriginManual {
+			t.Errorf("%s: PrefixOrigin is %s, although it's expected to be kept.", test.name,
+				row.PrefixOrigin.String())
+		}
+	}
+}
+
+func TestEnsureDeleted(t *testing.T) {
+
+	tests := []struct {
+		err    error
+		result EnsureResult
+		failed bool
+	}{
+		{nil, EnsureDeleted, false},
+		{newOperationError("iphlpapi.GetUnicastIpAddressEntry", 1, "", windows.ERROR_NOT_FOUND), EnsureUnchanged,
+			false},
+		{newOperationError("iphlpapi.GetIpForwardEntry2", 1, "", windows.ERROR_FILE_NOT_FOUND), EnsureUnchanged,
+			false},
+		{newOperationError("iphlpapi.DeleteIpForwardEntry2", 1, "", windows.ERROR_ACCESS_DENIED), EnsureUnchanged,
+			true},
+	}
+
+	for _, test := range tests {
+
+		result, err := ensureDeleted(test.err)
+
+		if result != test.result || (err != nil) != test.failed {
+			t.Errorf("ensureDeleted(%v) returned %s, %v, although %s is expected.", test.err, result.String(), err,
+				test.result.String())
+		}
+
+		if test.failed && !errors.Is(err, ErrAccessDenied) {
+			t.Errorf("ensureDeleted(%v) returned error %v, although the original error is expected.", test.err, err)
+		}
+	}
+}
+
+func TestInterface_EnsureAddress(t *testing.T) {
+
+	ifc, err := InterfaceFromLUID(existingLuid)
+
+	if err != nil {
+		t.Errorf("InterfaceFromLUID() returned an error (%v), so ensure address testing cannot be performed.", err)
+		return
+	}
+
+	if _, err := ifc.GetUnicastIpAddressRow(&unexistentIpAddresToAdd.IP); err == nil {
+		t.Errorf("Unicast address %s already exists. Please set unexistentIpAddresToAdd appropriately.",
+			unexistentIpAddresToAdd.IP.String())
+		return
+	}
+
+	steps := []struct {
+		name    string
+		ensure  func() (EnsureResult, error)
+		results []EnsureResult
+	}{
+		{"EnsureAddress()", func() (EnsureResult, error) {
+			return ifc.EnsureAddress(&unexistentIpAddresToAdd, nil)
+		}, []EnsureResult{EnsureCreated, EnsureUnchanged}},
+		{"EnsureAddress() with SkipAsSource", func() (EnsureResult, error) {
+			return ifc.EnsureAddress(&unexistentIpAddresToAdd, &AddressOptions{SkipAsSource: true})
+		}, []EnsureResult{EnsureUpdated, EnsureUnchanged}},
+		{"EnsureAddressAbsent()", func() (EnsureResult, error) {
+			return ifc.EnsureAddressAbsent(&unexistentIpAddresToAdd.IP)
+		}, []EnsureResult{EnsureDeleted, EnsureUnchanged}},
+	}
+
+	for _, step := range steps {
+		for _, expected := range step.results {
+
+			result, err := step.ensure()
+
+			if err != nil {
+				t.Errorf("%s returned an error: %v", step.name, err)
+				_, _ = ifc.EnsureAddressAbsent(&unexistentIpAddresToAdd.IP)
+				return
+			}
+
+			if result != expected {
+				t.Errorf("%s returned %s, although %s is expected.", step.name, result.String(), expected.String())
+			}
+		}
+	}
+}
+
+func TestInterface_EnsureRoute(t *testing.T) {
+
+	ifc, err := InterfaceFromLUID(existingLuid)
+
+	if err != nil {
+		t.Errorf("InterfaceFromLUID() returned an error (%v), so ensure route testing cannot be performed.", err)
+		return
+	}
+
+	route := unexistentRouteIPv4ToAdd
+
+	if _, err := ifc.GetRoute(&route.Destination, &route.NextHop); err == nil {
+		t.Errorf("Route %s already exists. Please set unexistentRouteIPv4ToAdd appropriately.",
+			route.Destination.String())
+		return
+	}
+
+	updated := route
+	updated.Metric = route.Metric + 10
+
+	steps := []struct {
+		name    string
+		ensure  func() (EnsureResult, error)
+		results []EnsureResult
+	}{
+		{"EnsureRoute()", func() (EnsureResult, error) {
+			return ifc.EnsureRoute(&route)
+		}, []EnsureResult{EnsureCreated, EnsureUnchanged}},
+		{"EnsureRoute() with another metric", func() (EnsureResult, error) {
+			return ifc.EnsureRoute(&updated)
+		}, []EnsureResult{EnsureUpdated, EnsureUnchanged}},
+		{"EnsureRouteAbsent()", func() (EnsureResult, error) {
+			return ifc.EnsureRouteAbsent(&route.Destination, &route.NextHop)
+		}, []EnsureResult{EnsureDeleted, EnsureUnchanged}},
+	}
+
+	for _, step := range steps {
+		for _, expected := range step.results {
+
+			result, err := step.ensure()
+
+			if err != nil {
+				t.Errorf("%s returned an error: %v", step.name, err)
+				_, _ = ifc.EnsureRouteAbsent(&route.Destination, &route.NextHop)
+				return
+			}
+
+			if result != expected {
+				t.Errorf("%s returned %s, although %s is expected.", step.name, result.String(), expected.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// Kinds of failures, to be checked with errors.Is(). Errors returned by this package match them regardless of whether
+// they come from Windows (see OperationError) or from the package itself.
+var (
+	// The row (address, route...) or object doesn't exist. Also matched by ErrInterfaceGone errors.
+	ErrNotFound = errors.New("not found")
+	// The row being created already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// The caller lacks the privileges, typically because it doesn't run elevated.
+	ErrAccessDenied = errors.New("access denied")
+	// An argument (or a field of a row) is invalid.
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// The interface doesn't exist (anymore), e.g. because the adapter was removed or disabled.
+	ErrInterfaceGone = errors.New("interface gone")
+)
+
+// Windows errors corresponding to the kinds above. ERROR_FILE_NOT_FOUND is what IP Helper returns for rows of a
+// missing interface.
+var windowsErrorKinds = map[windows.Errno][]error{
+	windows.ERROR_NOT_FOUND:             {ErrNotFound},
+	windows.ERROR_PATH_NOT_FOUND:        {ErrNotFound},
+	windows.ERROR_FILE_NOT_FOUND:        {ErrNotFound, ErrInterfaceGone},
+	windows.ERROR_DEVICE_NOT_CONNECTED:  {ErrNotFound, ErrInterfaceGone},
+	windows.ERROR_DEV_NOT_EXIST:         {ErrNotFound, ErrInterfaceGone},
+	windows.ERROR_OBJECT_ALREADY_EXISTS: {ErrAlreadyExists},
+	windows.ERROR_ALREADY_EXISTS:        {ErrAlreadyExists},
+	windows.ERROR_ACCESS_DENIED:         {ErrAccessDenied},
+	windows.ERROR_ELEVATION_REQUIRED:    {ErrAccessDenied},
+	windows.ERROR_INVALID_PARAMETER:     {ErrInvalidParameter},
+}
+
+var windowsErrorNames = map[windows.Errno]string{
+	windows.ERROR_NOT_FOUND:             "ERROR_NOT_FOUND",
+	windows.ERROR_PATH_NOT_FOUND:        "ERROR_PATH_NOT_FOUND",
+	windows.ERROR_FILE_NOT_FOUND:        "ERROR_FILE_NOT_FOUND",
+	windows.ERROR_DEVICE_NOT_CONNECTED:  "ERROR_DEVICE_NOT_CONNECTED",
+	windows.ERROR_DEV_NOT_EXIST:         "ERROR_DEV_NOT_EXIST",
+	windows.ERROR_OBJECT_ALREADY_EXISTS: "ERROR_OBJECT_ALREADY_EXISTS",
+	windows.ERROR_ALREADY_EXISTS:        "ERROR_ALREADY_EXISTS",
+	windows.ERROR_ACCESS_DENIED:         "ERROR_ACCESS_DENIED",
+	windows.ERROR_ELEVATION_REQUIRED:    "ERROR_ELEVATION_REQUIRED",
+	windows.ERROR_INVALID_PARAMETER:     "ERROR_INVALID_PARAMETER",
+	windows.ERROR_NOT_SUPPORTED:         "ERROR_NOT_SUPPORTED",
+	windows.ERROR_NOT_READY:             "ERROR_NOT_READY",
+	windows.ERROR_INVALID_HANDLE:        "ERROR_INVALID_HANDLE",
+	windows.ERROR_NO_DATA:               "ERROR_NO_DATA",
+	windows.ERROR_NETWORK_UNREACHABLE:   "ERROR_NETWORK_UNREACHABLE",
+	windows.ERROR_BUFFER_OVERFLOW:       "ERROR_BUFFER_OVERFLOW",
+	windows.ERROR_NOT_ENOUGH_MEMORY:     "ERROR_NOT_ENOUGH_MEMORY",
+}
+
+// Failure of a Windows (IP Helper) operation. Err is the windows.Errno returned, so errors.Is() works both with it
+// and with the Err... kinds above.
+type OperationError struct {
+	// The failed function, e.g. "iphlpapi.CreateUnicastIpAddressEntry".
+	Op string
+	// The interface concerned; 0 for table operations.
+	InterfaceLuid uint64
+	// The row concerned (e.g. address or route), if any.
+	Row string
+	Err error
+}
+
+func newOperationError(op string, interfaceLuid uint64, row string, errno windows.Errno) error {
+	return &OperationError{Op: op, InterfaceLuid: interfaceLuid, Row: row, Err: errno}
+}
+
+// Returns the name of the Windows error (e.g. "ERROR_NOT_FOUND"), or its number if the name isn't known.
+func (oe *OperationError) ErrorName() string {
+
+	var errno windows.Errno
+
+	if !errors.As(oe.Err, &errno) {
+		return ""
+	}
+
+	if name, ok := windowsErrorNames[errno]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ERROR_%d", uint32(errno))
+}
+
+// Formatted as "Op: Row (interface LUID): Err (ERROR_NAME)", leaving out the parts which aren't set, e.g.
+// "iphlpapi.CreateUnicastIpAddressEntry: 10.0.0.2 (interface 1): Cannot create a file when that file already exists.
+// (ERROR_ALREADY_EXISTS)".
+func (oe *OperationError) Error() string {
+
+	var sb strings.Builder
+
+	sb.WriteString(oe.Op)
+
+	if oe.Row != "" {
+		sb.WriteString(": ")
+		sb.WriteString(oe.Row)
+	}
+
+	if oe.InterfaceLuid != 0 {
+		fmt.Fprintf(&sb, " (interface %d)", oe.InterfaceLuid)
+	}
+
+	sb.WriteString(": ")
+	sb.WriteString(oe.Err.Error())
+
+	if name := oe.ErrorName(); name != "" {
+		fmt.Fprintf(&sb, " (%s)", name)
+	}
+
+	return sb.String()
+}
+
+func (oe *OperationError) Unwrap() error {
+	return oe.Err
+}
+
+func (oe *OperationError) Is(target error) bool {
+
+	var errno windows.Errno
+
+	if !errors.As(oe.Err, &errno) {
+		return false
+	}
+
+	for _, kind := range windowsErrorKinds[errno] {
+		if kind == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Error raised by the package itself, with its own message, matching a kind with errors.Is().
+type kindError struct {
+	kind    error
+	message string
+}
+
+func newKindError(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, message: fmt.Sprintf(format, args...)}
+}
+
+func (ke *kindError) Error() string {
+	return ke.message
+}
+
+func (ke *kindError) Is(target error) bool {
+	return target == ke.kind || (ke.kind == ErrInterfaceGone && target == ErrNotFound)
+}
+
+// Multiple errors, e.g. of operations on several rows. errors.Is() and errors.As() match any of them.
+type multiError []error
+
+func (me multiError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d errors: ", len(me))
+	for i, e := range me {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(e.Error())
+	}
+	return sb.String()
+}
+
+func (me multiError) Is(target error) bool {
+	for _, e := range me {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (me multiError) As(target interface{}) bool {
+	for _, e := range me {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+func TestOperationErrorKinds(t *testing.T) {
+
+	kinds := []error{ErrNotFound, ErrAlreadyExists, ErrAccessDenied, ErrInvalidParameter, ErrInterfaceGone}
+
+	tests := []struct {
+		errno windows.Errno
+		// Expected to match these kinds only.
+		kinds []error
+	}{
+		{windows.ERROR_NOT_FOUND, []error{ErrNotFound}},
+		{windows.ERROR_FILE_NOT_FOUND, []error{ErrNotFound, ErrInterfaceGone}},
+		{windows.ERROR_OBJECT_ALREADY_EXISTS, []error{ErrAlreadyExists}},
+		{windows.ERROR_ACCESS_DENIED, []error{ErrAccessDenied}},
+		{windows.ERROR_INVALID_PARAMETER, []error{ErrInvalidParameter}},
+		{windows.ERROR_NOT_SUPPORTED, nil},
+	}
+
+	for _, test := range tests {
+
+		// Wrapping doesn't matter.
+		err := fmt.Errorf("adding: %w", newOperationError("iphlpapi.CreateUnicastIpAddressEntry", 1, "10.0.0.2",
+			test.errno))
+
+		for _, kind := range kinds {
+
+			expected := false
+
+			for _, k := range test.kinds {
+				if k == kind {
+					expected = true
+				}
+			}
+
+			if errors.Is(err, kind) != expected {
+				t.Errorf("errors.Is(%v, %v) returned %v, although %v is expected.", err, kind, !expected, expected)
+			}
+		}
+
+		if !errors.Is(err, test.errno) {
+			t.Errorf("errors.Is(%v, %d) returned false, although true is expected.", err, test.errno)
+		}
+	}
+}
+
+func TestOperationErrorFields(t *testing.T) {
+
+	err := newOperationError("iphlpapi.DeleteIpForwardEntry2", 42, "10.0.0.0/8 via 10.0.0.1", windows.ERROR_NOT_FOUND)
+
+	var oe *OperationError
+
+	if !errors.As(multiError{errors.New("other"), err}, &oe) {
+		t.Fatal("errors.As() didn't find OperationError in multiError, although it's expected.")
+	}
+
+	if oe.Op != "iphlpapi.DeleteIpForwardEntry2" || oe.InterfaceLuid != 42 || oe.ErrorName() != "ERROR_NOT_FOUND" {
+		t.Errorf("OperationError is %+v with error name %s, although other values are expected.", oe,
+			oe.ErrorName())
+	}
+
+	expected := "iphlpapi.DeleteIpForwardEntry2: 10.0.0.0/8 via 10.0.0.1 (interface 42): " +
+		windows.ERROR_NOT_FOUND.Error() + " (ERROR_NOT_FOUND)"
+
+	if message := err.Error(); message != expected {
+		t.Errorf("Error() returned %q, although %q is expected.", message, expected)
+	}
+
+	// Parts which aren't set are left out.
+	expected = "iphlpapi.GetIpForwardTable2: " + windows.ERROR_NOT_ENOUGH_MEMORY.Error() + " (ERROR_NOT_ENOUGH_MEMORY)"
+
+	if message := newOperationError("iphlpapi.GetIpForwardTable2", 0, "",
+		windows.ERROR_NOT_ENOUGH_MEMORY).Error(); message != expected {
+		t.Errorf("Error() returned %q, although %q is expected.", message, expected)
+	}
+
+	if oe.Row != "10.0.0.0/8 via 10.0.0.1" {
+		t.Errorf("Row is %q, although the route is expected.", oe.Row)
+	}
+
+	if name := (&OperationError{Err: windows.Errno(123456)}).ErrorName(); name != "ERROR_123456" {
+		t.Errorf("ErrorName() of an unknown error returned %s, although ERROR_123456 is expected.", name)
+	}
+}
+
+func TestKindError(t *testing.T) {
+
+	err := newKindError(ErrInterfaceGone, "InterfaceFromIndexEx() - interface with specified LUID not found")
+
+	if err.Error() != "InterfaceFromIndexEx() - interface with specified LUID not found" {
+		t.Errorf("Error() returned %q, although the message is expected unchanged.", err.Error())
+	}
+
+	if !errors.Is(err, ErrInterfaceGone) || !errors.Is(err, ErrNotFound) || errors.Is(err, ErrAlreadyExists) {
+		t.Error("ErrInterfaceGone error doesn't match exactly ErrInterfaceGone and ErrNotFound, although it's expected.")
+	}
+
+	if err := newKindError(ErrNotFound, "gone"); errors.Is(err, ErrInterfaceGone) {
+		t.Error("ErrNotFound error matches ErrInterfaceGone, although it isn't expected.")
+	}
+}
+
+func TestMultiErrorIs(t *testing.T) {
+
+	err := multiError{
+		newKindError(ErrInvalidParameter, "invalid"),
+		fmt.Errorf("route: %w", newOperationError("iphlpapi.CreateIpForwardEntry2", 1, "",
+			windows.ERROR_OBJECT_ALREADY_EXISTS)),
+	}
+
+	if !errors.Is(err, ErrInvalidParameter) || !errors.Is(err, ErrAlreadyExists) {
+		t.Error("multiError doesn't match kinds of its members, although it's expected.")
+	}
+
+	if errors.Is(err, ErrAccessDenied) {
+		t.Error("multiError matches ErrAccessDenied, although none of its members does.")
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"bytes"
 	"fmt"
 	"golang.org/x/sys/windows"
-	"os"
 	"strings"
 	"unsafe"
 )
@@ -85,7 +84,7 @@ func InterfaceLuidToGuid(luid uint64) (*windows.GUID, error) {
 	if result == 0 {
 		return &guid, nil
 	} else {
-		return nil, os.NewSyscallError("iphlpapi.ConvertInterfaceLuidToGuid", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.ConvertInterfaceLuidToGuid", luid, "", windows.Errno(result))
 	}
 }
 
@@ -97,6 +96,6 @@ func InterfaceGuidToLuid(guid *windows.GUID) (uint64, error) {
 	if result == 0 {
 		return luid, nil
 	} else {
-		return 0, os.NewSyscallError("iphlpapi.ConvertInterfaceGuidToLuid", windows.Errno(result))
+		return 0, newOperationError("iphlpapi.ConvertInterfaceGuidToLuid", 0, "", windows.Errno(result))
 	}
 }
@@ -0,0 +1,70 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Corresponds to MIBICMPSTATS_EX_XPSP1 defined in ipmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/ipmib/ns-ipmib-mibicmpstats_ex_xpsp1).
+type IcmpStats struct {
+	Msgs   uint32
+	Errors uint32
+	// Number of messages per ICMP (or ICMPv6) type, indexed by type.
+	TypeCount [256]uint32
+}
+
+// Corresponds to MIB_ICMP_EX_XPSP1 defined in ipmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/ipmib/ns-ipmib-mib_icmp_ex_xpsp1).
+type IcmpStatistics struct {
+	Family AddressFamily
+	In     IcmpStats
+	Out    IcmpStats
+}
+
+// Returns ICMP (for AF_INET) or ICMPv6 (for AF_INET6) statistics. Corresponds to GetIcmpStatisticsEx function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-geticmpstatisticsex).
+func GetIcmpStatistics(family AddressFamily) (*IcmpStatistics, error) {
+
+	stats, err := getWtMibIcmpEx(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.toIcmpStatistics(family), nil
+}
+
+// Only non-zero TypeCount items are included in the output.
+func (stats *IcmpStats) String() string {
+
+	if stats == nil {
+		return "<nil>"
+	}
+
+	var types []string
+
+	for t, count := range stats.TypeCount {
+		if count != 0 {
+			types = append(types, fmt.Sprintf("%d: %d", t, count))
+		}
+	}
+
+	return fmt.Sprintf("Msgs: %d; Errors: %d; TypeCount: {%s}", stats.Msgs, stats.Errors, strings.Join(types, ", "))
+}
+
+func (stats *IcmpStatistics) String() string {
+
+	if stats == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf(`Family: %s
+In: %s
+Out: %s`, stats.Family.String(), stats.In.String(), stats.Out.String())
+}
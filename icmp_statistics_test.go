@@ -0,0 +1,46 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"testing"
+)
+
+const icmpStatistics_print = false
+
+func TestGetIcmpStatistics(t *testing.T) {
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+
+		stats, err := GetIcmpStatistics(family)
+
+		if err != nil {
+			t.Errorf("GetIcmpStatistics(%s) returned an error: %v", family.String(), err)
+			continue
+		}
+
+		if stats == nil || stats.Family != family {
+			t.Errorf("GetIcmpStatistics(%s) returned unexpected result: %v", family.String(), stats)
+			continue
+		}
+
+		if icmpStatistics_print {
+			fmt.Println("======================= ICMP STATISTICS OUTPUT START =======================")
+			fmt.Println(stats)
+			fmt.Println("======================== ICMP STATISTICS OUTPUT END ========================")
+		}
+	}
+}
+
+func TestGetIcmpStatisticsInvalidFamily(t *testing.T) {
+
+	_, err := GetIcmpStatistics(AF_UNSPEC)
+
+	if err == nil {
+		t.Error("GetIcmpStatistics(AF_UNSPEC) didn't return an error.")
+	}
+}
@@ -8,6 +8,7 @@ package winipcfg
 import (
 	"fmt"
 	"golang.org/x/sys/windows"
+	"net"
 )
 
 // Corresponds to MIB_IF_ROW2 struct defined in netioapi.h
@@ -67,6 +68,9 @@ type IfRow struct {
 	OutMulticastOctets uint64
 	OutBroadcastOctets uint64
 	OutQLen            uint64
+
+	// Raw PhysicalAddress, which (unlike the string) keeps zero bytes.
+	physicalAddress net.HardwareAddr
 }
 
 // Returns IfRow struct with specified InterfaceLuid. Corresponds to GetIfEntry2Ex function
@@ -0,0 +1,235 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Describes a single relationship in the network interface stack. Corresponds to MIB_IFSTACK_ROW and
+// MIB_INVERTEDIFSTACK_ROW structs defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_ifstack_row). Index 0 denotes
+// "no interface", i.e. the other interface is at the top or at the bottom of the stack.
+type IfStackRow struct {
+	HigherLayerInterfaceIndex uint32
+	LowerLayerInterfaceIndex  uint32
+}
+
+// Returns the interface stack table, ordered by higher layer interface. Corresponds to GetIfStackTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getifstacktable).
+func GetIfStackTable() ([]*IfStackRow, error) {
+
+	rows, err := getWtMibIfstackRows()
+
+	if err != nil {
+		return nil, err
+	}
+
+	length := len(rows)
+
+	stack := make([]*IfStackRow, length, length)
+
+	for idx, row := range rows {
+		stack[idx] = &IfStackRow{
+			HigherLayerInterfaceIndex: row.HigherLayerInterfaceIndex,
+			LowerLayerInterfaceIndex:  row.LowerLayerInterfaceIndex,
+		}
+	}
+
+	return stack, nil
+}
+
+// Returns the interface stack table, ordered by lower layer interface. Corresponds to GetInvertedIfStackTable
+// function (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getinvertedifstacktable).
+func GetInvertedIfStackTable() ([]*IfStackRow, error) {
+
+	rows, err := getWtMibInvertedifstackRows()
+
+	if err != nil {
+		return nil, err
+	}
+
+	length := len(rows)
+
+	stack := make([]*IfStackRow, length, length)
+
+	for idx, row := range rows {
+		stack[idx] = &IfStackRow{
+			HigherLayerInterfaceIndex: row.HigherLayerInterfaceIndex,
+			LowerLayerInterfaceIndex:  row.LowerLayerInterfaceIndex,
+		}
+	}
+
+	return stack, nil
+}
+
+func (isr *IfStackRow) String() string {
+	if isr == nil {
+		return "<nil>"
+	} else {
+		return fmt.Sprintf("HigherLayerInterfaceIndex: %d\nLowerLayerInterfaceIndex: %d",
+			isr.HigherLayerInterfaceIndex, isr.LowerLayerInterfaceIndex)
+	}
+}
+
+// Returns interfaces directly below the interface in the interface stack (i.e. the interfaces this one is bound to).
+// IfRow structs are returned, rather than Interface structs, because lower layers are often NDIS filter or
+// miniport interfaces which aren't reported by GetAdaptersAddresses.
+func (ifc *Interface) LowerLayers() ([]*IfRow, error) {
+	return ifStackNeighbours(ifc.Index, ifStackLowerLayers)
+}
+
+// Returns interfaces directly above the interface in the interface stack. See LowerLayers() for details.
+func (ifc *Interface) UpperLayers() ([]*IfRow, error) {
+	return ifStackNeighbours(ifc.Index, ifStackUpperLayers)
+}
+
+// Returns the hardware interface which ultimately carries traffic of the interface, by walking the interface stack
+// downwards. If the interface is itself a hardware interface, its own IfRow is returned. If there are several hardware
+// interfaces below (i.e. NIC teaming), the nearest one with the lowest index is returned.
+func (ifc *Interface) PhysicalInterface() (*IfRow, error) {
+	return GetPhysicalIfRow(ifc.Luid)
+}
+
+// The same as Interface.PhysicalInterface(), for the interface with specified LUID.
+func GetPhysicalIfRow(interfaceLuid uint64) (*IfRow, error) {
+
+	ifrows, err := GetIfRows(MibIfEntryNormalWithoutStatistics)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stack, err := GetIfStackTable()
+
+	if err != nil {
+		return nil, err
+	}
+
+	byIndex := make(map[uint32]*IfRow, len(ifrows))
+	var start *IfRow = nil
+
+	for _, ifrow := range ifrows {
+		byIndex[ifrow.InterfaceIndex] = ifrow
+		if ifrow.InterfaceLuid == interfaceLuid {
+			start = ifrow
+		}
+	}
+
+	if start == nil {
+		return nil, newKindError(ErrInterfaceGone, "GetPhysicalIfRow() - interface with specified LUID not found")
+	}
+
+	index, ok := resolvePhysicalInterfaceIndex(stack, start.InterfaceIndex, func(index uint32) bool {
+		ifrow, ok := byIndex[index]
+		return ok && ifrow.InterfaceAndOperStatusFlags.HardwareInterface
+	})
+
+	if ok {
+		return byIndex[index], nil
+	} else {
+		return nil, newKindError(ErrNotFound,
+			"GetPhysicalIfRow() - no hardware interface found below interface %d", start.InterfaceIndex)
+	}
+}
+
+func ifStackNeighbours(index uint32, neighbours func([]*IfStackRow, uint32) []uint32) ([]*IfRow, error) {
+
+	stack, err := GetIfStackTable()
+
+	if err != nil {
+		return nil, err
+	}
+
+	indices := neighbours(stack, index)
+
+	if len(indices) == 0 {
+		return []*IfRow{}, nil
+	}
+
+	ifrows, err := GetIfRows(MibIfEntryNormalWithoutStatistics)
+
+	if err != nil {
+		return nil, err
+	}
+
+	byIndex := make(map[uint32]*IfRow, len(ifrows))
+
+	for _, ifrow := range ifrows {
+		byIndex[ifrow.InterfaceIndex] = ifrow
+	}
+
+	result := make([]*IfRow, 0, len(indices))
+
+	for _, idx := range indices {
+		// An interface may disappear between the two calls.
+		if ifrow, ok := byIndex[idx]; ok {
+			result = append(result, ifrow)
+		}
+	}
+
+	return result, nil
+}
+
+// Returns sorted indices of interfaces directly below the interface at 'index'.
+func ifStackLowerLayers(stack []*IfStackRow, index uint32) []uint32 {
+
+	var result []uint32
+
+	for _, row := range stack {
+		if row.HigherLayerInterfaceIndex == index && row.LowerLayerInterfaceIndex != 0 {
+			result = append(result, row.LowerLayerInterfaceIndex)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+// Returns sorted indices of interfaces directly above the interface at 'index'.
+func ifStackUpperLayers(stack []*IfStackRow, index uint32) []uint32 {
+
+	var result []uint32
+
+	for _, row := range stack {
+		if row.LowerLayerInterfaceIndex == index && row.HigherLayerInterfaceIndex != 0 {
+			result = append(result, row.HigherLayerInterfaceIndex)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+// Walks the stack downwards from 'start' breadth-first, and returns the first index for which 'isPhysical' returns
+// true. Cycles in the stack (which shouldn't exist, but the table is a snapshot) are tolerated.
+func resolvePhysicalInterfaceIndex(stack []*IfStackRow, start uint32, isPhysical func(uint32) bool) (uint32, bool) {
+
+	visited := map[uint32]bool{start: true}
+	queue := []uint32{start}
+
+	for len(queue) > 0 {
+
+		index := queue[0]
+		queue = queue[1:]
+
+		if isPhysical(index) {
+			return index, true
+		}
+
+		for _, lower := range ifStackLowerLayers(stack, index) {
+			if !visited[lower] {
+				visited[lower] = true
+				queue = append(queue, lower)
+			}
+		}
+	}
+
+	return 0, false
+}
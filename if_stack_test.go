@@ -0,0 +1,124 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const ifStack_print = false
+
+// A typical stack: VPN adapter (20) on top of Hyper-V vSwitch (15), which is on top of a NIC team (10) of two
+// hardware NICs (3 and 4). 30 is a standalone hardware NIC. 40 and 41 form a (bogus) cycle.
+var fabricatedIfStack = []*IfStackRow{
+	{0, 20},
+	{20, 15},
+	{15, 10},
+	{10, 4},
+	{10, 3},
+	{3, 0},
+	{4, 0},
+	{0, 30},
+	{30, 0},
+	{40, 41},
+	{41, 40},
+}
+
+func TestIfStackLayers(t *testing.T) {
+
+	if lower := ifStackLowerLayers(fabricatedIfStack, 10); !reflect.DeepEqual(lower, []uint32{3, 4}) {
+		t.Errorf("ifStackLowerLayers(10) returned %v, although [3 4] is expected.", lower)
+	}
+
+	if upper := ifStackUpperLayers(fabricatedIfStack, 3); !reflect.DeepEqual(upper, []uint32{10}) {
+		t.Errorf("ifStackUpperLayers(3) returned %v, although [10] is expected.", upper)
+	}
+
+	if lower := ifStackLowerLayers(fabricatedIfStack, 30); len(lower) != 0 {
+		t.Errorf("ifStackLowerLayers(30) returned %v, although no layers are expected.", lower)
+	}
+
+	if upper := ifStackUpperLayers(fabricatedIfStack, 20); len(upper) != 0 {
+		t.Errorf("ifStackUpperLayers(20) returned %v, although no layers are expected.", upper)
+	}
+}
+
+func TestResolvePhysicalInterfaceIndex(t *testing.T) {
+
+	physical := map[uint32]bool{3: true, 4: true, 30: true}
+	isPhysical := func(index uint32) bool { return physical[index] }
+
+	tests := []struct {
+		start    uint32
+		expected uint32
+		ok       bool
+	}{
+		{20, 3, true},
+		{10, 3, true},
+		{4, 4, true},
+		{30, 30, true},
+		{40, 0, false},
+		{99, 0, false},
+	}
+
+	for _, test := range tests {
+		index, ok := resolvePhysicalInterfaceIndex(fabricatedIfStack, test.start, isPhysical)
+		if index != test.expected || ok != test.ok {
+			t.Errorf("resolvePhysicalInterfaceIndex(%d) returned %d, %v, although %d, %v is expected.", test.start,
+				index, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestGetIfStackTable(t *testing.T) {
+
+	stack, err := GetIfStackTable()
+
+	if err != nil {
+		t.Errorf("GetIfStackTable() returned an error: %v", err)
+		return
+	}
+
+	inverted, err := GetInvertedIfStackTable()
+
+	if err != nil {
+		t.Errorf("GetInvertedIfStackTable() returned an error: %v", err)
+		return
+	}
+
+	if len(stack) != len(inverted) {
+		t.Errorf("GetIfStackTable() returned %d rows, and GetInvertedIfStackTable() %d rows.", len(stack),
+			len(inverted))
+	}
+
+	if ifStack_print {
+		for _, row := range stack {
+			fmt.Println("======================== IF STACK ROW OUTPUT START ========================")
+			fmt.Println(row)
+			fmt.Println("========================= IF STACK ROW OUTPUT END =========================")
+		}
+	}
+}
+
+func TestInterfaceLayers(t *testing.T) {
+
+	ifc, err := InterfaceFromLUID(existingLuid)
+
+	if err != nil {
+		t.Errorf("InterfaceFromLUID() returned an error: %v", err)
+		return
+	}
+
+	if _, err := ifc.LowerLayers(); err != nil {
+		t.Errorf("Interface.LowerLayers() returned an error: %v", err)
+	}
+
+	if _, err := ifc.UpperLayers(); err != nil {
+		t.Errorf("Interface.UpperLayers() returned an error: %v", err)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"net"
 	"sort"
-	"strings"
 
 	"golang.org/x/sys/windows"
 )
@@ -62,8 +61,13 @@ func GetInterfaces() ([]*Interface, error) {
 // Returns all available interfaces. Corresponds to GetAdaptersAddresses function
 // (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getadaptersaddresses)
 func GetInterfacesEx(flags *GetAdapterAddressesFlags) ([]*Interface, error) {
+	return getInterfaces(AF_UNSPEC, flags)
+}
 
-	wtiaas, err := getWtIpAdapterAddresses(flags.toGetAdapterAddressesFlagsBytes())
+// Returns interfaces with 'family' enabled (all if it's AF_UNSPEC); they contain only addresses of that family.
+func getInterfaces(family AddressFamily, flags *GetAdapterAddressesFlags) ([]*Interface, error) {
+
+	wtiaas, err := getWtIpAdapterAddressesEx(family, flags.toGetAdapterAddressesFlagsBytes())
 
 	if err != nil {
 		return nil, err
@@ -107,7 +111,7 @@ func InterfaceFromLUIDEx(luid uint64, flags *GetAdapterAddressesFlags) (*Interfa
 		}
 	}
 
-	return nil, fmt.Errorf("InterfaceFromIndexEx() - interface with specified LUID not found")
+	return nil, newKindError(ErrInterfaceGone, "InterfaceFromIndexEx() - interface with specified LUID not found")
 }
 
 // The same as InterfaceFromIndexEx() with 'flags' input argument gotten from DefaultGetAdapterAddressesFlags().
@@ -144,7 +148,7 @@ func InterfaceFromIndexEx(index uint32, flags *GetAdapterAddressesFlags) (*Inter
 		}
 	}
 
-	return nil, fmt.Errorf("InterfaceFromIndexEx() - interface with specified index not found")
+	return nil, newKindError(ErrInterfaceGone, "InterfaceFromIndexEx() - interface with specified index not found")
 }
 
 // The same as InterfaceFromFriendlyNameEx() with 'flags' input argument gotten from DefaultGetAdapterAddressesFlags().
@@ -176,7 +180,8 @@ func InterfaceFromFriendlyNameEx(friendlyName string, flags *GetAdapterAddresses
 		}
 	}
 
-	return nil, fmt.Errorf("InterfaceFromFriendlyNameEx() - interface with specified friendly name not found")
+	return nil, newKindError(ErrInterfaceGone,
+		"InterfaceFromFriendlyNameEx() - interface with specified friendly name not found")
 }
 
 // The same as InterfaceFromGUIDEx() with 'flags' input argument gotten from DefaultGetAdapterAddressesFlags().
@@ -502,20 +507,6 @@ func (ifc *Interface) AddRoutes(routesData []*RouteData) error {
 	return multiError(errs)
 }
 
-type multiError []error
-
-func (me multiError) Error() string {
-	var sb strings.Builder
-	fmt.Fprintf(&sb, "%d errors: ", len(me))
-	for i, e := range me {
-		if i > 0 {
-			sb.WriteString(", ")
-		}
-		sb.WriteString(e.Error())
-	}
-	return sb.String()
-}
-
 // Sets (flush than add) multiple routes to the interface.
 func (ifc *Interface) SetRoutes(routesData []*RouteData) error {
 
@@ -0,0 +1,299 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// Statistics of an InterfaceCache, as returned by InterfaceCache.Stats().
+type InterfaceCacheStats struct {
+	// Lookups which found an interface.
+	Hits uint64
+	// Lookups which didn't find an interface.
+	Misses uint64
+	// Number of times the interfaces were (re)loaded, and number of failed attempts.
+	Loads      uint64
+	LoadErrors uint64
+	// Number of change notifications (and Invalidate() calls) which marked the cache stale.
+	Invalidations uint64
+	// When the interfaces were last loaded; zero if they never were.
+	LastLoad time.Time
+	// Whether the cache is stale, i.e. the next lookup will reload the interfaces, and since when (zero if it isn't).
+	Stale      bool
+	StaleSince time.Time
+}
+
+// Age of the cached data, i.e. time elapsed since the last load; zero if nothing is loaded.
+func (stats InterfaceCacheStats) Age(now time.Time) time.Duration {
+
+	if stats.LastLoad.IsZero() {
+		return 0
+	}
+
+	return now.Sub(stats.LastLoad)
+}
+
+// Cache of interfaces with O(1) lookups by LUID, index, GUID and friendly name (alias). Interface and unicast address
+// change notifications only mark the cache stale; interfaces are reloaded (with a single GetAdaptersAddresses call)
+// by the first lookup after that. Since notifications are delivered asynchronously, a lookup which doesn't find the
+// interface also reloads once, unless the interfaces were loaded less than interfaceCacheMissReloadAge ago. Returned
+// interfaces are copies, but their slices are shared with the cache, so they must not be modified.
+type InterfaceCache struct {
+	flags *GetAdapterAddressesFlags
+	// Replaced in tests.
+	getInterfaces func(flags *GetAdapterAddressesFlags) ([]*Interface, error)
+	now           func() time.Time
+
+	mutex          sync.Mutex
+	interfaces     []*Interface
+	byLuid         map[uint64]*Interface
+	byIndex        map[uint32]*Interface
+	byGuid         map[windows.GUID]*Interface
+	byFriendlyName map[string]*Interface
+	stats          InterfaceCacheStats
+
+	interfaceCallback      *InterfaceChangeCallback
+	unicastAddressCallback *UnicastAddressChangeCallback
+}
+
+// Lookups which don't find an interface reload interfaces loaded at least this long ago.
+const interfaceCacheMissReloadAge = time.Second
+
+// The same as NewInterfaceCacheEx() with 'flags' input argument gotten from DefaultGetAdapterAddressesFlags().
+func NewInterfaceCache(source NotificationSource) (*InterfaceCache, error) {
+	return NewInterfaceCacheEx(source, DefaultGetAdapterAddressesFlags())
+}
+
+// Creates an InterfaceCache kept current by notifications from 'source' (typically SystemNotificationSource).
+// Interfaces are loaded with 'flags' (DefaultGetAdapterAddressesFlags() if nil), except that friendly names are never
+// skipped. Nothing is loaded until the first lookup or Refresh().
+func NewInterfaceCacheEx(source NotificationSource, flags *GetAdapterAddressesFlags) (*InterfaceCache, error) {
+	return newInterfaceCache(source, flags, GetInterfacesEx, time.Now)
+}
+
+func newInterfaceCache(source NotificationSource, flags *GetAdapterAddressesFlags,
+	getInterfaces func(flags *GetAdapterAddressesFlags) ([]*Interface, error),
+	now func() time.Time) (*InterfaceCache, error) {
+
+	if flags == nil {
+		flags = DefaultGetAdapterAddressesFlags()
+	}
+
+	cacheFlags := *flags
+	cacheFlags.GAA_FLAG_SKIP_FRIENDLY_NAME = false
+
+	cache := &InterfaceCache{flags: &cacheFlags, getInterfaces: getInterfaces, now: now}
+	cache.stats.Stale = true
+	cache.stats.StaleSince = now()
+
+	var err error
+
+	cache.interfaceCallback, err = source.RegisterInterfaceChangeCallbackEx(nil,
+		func(MibNotificationType, uint64) { cache.Invalidate() })
+
+	if err != nil {
+		return nil, err
+	}
+
+	cache.unicastAddressCallback, err = source.RegisterUnicastAddressChangeCallbackEx(nil,
+		func(MibNotificationType, uint64, *net.IP) { cache.Invalidate() })
+
+	if err != nil {
+		_ = cache.interfaceCallback.Unregister()
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// Marks the cache stale, so the next lookup reloads interfaces.
+func (cache *InterfaceCache) Invalidate() {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.stats.Invalidations++
+
+	if !cache.stats.Stale {
+		cache.stats.Stale = true
+		cache.stats.StaleSince = cache.now()
+	}
+}
+
+// Reloads interfaces, regardless of whether the cache is stale.
+func (cache *InterfaceCache) Refresh() error {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.load()
+}
+
+// Has to be called with mutex held.
+func (cache *InterfaceCache) load() error {
+
+	// Notifications arriving meanwhile wait for the mutex, so they mark the newly loaded data stale.
+	ifcs, err := cache.getInterfaces(cache.flags)
+
+	if err != nil {
+		cache.stats.LoadErrors++
+		return err
+	}
+
+	cache.interfaces = ifcs
+	cache.byLuid = make(map[uint64]*Interface, len(ifcs))
+	cache.byIndex = make(map[uint32]*Interface, len(ifcs))
+	cache.byGuid = make(map[windows.GUID]*Interface, len(ifcs))
+	cache.byFriendlyName = make(map[string]*Interface, len(ifcs))
+
+	for _, ifc := range ifcs {
+
+		cache.byLuid[ifc.Luid] = ifc
+
+		if ifc.Index != 0 {
+			cache.byIndex[ifc.Index] = ifc
+		}
+
+		if ifc.Ipv6IfIndex != 0 {
+			cache.byIndex[ifc.Ipv6IfIndex] = ifc
+		}
+
+		// AdapterName holds the interface GUID.
+		if guid, err := windows.GUIDFromString(ifc.AdapterName); err == nil {
+			cache.byGuid[guid] = ifc
+		}
+
+		if ifc.FriendlyName != "" {
+			cache.byFriendlyName[ifc.FriendlyName] = ifc
+		}
+	}
+
+	cache.stats.Loads++
+	cache.stats.LastLoad = cache.now()
+	cache.stats.Stale = false
+	cache.stats.StaleSince = time.Time{}
+
+	return nil
+}
+
+// Returns a copy of the interface found by 'index', reloading interfaces first if the cache is stale, or after a miss
+// if they weren't loaded very recently.
+func (cache *InterfaceCache) lookup(what string, index func() *Interface) (*Interface, error) {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.stats.Stale {
+		if err := cache.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	ifc := index()
+
+	// The interface may have appeared before its notification was delivered.
+	if ifc == nil && cache.now().Sub(cache.stats.LastLoad) >= interfaceCacheMissReloadAge {
+		if err := cache.load(); err != nil {
+			return nil, err
+		}
+		ifc = index()
+	}
+
+	if ifc == nil {
+		cache.stats.Misses++
+		return nil, newKindError(ErrInterfaceGone, "InterfaceCache - interface with specified %s not found", what)
+	}
+
+	cache.stats.Hits++
+
+	result := *ifc
+
+	return &result, nil
+}
+
+// Returns interface with specified LUID.
+func (cache *InterfaceCache) InterfaceFromLUID(luid uint64) (*Interface, error) {
+	return cache.lookup("LUID", func() *Interface { return cache.byLuid[luid] })
+}
+
+// Returns interface with specified index (IPv4 or IPv6 one).
+func (cache *InterfaceCache) InterfaceFromIndex(index uint32) (*Interface, error) {
+	return cache.lookup("index", func() *Interface { return cache.byIndex[index] })
+}
+
+// Returns interface with specified GUID.
+func (cache *InterfaceCache) InterfaceFromGUID(guid *windows.GUID) (*Interface, error) {
+	return cache.lookup("GUID", func() *Interface {
+		if guid == nil {
+			return nil
+		}
+		return cache.byGuid[*guid]
+	})
+}
+
+// Returns interface with specified friendly name (alias).
+func (cache *InterfaceCache) InterfaceFromFriendlyName(friendlyName string) (*Interface, error) {
+	return cache.lookup("friendly name", func() *Interface { return cache.byFriendlyName[friendlyName] })
+}
+
+// Returns all cached interfaces, reloading them first if the cache is stale.
+func (cache *InterfaceCache) Interfaces() ([]*Interface, error) {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.stats.Stale {
+		if err := cache.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	ifcs := make([]*Interface, len(cache.interfaces))
+
+	for i, ifc := range cache.interfaces {
+		copied := *ifc
+		ifcs[i] = &copied
+	}
+
+	return ifcs, nil
+}
+
+func (cache *InterfaceCache) Stats() InterfaceCacheStats {
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.stats
+}
+
+// Stops following change notifications. The cache can still be used, but it's reloaded only by Refresh() and after
+// Invalidate().
+func (cache *InterfaceCache) Close() error {
+
+	var errs []error
+
+	if err := cache.interfaceCallback.Unregister(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := cache.unicastAddressCallback.Unregister(); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return multiError(errs)
+	}
+}
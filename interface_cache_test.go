@@ -0,0 +1,228 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+const interfaceCacheGuid = "{4D36E972-E325-11CE-BFC1-08002BE10318}"
+
+type fakeInterfaceLoader struct {
+	interfaces []*Interface
+	fail       bool
+	loads      int
+}
+
+func (loader *fakeInterfaceLoader) load(flags *GetAdapterAddressesFlags) ([]*Interface, error) {
+
+	loader.loads++
+
+	if flags.GAA_FLAG_SKIP_FRIENDLY_NAME {
+		return nil, errors.New("friendly names are skipped")
+	}
+
+	if loader.fail {
+		return nil, errors.New("loading failed")
+	}
+
+	return loader.interfaces, nil
+}
+
+func newTestInterfaceCache(t *testing.T, loader *fakeInterfaceLoader,
+	records []*NotificationRecord) (*InterfaceCache, *NotificationReplayer) {
+
+	replayer := NewNotificationReplayer(records)
+
+	cache, err := newInterfaceCache(replayer, MinGetAdapterAddressesFlags(), loader.load,
+		func() time.Time { return replayStart })
+
+	if err != nil {
+		t.Fatalf("newInterfaceCache() returned an error: %v", err)
+	}
+
+	return cache, replayer
+}
+
+func TestInterfaceCacheLookups(t *testing.T) {
+
+	loader := &fakeInterfaceLoader{interfaces: []*Interface{
+		{Luid: 1, Index: 11, Ipv6IfIndex: 12, AdapterName: interfaceCacheGuid, FriendlyName: "Ethernet"},
+		{Luid: 2, Index: 21, FriendlyName: "Wi-Fi"},
+	}}
+
+	cache, _ := newTestInterfaceCache(t, loader, nil)
+	defer cache.Close()
+
+	if loader.loads != 0 {
+		t.Errorf("Interfaces were loaded %d times before the first lookup, although none are expected.", loader.loads)
+	}
+
+	guid, _ := windows.GUIDFromString(interfaceCacheGuid)
+
+	lookups := []struct {
+		name   string
+		lookup func() (*Interface, error)
+		luid   uint64
+	}{
+		{"LUID", func() (*Interface, error) { return cache.InterfaceFromLUID(2) }, 2},
+		{"index", func() (*Interface, error) { return cache.InterfaceFromIndex(11) }, 1},
+		{"IPv6 index", func() (*Interface, error) { return cache.InterfaceFromIndex(12) }, 1},
+		{"GUID", func() (*Interface, error) { return cache.InterfaceFromGUID(&guid) }, 1},
+		{"friendly name", func() (*Interface, error) { return cache.InterfaceFromFriendlyName("Wi-Fi") }, 2},
+	}
+
+	for _, l := range lookups {
+
+		ifc, err := l.lookup()
+
+		if err != nil {
+			t.Errorf("Lookup by %s returned an error: %v", l.name, err)
+			continue
+		}
+
+		if ifc.Luid != l.luid {
+			t.Errorf("Lookup by %s returned interface %d, although %d is expected.", l.name, ifc.Luid, l.luid)
+		}
+	}
+
+	if _, err := cache.InterfaceFromLUID(3); err == nil {
+		t.Error("InterfaceFromLUID() of a missing interface didn't return an error, although it's expected.")
+	}
+
+	if _, err := cache.InterfaceFromGUID(nil); err == nil {
+		t.Error("InterfaceFromGUID(nil) didn't return an error, although it's expected.")
+	}
+
+	stats := cache.Stats()
+
+	if loader.loads != 1 || stats.Loads != 1 || stats.Hits != 5 || stats.Misses != 2 || stats.Stale {
+		t.Errorf("After lookups, interfaces were loaded %d times and stats are %+v, although a single load, 5 hits "+
+			"and 2 misses are expected.", loader.loads, stats)
+	}
+
+	// Returned interfaces are copies.
+	ifc, _ := cache.InterfaceFromLUID(1)
+	ifc.FriendlyName = "Changed"
+
+	if ifc, _ := cache.InterfaceFromLUID(1); ifc.FriendlyName != "Ethernet" {
+		t.Errorf("Cached interface's friendly name is %s, although Ethernet is expected.", ifc.FriendlyName)
+	}
+}
+
+func TestInterfaceCacheInvalidation(t *testing.T) {
+
+	loader := &fakeInterfaceLoader{interfaces: []*Interface{{Luid: 1, Index: 11}}}
+
+	cache, replayer := newTestInterfaceCache(t, loader, []*NotificationRecord{
+		{Kind: NotificationKindInterface, NotificationType: MibAddInstance, Family: AF_INET, InterfaceLuid: 2},
+		{Kind: NotificationKindUnicastAddress, NotificationType: MibAddInstance, Family: AF_INET, InterfaceLuid: 2},
+		{Kind: NotificationKindRoute, NotificationType: MibAddInstance, Route: fakeRoute(2, "0.0.0.0/0", 0)},
+	})
+	defer cache.Close()
+
+	if err := cache.Refresh(); err != nil {
+		t.Fatalf("Refresh() returned an error: %v", err)
+	}
+
+	loader.interfaces = append(loader.interfaces, &Interface{Luid: 2, Index: 21})
+
+	if err := replayer.Replay(0); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	stats := cache.Stats()
+
+	// Route changes don't affect interfaces.
+	if stats.Invalidations != 2 || !stats.Stale || !stats.StaleSince.Equal(replayStart) || loader.loads != 1 {
+		t.Errorf("After notifications, interfaces were loaded %d times and stats are %+v, although a single load "+
+			"and 2 invalidations are expected.", loader.loads, stats)
+	}
+
+	// Invalidation is lazy: the next lookup reloads.
+	if ifc, err := cache.InterfaceFromIndex(21); err != nil || ifc.Luid != 2 {
+		t.Errorf("InterfaceFromIndex() returned %v, %v, although the new interface is expected.", ifc, err)
+	}
+
+	if stats := cache.Stats(); stats.Loads != 2 || stats.Stale {
+		t.Errorf("After the lookup, stats are %+v, although 2 loads and fresh cache are expected.", stats)
+	}
+
+	if age := cache.Stats().Age(replayStart.Add(time.Minute)); age != time.Minute {
+		t.Errorf("Age() returned %v, although 1m is expected.", age)
+	}
+}
+
+func TestInterfaceCacheMissReload(t *testing.T) {
+
+	loader := &fakeInterfaceLoader{interfaces: []*Interface{{Luid: 1}}}
+	now := replayStart
+
+	cache, err := newInterfaceCache(NewNotificationReplayer(nil), nil, loader.load, func() time.Time { return now })
+
+	if err != nil {
+		t.Fatalf("newInterfaceCache() returned an error: %v", err)
+	}
+
+	defer cache.Close()
+
+	if cache.flags == nil || *cache.flags != *DefaultGetAdapterAddressesFlags() {
+		t.Error("Cache flags aren't the default ones, although nil flags are expected to mean the default.")
+	}
+
+	// A miss right after loading doesn't reload.
+	if _, err := cache.InterfaceFromLUID(2); err == nil {
+		t.Error("InterfaceFromLUID() of a missing interface didn't return an error, although it's expected.")
+	}
+
+	if loader.loads != 1 {
+		t.Errorf("Interfaces were loaded %d times, although 1 is expected.", loader.loads)
+	}
+
+	// The interface appeared, but its notification hasn't arrived yet.
+	loader.interfaces = append(loader.interfaces, &Interface{Luid: 2})
+	now = now.Add(interfaceCacheMissReloadAge)
+
+	if ifc, err := cache.InterfaceFromLUID(2); err != nil || ifc.Luid != 2 {
+		t.Errorf("InterfaceFromLUID() returned %v, %v, although the new interface is expected.", ifc, err)
+	}
+
+	if _, err := cache.InterfaceFromLUID(3); err == nil {
+		t.Error("InterfaceFromLUID() of a missing interface didn't return an error, although it's expected.")
+	}
+
+	if stats := cache.Stats(); loader.loads != 2 || stats.Misses != 2 || stats.Hits != 1 {
+		t.Errorf("Interfaces were loaded %d times and stats are %+v, although 2 loads, 1 hit and 2 misses are "+
+			"expected.", loader.loads, stats)
+	}
+}
+
+func TestInterfaceCacheLoadError(t *testing.T) {
+
+	loader := &fakeInterfaceLoader{fail: true}
+
+	cache, _ := newTestInterfaceCache(t, loader, nil)
+	defer cache.Close()
+
+	if _, err := cache.InterfaceFromLUID(1); err == nil {
+		t.Error("InterfaceFromLUID() didn't return an error when loading failed, although it's expected.")
+	}
+
+	if stats := cache.Stats(); stats.LoadErrors != 1 || !stats.Stale || stats.Misses != 0 {
+		t.Errorf("Stats are %+v, although a load error and stale cache are expected.", stats)
+	}
+
+	loader.fail = false
+	loader.interfaces = []*Interface{{Luid: 1}}
+
+	if _, err := cache.InterfaceFromLUID(1); err != nil {
+		t.Errorf("InterfaceFromLUID() returned an error: %v", err)
+	}
+}
@@ -7,65 +7,116 @@ package winipcfg
 
 import (
 	"golang.org/x/sys/windows"
-	"os"
-	"sync"
 	"unsafe"
 )
 
 type InterfaceChangeCallback struct {
-	cb func(notificationType MibNotificationType, interfaceLuid uint64)
+	dispatcher *notificationDispatcher
+	subscriber *notificationSubscriber
+}
+
+type interfaceChange struct {
+	notificationType MibNotificationType
+	family           AddressFamily
+	interfaceLuid    uint64
+	ipInterface      *IpInterface
 }
 
 var (
-	interfaceChangeMutex     = sync.Mutex{}
-	interfaceChangeCallbacks = make(map[*InterfaceChangeCallback]bool)
-	interfaceChangeHandle    = uintptr(0)
+	interfaceChangeHandles    notificationHandles
+	interfaceChangeDispatcher *notificationDispatcher
 )
 
-// Registering new InterfaceChangeCallback. If this particular callback is already registered, the function will
-// silently return. Returned InterfaceChangeCallback structure should be used with UnregisterInterfaceChangeCallback
-// function to unregister.
+func init() {
+	interfaceChangeDispatcher = newNotificationDispatcher(registerInterfaceChange,
+		func(family AddressFamily) error { return cancelChangeNotification(interfaceChangeHandles.of(family)) })
+}
+
+// Registering new InterfaceChangeCallback. Returned InterfaceChangeCallback structure should be used for unregistering.
+// The callback is called from its own goroutine (never from the OS notification thread), notifications are delivered
+// in order, and the callback may register and unregister callbacks, including itself.
 func RegisterInterfaceChangeCallback(callback func(notificationType MibNotificationType,
 	interfaceLuid uint64)) (*InterfaceChangeCallback, error) {
+	return RegisterInterfaceChangeCallbackEx(nil, callback)
+}
+
+// The same as RegisterInterfaceChangeCallback(), but the callback receives only changes matching 'filter' (nil matches
+// everything). Filter's DestinationPrefix isn't supported.
+func RegisterInterfaceChangeCallbackEx(filter *ChangeFilter, callback func(notificationType MibNotificationType,
+	interfaceLuid uint64)) (*InterfaceChangeCallback, error) {
+	return subscribeInterfaceChange(interfaceChangeDispatcher, filter, callback)
+}
+
+// Registers a callback which receives the complete changed IpInterface (including Family, Connected and NlMtu), as
+// provided by the OS, so there's no need to query it again. 'filter' is used as in RegisterInterfaceChangeCallbackEx().
+// Each callback receives its own copy of the IpInterface.
+func RegisterIpInterfaceChangeCallback(filter *ChangeFilter, callback func(notificationType MibNotificationType,
+	ipInterface *IpInterface)) (*InterfaceChangeCallback, error) {
+	return subscribeIpInterfaceChange(interfaceChangeDispatcher, filter, callback)
+}
 
-	cb := &InterfaceChangeCallback{callback}
+func subscribeInterfaceChange(dispatcher *notificationDispatcher, filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, interfaceLuid uint64)) (*InterfaceChangeCallback, error) {
+	return subscribeInterfaceChangeEvents(dispatcher, filter, func(change *interfaceChange) {
+		callback(change.notificationType, change.interfaceLuid)
+	})
+}
 
-	interfaceChangeMutex.Lock()
-	defer interfaceChangeMutex.Unlock()
+func subscribeIpInterfaceChange(dispatcher *notificationDispatcher, filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, ipInterface *IpInterface)) (*InterfaceChangeCallback, error) {
+	return subscribeInterfaceChangeEvents(dispatcher, filter, func(change *interfaceChange) {
+		ipInterface := *change.ipInterface
+		callback(change.notificationType, &ipInterface)
+	})
+}
 
-	interfaceChangeCallbacks[cb] = true
+func subscribeInterfaceChangeEvents(dispatcher *notificationDispatcher, filter *ChangeFilter,
+	deliver func(change *interfaceChange)) (*InterfaceChangeCallback, error) {
 
-	if interfaceChangeHandle == 0 {
+	compiled, err := filter.compile(false)
 
-		result := notifyIpInterfaceChange(AF_UNSPEC, windows.NewCallback(interfaceChanged), 0,
-			false, unsafe.Pointer(&interfaceChangeHandle))
+	if err != nil {
+		return nil, err
+	}
 
-		if result != 0 {
-			delete(interfaceChangeCallbacks, cb)
-			interfaceChangeHandle = 0
-			return nil, os.NewSyscallError("iphlpapi.NotifyIpInterfaceChange", windows.Errno(result))
-		}
+	subscriber, err := dispatcher.Subscribe(compiled.families(), func(event interface{}) bool {
+		change := event.(*interfaceChange)
+		return compiled.matches(change.family, change.interfaceLuid, change.notificationType)
+	}, func(event interface{}) {
+		deliver(event.(*interfaceChange))
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return cb, nil
+	return &InterfaceChangeCallback{dispatcher: dispatcher, subscriber: subscriber}, nil
 }
 
+// Unregisters the callback, waiting for its running call (if any) to return, so the callback won't be called after
+// Unregister returns. Called from the callback itself, Unregister doesn't wait for it.
 func (callback *InterfaceChangeCallback) Unregister() error {
+	return callback.dispatcher.Unsubscribe(callback.subscriber)
+}
 
-	interfaceChangeMutex.Lock()
-	defer interfaceChangeMutex.Unlock()
+// Returns the number of notifications dropped because the callback didn't keep up with them.
+func (callback *InterfaceChangeCallback) Dropped() uint64 {
+	return callback.subscriber.Dropped()
+}
 
-	delete(interfaceChangeCallbacks, callback)
+func registerInterfaceChange(family AddressFamily) error {
 
-	if len(interfaceChangeCallbacks) < 1 && interfaceChangeHandle != 0 {
+	handle := interfaceChangeHandles.of(family)
 
-		result := cancelMibChangeNotify2(interfaceChangeHandle)
+	if *handle != 0 {
+		return nil
+	}
 
-		if result != 0 {
-			return os.NewSyscallError("iphlpapi.CancelMibChangeNotify2", windows.Errno(result))
-		}
+	result := notifyIpInterfaceChange(family, windows.NewCallback(interfaceChanged), 0, false, unsafe.Pointer(handle))
 
-		interfaceChangeHandle = uintptr(0)
+	if result != 0 {
+		*handle = 0
+		return newOperationError("iphlpapi.NotifyIpInterfaceChange", 0, "", windows.Errno(result))
 	}
 
 	return nil
@@ -78,13 +129,8 @@ func interfaceChanged(callerContext unsafe.Pointer, wtIfc *wtMibIpinterfaceRow,
 		return 0
 	}
 
-	interfaceChangeMutex.Lock()
-
-	for cb := range interfaceChangeCallbacks {
-		cb.cb(notificationType, wtIfc.InterfaceLuid)
-	}
-
-	interfaceChangeMutex.Unlock()
+	interfaceChangeDispatcher.Dispatch(&interfaceChange{notificationType, wtIfc.Family, wtIfc.InterfaceLuid,
+		wtIfc.toIpInterface()})
 
 	return 0
 }
@@ -0,0 +1,225 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// Kind of an interface, as determined by ClassifyInterface().
+type InterfaceClass uint32
+
+const (
+	InterfaceClassUnknown InterfaceClass = iota
+	// Physical network adapter (Ethernet, Wi-Fi, WWAN...).
+	InterfaceClassPhysical
+	// Software loopback interface.
+	InterfaceClassLoopback
+	// VPN or other tunnel interface (Wintun, TAP, PPP, GRE...).
+	InterfaceClassTunnel
+	// Hyper-V virtual switch adapter on the host.
+	InterfaceClassHyperV
+	// Teredo IPv6 transition tunnel.
+	InterfaceClassTeredo
+	// 6to4 IPv6 transition tunnel.
+	InterfaceClass6to4
+	// IP-HTTPS IPv6 transition tunnel.
+	InterfaceClassIpHttps
+	// ISATAP IPv6 transition tunnel.
+	InterfaceClassIsatap
+	// Other virtual adapter (filter interfaces, Wi-Fi Direct, kernel debugger...).
+	InterfaceClassVirtual
+)
+
+func (c InterfaceClass) String() string {
+	switch c {
+	case InterfaceClassUnknown:
+		return "InterfaceClassUnknown"
+	case InterfaceClassPhysical:
+		return "InterfaceClassPhysical"
+	case InterfaceClassLoopback:
+		return "InterfaceClassLoopback"
+	case InterfaceClassTunnel:
+		return "InterfaceClassTunnel"
+	case InterfaceClassHyperV:
+		return "InterfaceClassHyperV"
+	case InterfaceClassTeredo:
+		return "InterfaceClassTeredo"
+	case InterfaceClass6to4:
+		return "InterfaceClass6to4"
+	case InterfaceClassIpHttps:
+		return "InterfaceClassIpHttps"
+	case InterfaceClassIsatap:
+		return "InterfaceClassIsatap"
+	case InterfaceClassVirtual:
+		return "InterfaceClassVirtual"
+	default:
+		return fmt.Sprintf("InterfaceClass_UNKNOWN(%d)", c)
+	}
+}
+
+// Returns true for any class other than InterfaceClassPhysical, i.e. for interfaces which UI or route exclusion
+// logic usually skips.
+func (c InterfaceClass) IsVirtual() bool {
+	return c != InterfaceClassPhysical
+}
+
+// Returns true for IPv6 transition tunnels (Teredo, 6to4, IP-HTTPS and ISATAP).
+func (c InterfaceClass) IsTransitionTunnel() bool {
+	return c == InterfaceClassTeredo || c == InterfaceClass6to4 || c == InterfaceClassIpHttps ||
+		c == InterfaceClassIsatap
+}
+
+// Attributes of an interface which ClassifyInterface() is based on. They can be taken from IfRow (see
+// IfRow.Traits()), or combined from Interface and IfRow (see Interface.Classify()).
+type InterfaceTraits struct {
+	Type               IfType
+	TunnelType         TunnelType
+	PhysicalMediumType NdisPhysicalMedium
+	Flags              InterfaceAndOperStatusFlags
+	Description        string
+	PhysicalAddress    net.HardwareAddr
+}
+
+// OUI Microsoft uses for Hyper-V virtual adapters.
+var hyperVOui = []byte{0x00, 0x15, 0x5d}
+
+// Description fragments of common VPN adapters which present themselves as Ethernet.
+var vpnDescriptionMarkers = []string{"tap-windows", "tap-win32", "wintun", "wireguard", "openvpn", "vpn"}
+
+// Classifies the interface. Order of checks:
+// 1) IF_TYPE_SOFTWARE_LOOPBACK is loopback;
+// 2) IF_TYPE_TUNNEL is a transition tunnel according to TunnelType, otherwise a (VPN) tunnel;
+// 3) PPP and proprietary virtual interfaces (e.g. Wintun) are tunnels;
+// 4) adapters marked as hardware, with 802.3 or native 802.11 physical medium, are physical; in a Hyper-V guest this
+// includes its synthetic adapter;
+// 5) adapters with Hyper-V MAC address or description are Hyper-V switch adapters;
+// 6) adapters with a well-known VPN description (e.g. TAP) are tunnels;
+// 7) remaining adapters marked as hardware (e.g. WWAN or Bluetooth) are physical;
+// 8) anything else is virtual.
+func ClassifyInterface(traits *InterfaceTraits) InterfaceClass {
+
+	if traits == nil {
+		return InterfaceClassUnknown
+	}
+
+	switch traits.Type {
+	case IF_TYPE_SOFTWARE_LOOPBACK:
+		return InterfaceClassLoopback
+	case IF_TYPE_TUNNEL:
+		switch traits.TunnelType {
+		case TUNNEL_TYPE_TEREDO:
+			return InterfaceClassTeredo
+		case TUNNEL_TYPE_6TO4:
+			return InterfaceClass6to4
+		case TUNNEL_TYPE_IPHTTPS:
+			return InterfaceClassIpHttps
+		case TUNNEL_TYPE_ISATAP:
+			return InterfaceClassIsatap
+		default:
+			return InterfaceClassTunnel
+		}
+	case IF_TYPE_PPP, IF_TYPE_PROP_VIRTUAL:
+		return InterfaceClassTunnel
+	}
+
+	hardware := traits.Flags.HardwareInterface && !traits.Flags.FilterInterface
+
+	if hardware && (traits.PhysicalMediumType == NdisPhysicalMedium802_3 ||
+		traits.PhysicalMediumType == NdisPhysicalMediumNative802_11) {
+		return InterfaceClassPhysical
+	}
+
+	description := strings.ToLower(traits.Description)
+
+	if (len(traits.PhysicalAddress) >= len(hyperVOui) && bytes.Equal(traits.PhysicalAddress[:len(hyperVOui)],
+		hyperVOui)) || strings.Contains(description, "hyper-v") {
+		return InterfaceClassHyperV
+	}
+
+	for _, marker := range vpnDescriptionMarkers {
+		if strings.Contains(description, marker) {
+			return InterfaceClassTunnel
+		}
+	}
+
+	if hardware {
+		return InterfaceClassPhysical
+	}
+
+	return InterfaceClassVirtual
+}
+
+// Returns classification traits of the row.
+func (ifr *IfRow) Traits() *InterfaceTraits {
+
+	if ifr == nil {
+		return nil
+	}
+
+	return &InterfaceTraits{
+		Type:               ifr.Type,
+		TunnelType:         ifr.TunnelType,
+		PhysicalMediumType: ifr.PhysicalMediumType,
+		Flags:              ifr.InterfaceAndOperStatusFlags,
+		Description:        ifr.Description,
+		PhysicalAddress:    ifr.physicalAddress,
+	}
+}
+
+// Classifies the interface. See ClassifyInterface() for details.
+func (ifr *IfRow) Classify() InterfaceClass {
+	return ClassifyInterface(ifr.Traits())
+}
+
+// Classifies the interface, combining its attributes with those from IfRow. See ClassifyInterface() for details.
+func (ifc *Interface) Classify() (InterfaceClass, error) {
+
+	row, err := ifc.GetIfRow(MibIfEntryNormalWithoutStatistics)
+
+	if err != nil {
+		return InterfaceClassUnknown, err
+	}
+
+	return ClassifyInterface(ifc.traits(row)), nil
+}
+
+// Combines traits of 'row' (which may be nil) with the interface's own attributes.
+func (ifc *Interface) traits(row *IfRow) *InterfaceTraits {
+
+	traits := row.Traits()
+
+	if traits == nil {
+		traits = &InterfaceTraits{Description: ifc.Description}
+	}
+
+	traits.Type = ifc.IfType
+	traits.TunnelType = ifc.TunnelType
+	traits.PhysicalAddress = ifc.PhysicalAddress
+
+	return traits
+}
+
+// Returns the UDP port used by the Teredo client. Corresponds to GetTeredoPort function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getteredoport). Fails with
+// ERROR_NOT_READY if the Teredo client isn't running.
+func GetTeredoPort() (uint16, error) {
+
+	port := uint16(0)
+
+	result := getTeredoPort(&port)
+
+	if result == 0 {
+		return port, nil
+	} else {
+		return 0, newOperationError("iphlpapi.GetTeredoPort", 0, "", windows.Errno(result))
+	}
+}
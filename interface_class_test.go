@@ -0,0 +1,211 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"net"
+	"testing"
+)
+
+const (
+	interfaceClass_print = false
+)
+
+func mustParseMAC(s string) net.HardwareAddr {
+
+	mac, err := net.ParseMAC(s)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return mac
+}
+
+// Traits as reported by real systems.
+var interfaceClassFixtures = []struct {
+	name     string
+	traits   InterfaceTraits
+	expected InterfaceClass
+}{
+	{"Intel Ethernet", InterfaceTraits{
+		Type:               IF_TYPE_ETHERNET_CSMACD,
+		PhysicalMediumType: NdisPhysicalMedium802_3,
+		Flags:              InterfaceAndOperStatusFlags{HardwareInterface: true, ConnectorPresent: true},
+		Description:        "Intel(R) Ethernet Connection (4) I219-LM",
+		PhysicalAddress:    mustParseMAC("8c:16:45:12:34:56"),
+	}, InterfaceClassPhysical},
+	{"Wi-Fi", InterfaceTraits{
+		Type:               IF_TYPE_IEEE80211,
+		PhysicalMediumType: NdisPhysicalMediumNative802_11,
+		Flags:              InterfaceAndOperStatusFlags{HardwareInterface: true, ConnectorPresent: true},
+		Description:        "Intel(R) Wireless-AC 9560 160MHz",
+	}, InterfaceClassPhysical},
+	{"Wi-Fi Direct", InterfaceTraits{
+		Type:               IF_TYPE_IEEE80211,
+		PhysicalMediumType: NdisPhysicalMediumNative802_11,
+		Description:        "Microsoft Wi-Fi Direct Virtual Adapter",
+	}, InterfaceClassVirtual},
+	{"Mobile broadband", InterfaceTraits{
+		Type:               IF_TYPE_WWANPP,
+		PhysicalMediumType: NdisPhysicalMediumWirelessWan,
+		Flags:              InterfaceAndOperStatusFlags{HardwareInterface: true, ConnectorPresent: true},
+		Description:        "Qualcomm Snapdragon X20 LTE",
+	}, InterfaceClassPhysical},
+	{"Hyper-V guest adapter", InterfaceTraits{
+		Type:               IF_TYPE_ETHERNET_CSMACD,
+		PhysicalMediumType: NdisPhysicalMedium802_3,
+		Flags:              InterfaceAndOperStatusFlags{HardwareInterface: true, ConnectorPresent: true},
+		Description:        "Microsoft Hyper-V Network Adapter",
+		PhysicalAddress:    mustParseMAC("00:15:5d:01:02:03"),
+	}, InterfaceClassPhysical},
+	{"Hyper-V switch", InterfaceTraits{
+		Type:            IF_TYPE_ETHERNET_CSMACD,
+		Description:     "Hyper-V Virtual Ethernet Adapter",
+		PhysicalAddress: mustParseMAC("00:15:5d:a0:b1:c2"),
+	}, InterfaceClassHyperV},
+	{"Hyper-V switch without description", InterfaceTraits{
+		Type:            IF_TYPE_ETHERNET_CSMACD,
+		PhysicalAddress: mustParseMAC("00:15:5d:a0:b1:c3"),
+	}, InterfaceClassHyperV},
+	{"Loopback", InterfaceTraits{
+		Type:        IF_TYPE_SOFTWARE_LOOPBACK,
+		Description: "Software Loopback Interface 1",
+	}, InterfaceClassLoopback},
+	{"Wintun", InterfaceTraits{
+		Type:        IF_TYPE_PROP_VIRTUAL,
+		Description: "Wintun Userspace Tunnel",
+	}, InterfaceClassTunnel},
+	{"TAP-Windows", InterfaceTraits{
+		Type:               IF_TYPE_ETHERNET_CSMACD,
+		PhysicalMediumType: NdisPhysicalMedium802_3,
+		Description:        "TAP-Windows Adapter V9",
+		PhysicalAddress:    mustParseMAC("00:ff:12:34:56:78"),
+	}, InterfaceClassTunnel},
+	{"VPN adapter marked as hardware", InterfaceTraits{
+		Type:        IF_TYPE_ETHERNET_CSMACD,
+		Flags:       InterfaceAndOperStatusFlags{HardwareInterface: true},
+		Description: "OpenVPN Data Channel Offload",
+	}, InterfaceClassTunnel},
+	{"RAS PPP", InterfaceTraits{
+		Type:        IF_TYPE_PPP,
+		Description: "WAN Miniport (IKEv2)",
+	}, InterfaceClassTunnel},
+	{"Teredo", InterfaceTraits{
+		Type:        IF_TYPE_TUNNEL,
+		TunnelType:  TUNNEL_TYPE_TEREDO,
+		Description: "Teredo Tunneling Pseudo-Interface",
+	}, InterfaceClassTeredo},
+	{"6to4", InterfaceTraits{
+		Type:        IF_TYPE_TUNNEL,
+		TunnelType:  TUNNEL_TYPE_6TO4,
+		Description: "Microsoft 6to4 Adapter",
+	}, InterfaceClass6to4},
+	{"IP-HTTPS", InterfaceTraits{
+		Type:        IF_TYPE_TUNNEL,
+		TunnelType:  TUNNEL_TYPE_IPHTTPS,
+		Description: "Microsoft IP-HTTPS Platform Adapter",
+	}, InterfaceClassIpHttps},
+	{"ISATAP", InterfaceTraits{
+		Type:        IF_TYPE_TUNNEL,
+		TunnelType:  TUNNEL_TYPE_ISATAP,
+		Description: "Microsoft ISATAP Adapter",
+	}, InterfaceClassIsatap},
+	{"GRE tunnel", InterfaceTraits{
+		Type:       IF_TYPE_TUNNEL,
+		TunnelType: TUNNEL_TYPE_DIRECT,
+	}, InterfaceClassTunnel},
+	{"Filter interface", InterfaceTraits{
+		Type:        IF_TYPE_ETHERNET_CSMACD,
+		Flags:       InterfaceAndOperStatusFlags{HardwareInterface: true, FilterInterface: true},
+		Description: "Intel(R) Ethernet Connection-WFP Native MAC Layer LightWeight Filter-0000",
+	}, InterfaceClassVirtual},
+}
+
+func TestClassifyInterface(t *testing.T) {
+
+	for _, fixture := range interfaceClassFixtures {
+		if class := ClassifyInterface(&fixture.traits); class != fixture.expected {
+			t.Errorf("ClassifyInterface() for %s returned %s, although %s is expected.", fixture.name,
+				class.String(), fixture.expected.String())
+		}
+	}
+
+	if class := ClassifyInterface(nil); class != InterfaceClassUnknown {
+		t.Errorf("ClassifyInterface(nil) returned %s, although InterfaceClassUnknown is expected.", class.String())
+	}
+}
+
+func TestIfRowTraitsPhysicalAddress(t *testing.T) {
+
+	row := wtMibIfRow2{Type: IF_TYPE_ETHERNET_CSMACD, PhysicalAddressLength: 6}
+	copy(row.PhysicalAddress[:], []byte{0x00, 0x15, 0x5d, 0xa0, 0xb1, 0xc2})
+
+	traits := row.toIfRow().Traits()
+
+	if traits.PhysicalAddress.String() != "00:15:5d:a0:b1:c2" {
+		t.Errorf("IfRow.Traits() returned physical address %s, although 00:15:5d:a0:b1:c2 is expected.",
+			traits.PhysicalAddress.String())
+	}
+
+	if class := ClassifyInterface(traits); class != InterfaceClassHyperV {
+		t.Errorf("ClassifyInterface() returned %s, although InterfaceClassHyperV is expected.", class.String())
+	}
+}
+
+func TestInterfaceClassPredicates(t *testing.T) {
+
+	if InterfaceClassPhysical.IsVirtual() || !InterfaceClassHyperV.IsVirtual() {
+		t.Error("IsVirtual() returned unexpected result.")
+	}
+
+	if !InterfaceClassTeredo.IsTransitionTunnel() || InterfaceClassTunnel.IsTransitionTunnel() {
+		t.Error("IsTransitionTunnel() returned unexpected result.")
+	}
+}
+
+func TestInterfaceClassify(t *testing.T) {
+
+	ifcs, err := GetInterfaces()
+
+	if err != nil {
+		t.Errorf("GetInterfaces() returned an error: %v", err)
+		return
+	}
+
+	for _, ifc := range ifcs {
+
+		class, err := ifc.Classify()
+
+		if err != nil {
+			t.Errorf("Interface.Classify() returned an error: %v", err)
+			continue
+		}
+
+		if ifc.IfType == IF_TYPE_SOFTWARE_LOOPBACK && class != InterfaceClassLoopback {
+			t.Errorf("Interface.Classify() returned %s for loopback interface.", class.String())
+		}
+
+		if interfaceClass_print {
+			t.Logf("%s: %s", ifc.FriendlyName, class.String())
+		}
+	}
+}
+
+func TestGetTeredoPort(t *testing.T) {
+
+	port, err := GetTeredoPort()
+
+	if err != nil {
+		// Teredo is disabled on many systems.
+		t.Logf("GetTeredoPort() returned an error: %v", err)
+		return
+	}
+
+	if interfaceClass_print {
+		t.Logf("Teredo port: %d", port)
+	}
+}
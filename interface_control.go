@@ -0,0 +1,194 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// GUID_DEVCLASS_NET defined in devguid.h
+var guidDevClassNet = windows.GUID{
+	Data1: 0x4d36e972,
+	Data2: 0xe325,
+	Data3: 0x11ce,
+	Data4: [8]byte{0xbf, 0xc1, 0x08, 0x00, 0x2b, 0xe1, 0x03, 0x18},
+}
+
+// Enables the network adapter of the interface with specified LUID, the same way the network connections folder does
+// (DIF_PROPERTYCHANGE through SetupAPI). If the interface has no PnP device (or SetupAPI fails), netsh is used as a
+// fallback. Requires administrative privileges.
+func EnableInterface(interfaceLuid uint64) error {
+	return setInterfaceAdminState(interfaceLuid, true)
+}
+
+// Disables the network adapter of the interface with specified LUID. See EnableInterface() for details.
+func DisableInterface(interfaceLuid uint64) error {
+	return setInterfaceAdminState(interfaceLuid, false)
+}
+
+// Enables the interface's network adapter. See EnableInterface() for details.
+func (ifc *Interface) Enable() error {
+	return EnableInterface(ifc.Luid)
+}
+
+// Disables the interface's network adapter. See EnableInterface() for details.
+func (ifc *Interface) Disable() error {
+	return DisableInterface(ifc.Luid)
+}
+
+// Changes alias (friendly name) of the interface with specified LUID. The connection is renamed through
+// INetConnection::Rename, which is what the network connections folder does; if that fails, netsh is used as a
+// fallback. Requires administrative privileges.
+func SetInterfaceAlias(interfaceLuid uint64, alias string) error {
+
+	if alias == "" {
+		return newKindError(ErrInvalidParameter, "SetInterfaceAlias() - alias can't be empty")
+	}
+
+	guid, err := InterfaceLuidToGuid(interfaceLuid)
+
+	if err != nil {
+		return err
+	}
+
+	nativeErr := renameNetConnection(interfaceLuid, guid, alias)
+
+	if nativeErr == nil {
+		return nil
+	}
+
+	row, err := GetIfRow(interfaceLuid, MibIfEntryNormalWithoutStatistics)
+
+	if err != nil {
+		return fmt.Errorf("SetInterfaceAlias() - %w; getting current alias: %v", nativeErr, err)
+	}
+
+	if err := RenameInterface(row.Alias, alias); err != nil {
+		return fmt.Errorf("SetInterfaceAlias() - %w; netsh fallback: %v", nativeErr, err)
+	}
+
+	return nil
+}
+
+// Changes alias (friendly name) of the interface. See SetInterfaceAlias() for details. On success ifc.FriendlyName is
+// updated as well.
+func (ifc *Interface) Rename(alias string) error {
+
+	if err := SetInterfaceAlias(ifc.Luid, alias); err != nil {
+		return err
+	}
+
+	ifc.FriendlyName = alias
+
+	return nil
+}
+
+func setInterfaceAdminState(interfaceLuid uint64, enable bool) error {
+
+	guid, err := InterfaceLuidToGuid(interfaceLuid)
+
+	if err != nil {
+		return err
+	}
+
+	nativeErr := setNetDeviceState(guid, enable)
+
+	if nativeErr == nil {
+		return nil
+	}
+
+	row, err := GetIfRow(interfaceLuid, MibIfEntryNormalWithoutStatistics)
+
+	if err != nil {
+		return fmt.Errorf("setInterfaceAdminState() - %v; getting alias: %w", nativeErr, err)
+	}
+
+	cmd, err := netshSetInterfaceAdminCmd(row.Alias, enable)
+
+	if err == nil {
+		err = runNetsh([]string{cmd})
+	}
+
+	if err != nil {
+		return fmt.Errorf("setInterfaceAdminState() - %v; netsh fallback: %w", nativeErr, err)
+	}
+
+	return nil
+}
+
+// Finds the network class device whose NetCfgInstanceId equals 'guid', and enables or disables it.
+func setNetDeviceState(guid *windows.GUID, enable bool) error {
+
+	devInfo, err := windows.SetupDiGetClassDevsEx(&guidDevClassNet, "", 0, windows.DIGCF_PRESENT, 0, "")
+
+	if err != nil {
+		return err
+	}
+
+	defer devInfo.Close()
+
+	for i := 0; ; i++ {
+
+		data, err := devInfo.EnumDeviceInfo(i)
+
+		if err != nil {
+			if errors.Is(err, windows.ERROR_NO_MORE_ITEMS) {
+				break
+			}
+			continue
+		}
+
+		instanceId, err := netCfgInstanceId(devInfo, data)
+
+		if err != nil || !guidsEqual(&instanceId, guid) {
+			continue
+		}
+
+		params := windows.PropChangeParams{
+			ClassInstallHeader: *windows.MakeClassInstallHeader(windows.DIF_PROPERTYCHANGE),
+			StateChange:        windows.DICS_DISABLE,
+			Scope:              windows.DICS_FLAG_GLOBAL,
+		}
+
+		if enable {
+			params.StateChange = windows.DICS_ENABLE
+		}
+
+		err = devInfo.SetClassInstallParams(data, &params.ClassInstallHeader, uint32(unsafe.Sizeof(params)))
+
+		if err != nil {
+			return err
+		}
+
+		return devInfo.CallClassInstaller(windows.DIF_PROPERTYCHANGE, data)
+	}
+
+	return newKindError(ErrNotFound, "setNetDeviceState() - network device %s not found", guidToString(guid))
+}
+
+func netCfgInstanceId(devInfo windows.DevInfo, data *windows.DevInfoData) (windows.GUID, error) {
+
+	key, err := devInfo.OpenDevRegKey(data, windows.DICS_FLAG_GLOBAL, 0, windows.DIREG_DRV, windows.KEY_QUERY_VALUE)
+
+	if err != nil {
+		return windows.GUID{}, err
+	}
+
+	defer windows.RegCloseKey(key)
+
+	value, _, err := registry.Key(key).GetStringValue("NetCfgInstanceId")
+
+	if err != nil {
+		return windows.GUID{}, err
+	}
+
+	return windows.GUIDFromString(value)
+}
@@ -0,0 +1,251 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Interface metric settings of one family, as saved by BackupInterfaceMetrics().
+type InterfaceMetricSetting struct {
+	InterfaceLuid      uint64
+	Family             AddressFamily
+	Metric             uint32
+	UseAutomaticMetric bool
+}
+
+func (s *InterfaceMetricSetting) String() string {
+
+	if s == nil {
+		return "<nil>"
+	}
+
+	if s.UseAutomaticMetric {
+		return fmt.Sprintf("%d/%s: automatic (%d)", s.InterfaceLuid, s.Family.String(), s.Metric)
+	}
+
+	return fmt.Sprintf("%d/%s: %d", s.InterfaceLuid, s.Family.String(), s.Metric)
+}
+
+// Saved interface metric settings, restorable by a single Restore() call.
+type InterfaceMetricBackup struct {
+	Settings []*InterfaceMetricSetting
+}
+
+// Saves metric settings of both families of the interface with specified LUID. Families which aren't enabled on the
+// interface are skipped.
+func BackupInterfaceMetrics(interfaceLuid uint64) (*InterfaceMetricBackup, error) {
+
+	ipifcs, err := GetIpInterfaces(AF_UNSPEC)
+
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &InterfaceMetricBackup{}
+
+	for _, ipifc := range ipifcs {
+		if ipifc.InterfaceLuid == interfaceLuid {
+			backup.Settings = append(backup.Settings, &InterfaceMetricSetting{
+				InterfaceLuid:      ipifc.InterfaceLuid,
+				Family:             ipifc.Family,
+				Metric:             ipifc.Metric,
+				UseAutomaticMetric: ipifc.UseAutomaticMetric,
+			})
+		}
+	}
+
+	if len(backup.Settings) == 0 {
+		return nil, newKindError(ErrInterfaceGone,
+			"BackupInterfaceMetrics() - IP interface with LUID %d not found", interfaceLuid)
+	}
+
+	return backup, nil
+}
+
+// Restores saved metric settings. All settings are attempted; errors are returned together.
+func (backup *InterfaceMetricBackup) Restore() error {
+
+	if backup == nil {
+		return nil
+	}
+
+	var errs []error
+
+	for _, s := range backup.Settings {
+
+		ipifc, err := GetIpInterface(s.InterfaceLuid, s.Family)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		update := ipifc.Update().UseAutomaticMetric(s.UseAutomaticMetric)
+
+		if !s.UseAutomaticMetric {
+			update.Metric(s.Metric)
+		}
+
+		if _, err := update.Apply(); err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", s.String(), err))
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return multiError(errs)
+	}
+}
+
+func (backup *InterfaceMetricBackup) String() string {
+
+	if backup == nil {
+		return "<nil>"
+	}
+
+	lines := make([]string, len(backup.Settings))
+
+	for i, s := range backup.Settings {
+		lines[i] = s.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Disables automatic metric of the interface with specified LUID, and sets its metric. If 'family' is AF_UNSPEC, the
+// metric is set for both families (skipping a family which isn't enabled on the interface).
+func SetInterfaceMetric(interfaceLuid uint64, family AddressFamily, metric uint32) error {
+
+	families := []AddressFamily{family}
+
+	if family == AF_UNSPEC {
+
+		ipifcs, err := GetIpInterfaces(AF_UNSPEC)
+
+		if err != nil {
+			return err
+		}
+
+		families = ipInterfaceFamilies(interfaceLuid, ipifcs)
+
+		if len(families) == 0 {
+			return newKindError(ErrInterfaceGone, "SetInterfaceMetric() - IP interface with LUID %d not found", interfaceLuid)
+		}
+	}
+
+	for _, f := range families {
+
+		ipifc, err := GetIpInterface(interfaceLuid, f)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := ipifc.Update().UseAutomaticMetric(false).Metric(metric).Apply(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Disables automatic metric of the interface, and sets its metric. See SetInterfaceMetric() for details.
+func (ifc *Interface) SetMetric(family AddressFamily, metric uint32) error {
+	return SetInterfaceMetric(ifc.Luid, family, metric)
+}
+
+// Makes the interface with specified LUID win interface metric ties (i.e. for DNS server selection), by setting its
+// metric of both families to the same value, strictly lower than metrics of all other IP interfaces of either
+// family. Previous settings are returned; call Restore() on them to revert the change.
+func PreferInterface(interfaceLuid uint64) (*InterfaceMetricBackup, error) {
+
+	ipifcs, err := GetIpInterfaces(AF_UNSPEC)
+
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := preferredInterfaceMetric(interfaceLuid, ipifcs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	backup, err := BackupInterfaceMetrics(interfaceLuid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := SetInterfaceMetric(interfaceLuid, AF_UNSPEC, metric); err != nil {
+		if rerr := backup.Restore(); rerr != nil {
+			return nil, fmt.Errorf("PreferInterface() - %w; restoring: %v", err, rerr)
+		}
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// Returns distinct families of IP interfaces with specified LUID, IPv4 first.
+func ipInterfaceFamilies(interfaceLuid uint64, ipifcs []*IpInterface) []AddressFamily {
+
+	var families []AddressFamily
+
+	for _, ipifc := range ipifcs {
+		if ipifc.InterfaceLuid == interfaceLuid {
+			families = append(families, ipifc.Family)
+		}
+	}
+
+	return distinctFamilies(families)
+}
+
+// Computes the metric which makes the interface with specified LUID strictly lowest among 'ipifcs' of both families.
+// Metrics of other interfaces are taken as reported, which for interfaces with automatic metric is the value
+// currently assigned by the system.
+func preferredInterfaceMetric(interfaceLuid uint64, ipifcs []*IpInterface) (uint32, error) {
+
+	found := false
+	lowest := uint32(0)
+	haveOthers := false
+
+	for _, ipifc := range ipifcs {
+
+		if ipifc.InterfaceLuid == interfaceLuid {
+			found = true
+			continue
+		}
+
+		if !haveOthers || ipifc.Metric < lowest {
+			lowest = ipifc.Metric
+			haveOthers = true
+		}
+	}
+
+	if !found {
+		return 0, newKindError(ErrInterfaceGone,
+			"preferredInterfaceMetric() - IP interface with LUID %d not found", interfaceLuid)
+	}
+
+	if !haveOthers {
+		// Nothing to compete with; the lowest valid metric is used.
+		return 1, nil
+	}
+
+	if lowest <= 1 {
+		return 0, newKindError(ErrInvalidParameter,
+			"preferredInterfaceMetric() - another interface already has metric %d", lowest)
+	}
+
+	return lowest - 1, nil
+}
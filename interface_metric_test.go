@@ -0,0 +1,88 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+)
+
+const (
+	interfaceMetric_print = false
+)
+
+func TestPreferredInterfaceMetric(t *testing.T) {
+
+	ipifcs := []*IpInterface{
+		{InterfaceLuid: 1, Family: AF_INET, Metric: 5},
+		{InterfaceLuid: 1, Family: AF_INET6, Metric: 5},
+		{InterfaceLuid: 2, Family: AF_INET, Metric: 25},
+		{InterfaceLuid: 2, Family: AF_INET6, Metric: 15},
+		{InterfaceLuid: 3, Family: AF_INET, Metric: 35},
+	}
+
+	metric, err := preferredInterfaceMetric(1, ipifcs)
+
+	if err != nil {
+		t.Fatalf("preferredInterfaceMetric() returned an error: %v", err)
+	}
+
+	if metric != 14 {
+		t.Errorf("preferredInterfaceMetric() returned %d, although 14 is expected.", metric)
+	}
+
+	if _, err := preferredInterfaceMetric(4, ipifcs); err == nil {
+		t.Error("preferredInterfaceMetric() for non-existing LUID didn't return an error, although it's expected.")
+	}
+
+	ipifcs = append(ipifcs, &IpInterface{InterfaceLuid: 3, Family: AF_INET6, Metric: 1})
+
+	if _, err := preferredInterfaceMetric(1, ipifcs); err == nil {
+		t.Error("preferredInterfaceMetric() with metric 1 taken didn't return an error, although it's expected.")
+	}
+
+	metric, err = preferredInterfaceMetric(1, ipifcs[:2])
+
+	if err != nil || metric != 1 {
+		t.Errorf("preferredInterfaceMetric() without other interfaces returned %d, %v, although 1 is expected.",
+			metric, err)
+	}
+}
+
+func TestIpInterfaceFamilies(t *testing.T) {
+
+	ipifcs := []*IpInterface{
+		{InterfaceLuid: 2, Family: AF_INET6},
+		{InterfaceLuid: 1, Family: AF_INET6},
+		{InterfaceLuid: 2, Family: AF_INET},
+	}
+
+	if families := ipInterfaceFamilies(1, ipifcs); len(families) != 1 || families[0] != AF_INET6 {
+		t.Errorf("ipInterfaceFamilies() returned %v, although [AF_INET6] is expected.", families)
+	}
+
+	if families := ipInterfaceFamilies(2, ipifcs); len(families) != 2 || families[0] != AF_INET ||
+		families[1] != AF_INET6 {
+		t.Errorf("ipInterfaceFamilies() returned %v, although [AF_INET AF_INET6] is expected.", families)
+	}
+}
+
+func TestBackupInterfaceMetrics(t *testing.T) {
+
+	backup, err := BackupInterfaceMetrics(existingLuid)
+
+	if err != nil {
+		t.Errorf("BackupInterfaceMetrics() returned an error: %v", err)
+		return
+	}
+
+	if len(backup.Settings) == 0 {
+		t.Error("BackupInterfaceMetrics() returned no settings.")
+	}
+
+	if interfaceMetric_print {
+		t.Logf("Interface metrics:\n%s", backup.String())
+	}
+}
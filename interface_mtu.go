@@ -0,0 +1,190 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+)
+
+const (
+	ipv4HeaderLength = 20
+	ipv6HeaderLength = 40
+	udpHeaderLength  = 8
+	// WireGuard data message header (16 bytes) plus Poly1305 authentication tag (16 bytes).
+	wireGuardHeaderLength = 32
+)
+
+// MTU of an interface, as seen by the link layer (IfRow.Mtu) and by each IP family (IpInterface.NlMtu). A family
+// which isn't enabled on the interface has zero MTU.
+type InterfaceMTU struct {
+	Link uint32
+	IPv4 uint32
+	IPv6 uint32
+}
+
+func (mtu *InterfaceMTU) String() string {
+
+	if mtu == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("Link: %d\nIPv4: %d\nIPv6: %d", mtu.Link, mtu.IPv4, mtu.IPv6)
+}
+
+// Returns the interface's MTU of the link and of both IP families.
+func (ifc *Interface) GetMTU() (*InterfaceMTU, error) {
+
+	row, err := ifc.GetIfRow(MibIfEntryNormalWithoutStatistics)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ipifcs, err := GetIpInterfaces(AF_UNSPEC)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mtu := &InterfaceMTU{Link: row.Mtu}
+
+	for _, ipifc := range ipifcs {
+
+		if ipifc.InterfaceLuid != ifc.Luid {
+			continue
+		}
+
+		switch ipifc.Family {
+		case AF_INET:
+			mtu.IPv4 = ipifc.NlMtu
+		case AF_INET6:
+			mtu.IPv6 = ipifc.NlMtu
+		}
+	}
+
+	return mtu, nil
+}
+
+// Sets NlMtu of the interface's IPv4 and IPv6 IP interfaces. Zero leaves the family's MTU unchanged. Both values are
+// validated before anything is changed: 'v4' has to be at least 576, 'v6' at least 1280 (RFC 8200), and neither can
+// exceed 65535. If setting the IPv6 MTU fails after the IPv4 one was set, the previous IPv4 MTU is restored. Returns
+// the effective MTU, re-read after the change; ifc.Mtu is updated accordingly.
+func (ifc *Interface) SetMTU(v4, v6 uint32) (*InterfaceMTU, error) {
+
+	if err := validateMTU(AF_INET, v4); err != nil {
+		return nil, newKindError(ErrInvalidParameter, "Interface.SetMTU() - %v", err)
+	}
+
+	if err := validateMTU(AF_INET6, v6); err != nil {
+		return nil, newKindError(ErrInvalidParameter, "Interface.SetMTU() - %v", err)
+	}
+
+	// IP interfaces already changed, as they were before the change.
+	var changed []IpInterface
+
+	for _, change := range []struct {
+		family AddressFamily
+		mtu    uint32
+	}{{AF_INET, v4}, {AF_INET6, v6}} {
+
+		if change.mtu == 0 {
+			continue
+		}
+
+		ipifc, err := ifc.GetIpInterface(change.family)
+		var previous IpInterface
+
+		if err == nil {
+			previous = *ipifc
+			_, err = ipifc.Update().MTU(change.mtu).Apply()
+		}
+
+		if err != nil {
+			if rerr := restoreMTU(changed); rerr != nil {
+				return nil, fmt.Errorf("Interface.SetMTU() - %w; restoring: %v", err, rerr)
+			}
+			return nil, err
+		}
+
+		changed = append(changed, previous)
+	}
+
+	mtu, err := ifc.GetMTU()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mtu.IPv4 != 0 {
+		ifc.Mtu = mtu.IPv4
+	} else if mtu.IPv6 != 0 {
+		ifc.Mtu = mtu.IPv6
+	}
+
+	return mtu, nil
+}
+
+// Sets MTU of the IP interfaces back to their NlMtu.
+func restoreMTU(ipifcs []IpInterface) error {
+
+	for i := range ipifcs {
+		if _, err := ipifcs[i].Update().MTU(ipifcs[i].NlMtu).Apply(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Zero MTU is valid, meaning "unchanged".
+func validateMTU(family AddressFamily, mtu uint32) error {
+
+	if mtu == 0 {
+		return nil
+	}
+
+	min := uint32(ipv4MinimumMtu)
+
+	if family == AF_INET6 {
+		min = ipv6MinimumMtu
+	}
+
+	if mtu < min || mtu > ipMaximumMtu {
+		return fmt.Errorf("%s MTU %d is out of range [%d, %d]", family.String(), mtu, min, ipMaximumMtu)
+	}
+
+	return nil
+}
+
+// Returns encapsulation overhead of WireGuard over the outer IP family: IP and UDP headers, plus WireGuard's own
+// header and authentication tag.
+func WireGuardOverhead(outer AddressFamily) uint32 {
+
+	if outer == AF_INET6 {
+		return ipv6HeaderLength + udpHeaderLength + wireGuardHeaderLength
+	}
+
+	return ipv4HeaderLength + udpHeaderLength + wireGuardHeaderLength
+}
+
+// Derives a safe tunnel MTU from MTU of the underlying (physical) interface and encapsulation overhead, so that
+// encapsulated packets aren't fragmented. Returns an error if the result is below the IPv4 minimum (576); results
+// below 1280 can't carry IPv6 inside the tunnel, which is reported by the second return value being false.
+func SafeTunnelMTU(underlyingMTU, overhead uint32) (mtu uint32, ipv6Capable bool, err error) {
+
+	if underlyingMTU <= overhead || underlyingMTU-overhead < ipv4MinimumMtu {
+		return 0, false, newKindError(ErrInvalidParameter,
+			"SafeTunnelMTU() - underlying MTU %d is too small for overhead %d", underlyingMTU, overhead)
+	}
+
+	mtu = underlyingMTU - overhead
+
+	if mtu > ipMaximumMtu {
+		mtu = ipMaximumMtu
+	}
+
+	return mtu, mtu >= ipv6MinimumMtu, nil
+}
@@ -0,0 +1,106 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	interfaceMtu_print = false
+)
+
+func TestValidateMTU(t *testing.T) {
+
+	tests := []struct {
+		family AddressFamily
+		mtu    uint32
+		valid  bool
+	}{
+		{AF_INET, 0, true},
+		{AF_INET6, 0, true},
+		{AF_INET, 576, true},
+		{AF_INET, 575, false},
+		{AF_INET6, 1280, true},
+		{AF_INET6, 1279, false},
+		{AF_INET6, 65535, true},
+		{AF_INET, 65536, false},
+	}
+
+	for _, test := range tests {
+		if err := validateMTU(test.family, test.mtu); (err == nil) != test.valid {
+			t.Errorf("validateMTU(%s, %d) returned %v, although valid=%v is expected.", test.family.String(),
+				test.mtu, err, test.valid)
+		}
+	}
+}
+
+func TestSafeTunnelMTU(t *testing.T) {
+
+	tests := []struct {
+		underlying  uint32
+		overhead    uint32
+		mtu         uint32
+		ipv6Capable bool
+		fails       bool
+	}{
+		{1500, WireGuardOverhead(AF_INET), 1440, true, false},
+		{1500, WireGuardOverhead(AF_INET6), 1420, true, false},
+		{1340, WireGuardOverhead(AF_INET6), 1260, false, false},
+		{600, 60, 0, false, true},
+		{40, 60, 0, false, true},
+		{70000, 0, 65535, true, false},
+	}
+
+	for _, test := range tests {
+
+		mtu, ipv6Capable, err := SafeTunnelMTU(test.underlying, test.overhead)
+
+		if test.fails {
+			if !errors.Is(err, ErrInvalidParameter) {
+				t.Errorf("SafeTunnelMTU(%d, %d) returned %v, although ErrInvalidParameter is expected.",
+					test.underlying, test.overhead, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("SafeTunnelMTU() returned an error: %v", err)
+			continue
+		}
+
+		if mtu != test.mtu || ipv6Capable != test.ipv6Capable {
+			t.Errorf("SafeTunnelMTU(%d, %d) returned %d/%v, although %d/%v is expected.", test.underlying,
+				test.overhead, mtu, ipv6Capable, test.mtu, test.ipv6Capable)
+		}
+	}
+}
+
+func TestInterfaceGetMTU(t *testing.T) {
+
+	ifc, err := InterfaceFromLUID(existingLuid)
+
+	if err != nil {
+		t.Errorf("InterfaceFromLUID() returned an error: %v", err)
+		return
+	}
+
+	mtu, err := ifc.GetMTU()
+
+	if err != nil {
+		t.Errorf("Interface.GetMTU() returned an error: %v", err)
+		return
+	}
+
+	if mtu.IPv4 == 0 && mtu.IPv6 == 0 {
+		t.Error("Interface.GetMTU() returned zero MTU for both families.")
+	}
+
+	if interfaceMtu_print {
+		t.Logf("Interface MTU:\n%s", mtu.String())
+	}
+}
@@ -0,0 +1,411 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Predicate on interfaces. Matchers can be combined with MatchAll(), MatchAny() and MatchNot().
+type InterfaceMatcher func(ifc *Interface) bool
+
+// Matches interfaces matched by all 'matchers' (i.e. every interface if there are none).
+func MatchAll(matchers ...InterfaceMatcher) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		for _, matcher := range matchers {
+			if !matcher(ifc) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Matches interfaces matched by any of 'matchers' (i.e. no interface if there are none).
+func MatchAny(matchers ...InterfaceMatcher) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		for _, matcher := range matchers {
+			if matcher(ifc) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Matches interfaces not matched by 'matcher'.
+func MatchNot(matcher InterfaceMatcher) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		return !matcher(ifc)
+	}
+}
+
+// Matches interfaces of any of 'types'.
+func MatchIfTypes(types ...IfType) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		for _, t := range types {
+			if ifc.IfType == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Matches interfaces in any of 'statuses'.
+func MatchOperStatuses(statuses ...IfOperStatus) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		for _, status := range statuses {
+			if ifc.OperStatus == status {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Matches interfaces whose friendly name matches glob 'pattern' (see globToRegexp() for syntax), ignoring case as
+// Windows does. Returns an error if the pattern is malformed.
+func MatchFriendlyNameGlob(pattern string) (InterfaceMatcher, error) {
+	return globMatcher(pattern, func(ifc *Interface) string { return ifc.FriendlyName })
+}
+
+// Matches interfaces whose description matches glob 'pattern' (see globToRegexp() for syntax), ignoring case.
+// Returns an error if the pattern is malformed.
+func MatchDescriptionGlob(pattern string) (InterfaceMatcher, error) {
+	return globMatcher(pattern, func(ifc *Interface) string { return ifc.Description })
+}
+
+func globMatcher(pattern string, field func(ifc *Interface) string) (InterfaceMatcher, error) {
+
+	re, err := globToRegexp(pattern)
+
+	if err != nil {
+		return nil, newKindError(ErrInvalidParameter, "invalid glob pattern %q: %v", pattern, err)
+	}
+
+	return func(ifc *Interface) bool {
+		return re.MatchString(field(ifc))
+	}, nil
+}
+
+// Compiles glob 'pattern' into an anchored, case insensitive regular expression. Unlike path.Match(), '/' isn't
+// special, since names and descriptions aren't paths (e.g. "Killer Wireless-n/a/ac 1535 Wireless Network Adapter"):
+// '*' matches any sequence of characters, '?' matches any single character, '[...]' matches a character from the
+// class ('[!...]' or '[^...]' negates it; ranges such as 'a-z' are allowed), and '\' escapes the next character.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+
+	runes := []rune(pattern)
+
+	var expr strings.Builder
+	expr.WriteString(`(?is)^`)
+
+	for i := 0; i < len(runes); i++ {
+
+		switch runes[i] {
+		case '*':
+			expr.WriteString(`.*`)
+		case '?':
+			expr.WriteString(`.`)
+		case '\\':
+			if i++; i == len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end, class, err := globClass(runes, i+1)
+			if err != nil {
+				return nil, err
+			}
+			expr.WriteString(class)
+			i = end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+
+	expr.WriteString(`$`)
+
+	return regexp.Compile(expr.String())
+}
+
+// Translates the character class starting at 'start' (just after '['). Returns the index of the closing ']', and the
+// regular expression class.
+func globClass(runes []rune, start int) (end int, class string, err error) {
+
+	var expr strings.Builder
+	expr.WriteString(`[`)
+
+	i := start
+
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		expr.WriteString(`^`)
+		i++
+	}
+
+	empty := true
+
+	for ; i < len(runes); i++ {
+
+		switch r := runes[i]; r {
+		case ']':
+			if empty {
+				return 0, "", fmt.Errorf("empty character class")
+			}
+			expr.WriteString(`]`)
+			return i, expr.String(), nil
+		case '\\':
+			if i++; i == len(runes) {
+				return 0, "", fmt.Errorf("trailing backslash")
+			}
+			if escaped := runes[i]; escaped == '-' {
+				expr.WriteString(`\-`)
+			} else {
+				expr.WriteString(regexp.QuoteMeta(string(escaped)))
+			}
+		case '-':
+			if empty || i+1 == len(runes) || runes[i+1] == ']' {
+				return 0, "", fmt.Errorf("incomplete range in character class")
+			}
+			expr.WriteString(`-`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+
+		empty = false
+	}
+
+	return 0, "", fmt.Errorf("missing closing ']'")
+}
+
+// Matches interfaces whose friendly name matches 're'.
+func MatchFriendlyNameRegexp(re *regexp.Regexp) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		return re.MatchString(ifc.FriendlyName)
+	}
+}
+
+// Matches interfaces whose description matches 're'.
+func MatchDescriptionRegexp(re *regexp.Regexp) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		return re.MatchString(ifc.Description)
+	}
+}
+
+// Matches interfaces with physical (MAC) address 'mac'.
+func MatchPhysicalAddress(mac net.HardwareAddr) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		return bytes.Equal(ifc.PhysicalAddress, mac)
+	}
+}
+
+// Matches interfaces with at least one gateway. Gateways are present only if interfaces are retrieved with
+// GAA_FLAG_INCLUDE_GATEWAYS.
+func MatchHasGateway() InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		return len(ifc.GatewayAddresses) > 0
+	}
+}
+
+// Matches interfaces with at least one unicast address of 'family'.
+func MatchHasAddress(family AddressFamily) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		for _, address := range ifc.UnicastAddresses {
+			if address.Address.Family == family {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Matches interfaces classified (see ClassifyInterface()) as any of 'classes'. Classification needs data which
+// Interface doesn't contain, so it's done by 'classify'.
+func MatchClasses(classify func(ifc *Interface) InterfaceClass, classes ...InterfaceClass) InterfaceMatcher {
+	return func(ifc *Interface) bool {
+		class := classify(ifc)
+		for _, c := range classes {
+			if class == c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Criteria of FindInterfaces(). Zero values don't restrict anything; non-zero criteria have to be all met.
+type InterfaceQuery struct {
+	// AF_INET or AF_INET6 to get only interfaces with that protocol enabled, containing only addresses of that
+	// family. Passed through to GetAdaptersAddresses.
+	Family AddressFamily
+	// Flags for GetAdaptersAddresses; DefaultGetAdapterAddressesFlags() if nil. Flags needed by the other criteria
+	// (e.g. GAA_FLAG_INCLUDE_GATEWAYS for HasGateway) are adjusted automatically.
+	Flags *GetAdapterAddressesFlags
+
+	IfTypes      []IfType
+	OperStatuses []IfOperStatus
+	// Glob patterns (see MatchFriendlyNameGlob(), case insensitive) and regular expressions.
+	FriendlyName       string
+	FriendlyNameRegexp *regexp.Regexp
+	Description        string
+	DescriptionRegexp  *regexp.Regexp
+	PhysicalAddress    net.HardwareAddr
+	HasGateway         bool
+	HasIPv4Address     bool
+	HasIPv6Address     bool
+	// Classes as returned by ClassifyInterface(), e.g. InterfaceClassPhysical or InterfaceClassTunnel. Requires
+	// querying IfRows as well.
+	Classes []InterfaceClass
+	// Additional custom criteria.
+	Matcher InterfaceMatcher
+}
+
+// Returns the matcher corresponding to the query (without Family, which is applied by GetAdaptersAddresses).
+// 'classify' is used only if Classes isn't empty.
+func (query *InterfaceQuery) matcher(classify func(ifc *Interface) InterfaceClass) (InterfaceMatcher, error) {
+
+	var matchers []InterfaceMatcher
+
+	if len(query.IfTypes) > 0 {
+		matchers = append(matchers, MatchIfTypes(query.IfTypes...))
+	}
+
+	if len(query.OperStatuses) > 0 {
+		matchers = append(matchers, MatchOperStatuses(query.OperStatuses...))
+	}
+
+	if query.FriendlyName != "" {
+		matcher, err := MatchFriendlyNameGlob(query.FriendlyName)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	if query.FriendlyNameRegexp != nil {
+		matchers = append(matchers, MatchFriendlyNameRegexp(query.FriendlyNameRegexp))
+	}
+
+	if query.Description != "" {
+		matcher, err := MatchDescriptionGlob(query.Description)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	if query.DescriptionRegexp != nil {
+		matchers = append(matchers, MatchDescriptionRegexp(query.DescriptionRegexp))
+	}
+
+	if query.PhysicalAddress != nil {
+		matchers = append(matchers, MatchPhysicalAddress(query.PhysicalAddress))
+	}
+
+	if query.HasGateway {
+		matchers = append(matchers, MatchHasGateway())
+	}
+
+	if query.HasIPv4Address {
+		matchers = append(matchers, MatchHasAddress(AF_INET))
+	}
+
+	if query.HasIPv6Address {
+		matchers = append(matchers, MatchHasAddress(AF_INET6))
+	}
+
+	if len(query.Classes) > 0 {
+		matchers = append(matchers, MatchClasses(classify, query.Classes...))
+	}
+
+	if query.Matcher != nil {
+		matchers = append(matchers, query.Matcher)
+	}
+
+	return MatchAll(matchers...), nil
+}
+
+// Returns interfaces matched by 'matcher'.
+func FilterInterfaces(ifcs []*Interface, matcher InterfaceMatcher) []*Interface {
+
+	var matched []*Interface
+
+	for _, ifc := range ifcs {
+		if matcher(ifc) {
+			matched = append(matched, ifc)
+		}
+	}
+
+	return matched
+}
+
+// Returns interfaces meeting all criteria of 'query' (nil matches all interfaces).
+func FindInterfaces(query *InterfaceQuery) ([]*Interface, error) {
+
+	if query == nil {
+		query = &InterfaceQuery{}
+	}
+
+	switch query.Family {
+	case AF_UNSPEC, AF_INET, AF_INET6:
+	default:
+		return nil, newKindError(ErrInvalidParameter, "FindInterfaces() - unsupported family %s", query.Family.String())
+	}
+
+	flags := DefaultGetAdapterAddressesFlags()
+
+	if query.Flags != nil {
+		copied := *query.Flags
+		flags = &copied
+	}
+
+	if query.HasGateway {
+		flags.GAA_FLAG_INCLUDE_GATEWAYS = true
+	}
+
+	if query.FriendlyName != "" || query.FriendlyNameRegexp != nil {
+		flags.GAA_FLAG_SKIP_FRIENDLY_NAME = false
+	}
+
+	if query.HasIPv4Address || query.HasIPv6Address {
+		flags.GAA_FLAG_SKIP_UNICAST = false
+	}
+
+	rows := make(map[uint64]*IfRow)
+
+	if len(query.Classes) > 0 {
+
+		ifrs, err := GetIfRows(MibIfEntryNormalWithoutStatistics)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ifr := range ifrs {
+			rows[ifr.InterfaceLuid] = ifr
+		}
+	}
+
+	matcher, err := query.matcher(func(ifc *Interface) InterfaceClass {
+		return ClassifyInterface(ifc.traits(rows[ifc.Luid]))
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("FindInterfaces() - %w", err)
+	}
+
+	ifcs, err := getInterfaces(query.Family, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterInterfaces(ifcs, matcher), nil
+}
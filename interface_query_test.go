@@ -0,0 +1,242 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const interfaceQuery_print = false
+
+func fakeAdapterUnicastAddress(ip string) *UnicastAddress {
+
+	address := &UnicastAddress{}
+	address.Address.Address = net.ParseIP(ip)
+
+	if address.Address.Address.To4() != nil {
+		address.Address.Family = AF_INET
+	} else {
+		address.Address.Family = AF_INET6
+	}
+
+	return address
+}
+
+func fakeQueryInterfaces() []*Interface {
+
+	return []*Interface{
+		{
+			Luid:            1,
+			FriendlyName:    "Ethernet",
+			Description:     "Intel(R) Ethernet Connection I219-V",
+			PhysicalAddress: net.HardwareAddr{0x00, 0x1b, 0x21, 0x01, 0x02, 0x03},
+			IfType:          IF_TYPE_ETHERNET_CSMACD,
+			OperStatus:      IfOperStatusUp,
+			UnicastAddresses: []*UnicastAddress{fakeAdapterUnicastAddress("192.168.1.10"),
+				fakeAdapterUnicastAddress("fe80::1")},
+			GatewayAddresses: []*IpAdapterAddressCommonType{{Address: SockaddrInet{Family: AF_INET,
+				Address: net.ParseIP("192.168.1.1")}}},
+		},
+		{
+			Luid:             2,
+			FriendlyName:     "Ethernet 2",
+			Description:      "TAP-Windows Adapter V9",
+			PhysicalAddress:  net.HardwareAddr{0x00, 0xff, 0x01, 0x02, 0x03, 0x04},
+			IfType:           IF_TYPE_ETHERNET_CSMACD,
+			OperStatus:       IfOperStatusDown,
+			UnicastAddresses: []*UnicastAddress{fakeAdapterUnicastAddress("fe80::2")},
+		},
+		{
+			Luid:             3,
+			FriendlyName:     "wg0",
+			Description:      "WireGuard Tunnel",
+			IfType:           IF_TYPE_PROP_VIRTUAL,
+			OperStatus:       IfOperStatusUp,
+			UnicastAddresses: []*UnicastAddress{fakeAdapterUnicastAddress("10.8.0.2")},
+		},
+		{
+			Luid:         4,
+			FriendlyName: "Loopback Pseudo-Interface 1",
+			IfType:       IF_TYPE_SOFTWARE_LOOPBACK,
+			OperStatus:   IfOperStatusUp,
+			UnicastAddresses: []*UnicastAddress{fakeAdapterUnicastAddress("127.0.0.1"),
+				fakeAdapterUnicastAddress("::1")},
+		},
+	}
+}
+
+// Classifies fake interfaces as FindInterfaces() does, with only the Ethernet adapter having an IfRow.
+func classifyFakeInterface(ifc *Interface) InterfaceClass {
+
+	rows := map[uint64]*IfRow{
+		1: {InterfaceLuid: 1, InterfaceAndOperStatusFlags: InterfaceAndOperStatusFlags{HardwareInterface: true}},
+	}
+
+	return ClassifyInterface(ifc.traits(rows[ifc.Luid]))
+}
+
+func TestInterfaceQueryMatcher(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		query InterfaceQuery
+		luids []uint64
+	}{
+		{"empty query", InterfaceQuery{}, []uint64{1, 2, 3, 4}},
+		{"IfTypes", InterfaceQuery{IfTypes: []IfType{IF_TYPE_PROP_VIRTUAL, IF_TYPE_SOFTWARE_LOOPBACK}},
+			[]uint64{3, 4}},
+		{"OperStatuses", InterfaceQuery{OperStatuses: []IfOperStatus{IfOperStatusDown}}, []uint64{2}},
+		{"friendly name glob", InterfaceQuery{FriendlyName: "ETHERNET*"}, []uint64{1, 2}},
+		{"friendly name regexp", InterfaceQuery{FriendlyNameRegexp: regexp.MustCompile(`^wg\d+$`)}, []uint64{3}},
+		{"description glob", InterfaceQuery{Description: "*tunnel"}, []uint64{3}},
+		{"description regexp", InterfaceQuery{DescriptionRegexp: regexp.MustCompile(`Intel`)}, []uint64{1}},
+		{"physical address", InterfaceQuery{PhysicalAddress: net.HardwareAddr{0x00, 0xff, 0x01, 0x02, 0x03, 0x04}},
+			[]uint64{2}},
+		{"has gateway", InterfaceQuery{HasGateway: true}, []uint64{1}},
+		{"has IPv4 address", InterfaceQuery{HasIPv4Address: true}, []uint64{1, 3, 4}},
+		{"has IPv6 address", InterfaceQuery{HasIPv6Address: true}, []uint64{1, 2, 4}},
+		{"tunnels", InterfaceQuery{Classes: []InterfaceClass{InterfaceClassTunnel}}, []uint64{2, 3}},
+		{"physical", InterfaceQuery{Classes: []InterfaceClass{InterfaceClassPhysical}}, []uint64{1}},
+		{"combined criteria", InterfaceQuery{FriendlyName: "Ethernet*", HasIPv6Address: true,
+			OperStatuses: []IfOperStatus{IfOperStatusUp}}, []uint64{1}},
+		{"custom matcher", InterfaceQuery{Matcher: MatchNot(MatchAny(MatchHasGateway(),
+			MatchIfTypes(IF_TYPE_SOFTWARE_LOOPBACK)))}, []uint64{2, 3}},
+	}
+
+	ifcs := fakeQueryInterfaces()
+
+	for _, test := range tests {
+
+		matcher, err := test.query.matcher(classifyFakeInterface)
+
+		if err != nil {
+			t.Errorf("%s: matcher() returned an error: %v", test.name, err)
+			continue
+		}
+
+		matched := FilterInterfaces(ifcs, matcher)
+
+		luids := make([]uint64, len(matched))
+
+		for i, ifc := range matched {
+			luids[i] = ifc.Luid
+		}
+
+		if fmt.Sprint(luids) != fmt.Sprint(test.luids) {
+			t.Errorf("%s: matched interfaces %v, although %v are expected.", test.name, luids, test.luids)
+		}
+	}
+}
+
+func TestInterfaceQueryInvalidGlob(t *testing.T) {
+
+	query := InterfaceQuery{Description: "[Intel"}
+
+	_, err := query.matcher(classifyFakeInterface)
+
+	if err == nil {
+		t.Error("matcher() didn't return an error for a malformed glob, although it's expected.")
+	} else if !strings.Contains(err.Error(), `"[Intel"`) || !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("matcher() returned %v, although an ErrInvalidParameter error with the original pattern is expected.",
+			err)
+	}
+}
+
+func TestGlobMatcher(t *testing.T) {
+
+	description := "Killer Wireless-n/a/ac 1535 Wireless Network Adapter"
+
+	tests := []struct {
+		pattern string
+		matched bool
+	}{
+		{"*wireless*", true},
+		{"KILLER WIRELESS-N/A/AC*", true},
+		{"*/ac ???? wireless*", true},
+		{"*[0-9] wireless network adapter", true},
+		{"*[!0-9] wireless network adapter", false},
+		{`Killer Wireless\-n/*`, true},
+		{`*[\-]n/a/ac*`, true},
+		{"wireless*", false},
+		{"*adapter?", false},
+		{"*(r)*", false},
+	}
+
+	for _, test := range tests {
+
+		matcher, err := MatchDescriptionGlob(test.pattern)
+
+		if err != nil {
+			t.Errorf("MatchDescriptionGlob(%q) returned an error: %v", test.pattern, err)
+			continue
+		}
+
+		if matched := matcher(&Interface{Description: description}); matched != test.matched {
+			t.Errorf("MatchDescriptionGlob(%q) matched %q: %v, although %v is expected.", test.pattern, description,
+				matched, test.matched)
+		}
+	}
+
+	for _, pattern := range []string{"[", "[]", "[a-]", "a\\", "[!]"} {
+		if _, err := MatchDescriptionGlob(pattern); err == nil {
+			t.Errorf("MatchDescriptionGlob(%q) didn't return an error, although it's expected.", pattern)
+		}
+	}
+}
+
+func TestMatchComposition(t *testing.T) {
+
+	ifc := fakeQueryInterfaces()[0]
+
+	if !MatchAll()(ifc) {
+		t.Error("MatchAll() without matchers didn't match, although it's expected.")
+	}
+
+	if MatchAny()(ifc) {
+		t.Error("MatchAny() without matchers matched, although it isn't expected.")
+	}
+
+	up := MatchOperStatuses(IfOperStatusUp)
+	tunnel := MatchIfTypes(IF_TYPE_TUNNEL)
+
+	if MatchAll(up, tunnel)(ifc) || !MatchAny(up, tunnel)(ifc) || !MatchAll(up, MatchNot(tunnel))(ifc) {
+		t.Error("Composed matchers returned unexpected results.")
+	}
+}
+
+func TestFindInterfaces(t *testing.T) {
+
+	ifcs, err := FindInterfaces(&InterfaceQuery{Family: AF_INET, HasIPv4Address: true,
+		OperStatuses: []IfOperStatus{IfOperStatusUp}})
+
+	if err != nil {
+		t.Errorf("FindInterfaces() returned an error: %v", err)
+		return
+	}
+
+	for _, ifc := range ifcs {
+
+		for _, address := range ifc.UnicastAddresses {
+			if address.Address.Family != AF_INET {
+				t.Errorf("Interface %d has %s address %s, although only AF_INET addresses are expected.",
+					ifc.Luid, address.Address.Family.String(), address.Address.Address.String())
+			}
+		}
+
+		if interfaceQuery_print {
+			fmt.Println(ifc)
+		}
+	}
+
+	if _, err := FindInterfaces(&InterfaceQuery{Family: AddressFamily(1234)}); err == nil {
+		t.Error("FindInterfaces() with an invalid family didn't return an error, although it's expected.")
+	}
+}
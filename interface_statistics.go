@@ -0,0 +1,352 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Enumerates IfRow statistics counters tracked by InterfaceStatisticsSampler.
+type InterfaceCounter int
+
+const (
+	InterfaceCounterInOctets InterfaceCounter = iota
+	InterfaceCounterInUcastPkts
+	InterfaceCounterInNUcastPkts
+	InterfaceCounterInDiscards
+	InterfaceCounterInErrors
+	InterfaceCounterInUnknownProtos
+	InterfaceCounterInUcastOctets
+	InterfaceCounterInMulticastOctets
+	InterfaceCounterInBroadcastOctets
+	InterfaceCounterOutOctets
+	InterfaceCounterOutUcastPkts
+	InterfaceCounterOutNUcastPkts
+	InterfaceCounterOutDiscards
+	InterfaceCounterOutErrors
+	InterfaceCounterOutUcastOctets
+	InterfaceCounterOutMulticastOctets
+	InterfaceCounterOutBroadcastOctets
+
+	InterfaceCounterCount = iota
+)
+
+var interfaceCounterNames = [InterfaceCounterCount]string{
+	InterfaceCounterInOctets:           "in_octets",
+	InterfaceCounterInUcastPkts:        "in_ucast_pkts",
+	InterfaceCounterInNUcastPkts:       "in_nucast_pkts",
+	InterfaceCounterInDiscards:         "in_discards",
+	InterfaceCounterInErrors:           "in_errors",
+	InterfaceCounterInUnknownProtos:    "in_unknown_protos",
+	InterfaceCounterInUcastOctets:      "in_ucast_octets",
+	InterfaceCounterInMulticastOctets:  "in_multicast_octets",
+	InterfaceCounterInBroadcastOctets:  "in_broadcast_octets",
+	InterfaceCounterOutOctets:          "out_octets",
+	InterfaceCounterOutUcastPkts:       "out_ucast_pkts",
+	InterfaceCounterOutNUcastPkts:      "out_nucast_pkts",
+	InterfaceCounterOutDiscards:        "out_discards",
+	InterfaceCounterOutErrors:          "out_errors",
+	InterfaceCounterOutUcastOctets:     "out_ucast_octets",
+	InterfaceCounterOutMulticastOctets: "out_multicast_octets",
+	InterfaceCounterOutBroadcastOctets: "out_broadcast_octets",
+}
+
+// Returns snake_case name of the counter, as used by WritePrometheusText().
+func (c InterfaceCounter) String() string {
+	if c < 0 || c >= InterfaceCounterCount {
+		return fmt.Sprintf("InterfaceCounter_UNKNOWN(%d)", int(c))
+	}
+	return interfaceCounterNames[c]
+}
+
+type interfaceCounters [InterfaceCounterCount]uint64
+
+func ifRowCounters(row *IfRow) interfaceCounters {
+	return interfaceCounters{
+		InterfaceCounterInOctets:           row.InOctets,
+		InterfaceCounterInUcastPkts:        row.InUcastPkts,
+		InterfaceCounterInNUcastPkts:       row.InNUcastPkts,
+		InterfaceCounterInDiscards:         row.InDiscards,
+		InterfaceCounterInErrors:           row.InErrors,
+		InterfaceCounterInUnknownProtos:    row.InUnknownProtos,
+		InterfaceCounterInUcastOctets:      row.InUcastOctets,
+		InterfaceCounterInMulticastOctets:  row.InMulticastOctets,
+		InterfaceCounterInBroadcastOctets:  row.InBroadcastOctets,
+		InterfaceCounterOutOctets:          row.OutOctets,
+		InterfaceCounterOutUcastPkts:       row.OutUcastPkts,
+		InterfaceCounterOutNUcastPkts:      row.OutNUcastPkts,
+		InterfaceCounterOutDiscards:        row.OutDiscards,
+		InterfaceCounterOutErrors:          row.OutErrors,
+		InterfaceCounterOutUcastOctets:     row.OutUcastOctets,
+		InterfaceCounterOutMulticastOctets: row.OutMulticastOctets,
+		InterfaceCounterOutBroadcastOctets: row.OutBroadcastOctets,
+	}
+}
+
+// Statistics of a single interface, computed from two consecutive samples.
+type InterfaceStatistics struct {
+	InterfaceLuid  uint64
+	InterfaceIndex uint32
+	Alias          string
+
+	// Time of the latest sample.
+	Time time.Time
+	// Time elapsed since the previous sample. Zero if this is the first sample of the interface.
+	Interval time.Duration
+
+	// Counter values of the latest sample.
+	Totals [InterfaceCounterCount]uint64
+	// Counter increments since the previous sample. If a counter went backwards (i.e. the interface was reset), its
+	// delta is the new counter value.
+	Deltas [InterfaceCounterCount]uint64
+	// Deltas divided by Interval, per second. All zeros if Interval is zero.
+	Rates [InterfaceCounterCount]float64
+
+	// Number of counter resets detected since the interface was first sampled.
+	Resets uint64
+}
+
+func (is *InterfaceStatistics) String() string {
+
+	if is == nil {
+		return "<nil>"
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, `InterfaceLuid: %d
+InterfaceIndex: %d
+Alias: %s
+Time: %s
+Interval: %s
+Resets: %d`, is.InterfaceLuid, is.InterfaceIndex, is.Alias, is.Time.Format(time.RFC3339Nano), is.Interval, is.Resets)
+
+	for c := InterfaceCounter(0); c < InterfaceCounterCount; c++ {
+		fmt.Fprintf(&sb, "\n%s: %d (+%d, %.2f/s)", c.String(), is.Totals[c], is.Deltas[c], is.Rates[c])
+	}
+
+	return sb.String()
+}
+
+// Read-only view of sampled interface statistics.
+type InterfaceMetrics interface {
+	// Returns statistics of all interfaces present in the latest sample, sorted by InterfaceLuid.
+	Statistics() []*InterfaceStatistics
+	// Returns statistics of the interface with specified LUID, or nil if it wasn't present in the latest sample.
+	StatisticsFor(interfaceLuid uint64) *InterfaceStatistics
+}
+
+// Periodically polls GetIfRows and computes per-interface deltas and rates. Interfaces which disappear from the
+// interface table are dropped from the metrics on the next sample.
+type InterfaceStatisticsSampler struct {
+	getRows func() ([]*IfRow, error)
+	now     func() time.Time
+
+	mutex sync.Mutex
+	stats map[uint64]*InterfaceStatistics
+
+	runner periodicRunner
+}
+
+// Creates a new sampler. Sampling starts only after Start() or Sample() is called.
+func NewInterfaceStatisticsSampler() *InterfaceStatisticsSampler {
+	return &InterfaceStatisticsSampler{
+		getRows: func() ([]*IfRow, error) { return GetIfRows(MibIfEntryNormal) },
+		now:     time.Now,
+		stats:   make(map[uint64]*InterfaceStatistics),
+	}
+}
+
+// Takes a sample immediately and updates the metrics.
+func (s *InterfaceStatisticsSampler) Sample() error {
+
+	rows, err := s.getRows()
+
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := make(map[uint64]*InterfaceStatistics, len(rows))
+
+	for _, row := range rows {
+		if row != nil {
+			stats[row.InterfaceLuid] = computeInterfaceStatistics(s.stats[row.InterfaceLuid], row, now)
+		}
+	}
+
+	s.stats = stats
+
+	return nil
+}
+
+func computeInterfaceStatistics(previous *InterfaceStatistics, row *IfRow, now time.Time) *InterfaceStatistics {
+
+	current := &InterfaceStatistics{
+		InterfaceLuid:  row.InterfaceLuid,
+		InterfaceIndex: row.InterfaceIndex,
+		Alias:          row.Alias,
+		Time:           now,
+		Totals:         ifRowCounters(row),
+	}
+
+	if previous == nil {
+		return current
+	}
+
+	current.Resets = previous.Resets
+	current.Interval = now.Sub(previous.Time)
+
+	reset := false
+
+	for c := range current.Totals {
+		if current.Totals[c] >= previous.Totals[c] {
+			current.Deltas[c] = current.Totals[c] - previous.Totals[c]
+		} else {
+			current.Deltas[c] = current.Totals[c]
+			reset = true
+		}
+	}
+
+	if reset {
+		current.Resets++
+	}
+
+	if current.Interval > 0 {
+		seconds := current.Interval.Seconds()
+		for c := range current.Deltas {
+			current.Rates[c] = float64(current.Deltas[c]) / seconds
+		}
+	}
+
+	return current
+}
+
+// Starts sampling every 'interval' in a background goroutine. The first sample is taken immediately. Sampling errors
+// are passed to 'onError' if it isn't nil; the sampler keeps running regardless. Returns an error if 'interval' isn't
+// positive; calling it while sampling is already running does nothing.
+func (s *InterfaceStatisticsSampler) Start(interval time.Duration, onError func(error)) error {
+
+	if interval <= 0 {
+		return newKindError(ErrInvalidParameter, "InterfaceStatisticsSampler.Start() - interval %s has to be positive",
+			interval)
+	}
+
+	s.runner.start(interval, s.Sample, onError)
+
+	return nil
+}
+
+// Stops background sampling started by Start() and waits for it to finish. Collected metrics are retained.
+func (s *InterfaceStatisticsSampler) Stop() {
+	s.runner.stop()
+}
+
+func (s *InterfaceStatisticsSampler) Statistics() []*InterfaceStatistics {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]*InterfaceStatistics, 0, len(s.stats))
+
+	for _, is := range s.stats {
+		copied := *is
+		result = append(result, &copied)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].InterfaceLuid < result[j].InterfaceLuid })
+
+	return result
+}
+
+func (s *InterfaceStatisticsSampler) StatisticsFor(interfaceLuid uint64) *InterfaceStatistics {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	is, ok := s.stats[interfaceLuid]
+
+	if !ok {
+		return nil
+	}
+
+	copied := *is
+
+	return &copied
+}
+
+// Writes metrics in Prometheus text exposition format. For every counter two metric families are written:
+// winipcfg_interface_<counter>_total (counter) and winipcfg_interface_<counter>_per_second (gauge). Additionally,
+// winipcfg_interface_counter_resets_total is written.
+func WritePrometheusText(w io.Writer, metrics InterfaceMetrics) error {
+
+	stats := metrics.Statistics()
+
+	ew := &errWriter{w: w}
+
+	for c := InterfaceCounter(0); c < InterfaceCounterCount; c++ {
+
+		name := "winipcfg_interface_" + c.String()
+
+		fmt.Fprintf(ew, "# HELP %s_total IfRow counter %s.\n# TYPE %s_total counter\n", name, c.String(), name)
+
+		for _, is := range stats {
+			fmt.Fprintf(ew, "%s_total{%s} %d\n", name, prometheusLabels(is), is.Totals[c])
+		}
+
+		fmt.Fprintf(ew, "# HELP %s_per_second Rate of IfRow counter %s.\n# TYPE %s_per_second gauge\n", name,
+			c.String(), name)
+
+		for _, is := range stats {
+			fmt.Fprintf(ew, "%s_per_second{%s} %s\n", name, prometheusLabels(is),
+				strconv.FormatFloat(is.Rates[c], 'g', -1, 64))
+		}
+	}
+
+	fmt.Fprint(ew, "# HELP winipcfg_interface_counter_resets_total Detected IfRow counter resets.\n"+
+		"# TYPE winipcfg_interface_counter_resets_total counter\n")
+
+	for _, is := range stats {
+		fmt.Fprintf(ew, "winipcfg_interface_counter_resets_total{%s} %d\n", prometheusLabels(is), is.Resets)
+	}
+
+	return ew.err
+}
+
+var prometheusLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func prometheusLabels(is *InterfaceStatistics) string {
+	return fmt.Sprintf(`luid="%d",index="%d",alias="%s"`, is.InterfaceLuid, is.InterfaceIndex,
+		prometheusLabelValueReplacer.Replace(is.Alias))
+}
+
+// Remembers the first write error, and skips all writes after it.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+
+	if ew.err != nil {
+		return 0, ew.err
+	}
+
+	n, err := ew.w.Write(p)
+	ew.err = err
+
+	return n, err
+}
@@ -0,0 +1,186 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeIfRowSource struct {
+	rows []*IfRow
+	now  time.Time
+}
+
+func (f *fakeIfRowSource) sampler() *InterfaceStatisticsSampler {
+	s := NewInterfaceStatisticsSampler()
+	s.getRows = func() ([]*IfRow, error) { return f.rows, nil }
+	s.now = func() time.Time { return f.now }
+	return s
+}
+
+func TestInterfaceStatisticsSamplerRates(t *testing.T) {
+
+	f := &fakeIfRowSource{
+		rows: []*IfRow{{InterfaceLuid: 1, Alias: "tun", InOctets: 1000, OutOctets: 500}},
+		now:  time.Unix(1000, 0),
+	}
+	s := f.sampler()
+
+	if err := s.Sample(); err != nil {
+		t.Fatalf("Sample() returned an error: %v", err)
+	}
+
+	first := s.StatisticsFor(1)
+
+	if first == nil || first.Interval != 0 || first.Rates[InterfaceCounterInOctets] != 0 || first.Totals[InterfaceCounterInOctets] != 1000 {
+		t.Fatalf("Unexpected first sample: %v", first)
+	}
+
+	f.rows = []*IfRow{{InterfaceLuid: 1, Alias: "tun", InOctets: 3000, OutOctets: 1500}}
+	f.now = f.now.Add(2 * time.Second)
+
+	if err := s.Sample(); err != nil {
+		t.Fatalf("Sample() returned an error: %v", err)
+	}
+
+	second := s.StatisticsFor(1)
+
+	if second.Interval != 2*time.Second {
+		t.Errorf("Interval is %s, although 2s is expected.", second.Interval)
+	}
+
+	if second.Deltas[InterfaceCounterInOctets] != 2000 || second.Rates[InterfaceCounterInOctets] != 1000 {
+		t.Errorf("InOctets delta/rate is %d/%f, although 2000/1000 is expected.", second.Deltas[InterfaceCounterInOctets],
+			second.Rates[InterfaceCounterInOctets])
+	}
+
+	if second.Deltas[InterfaceCounterOutOctets] != 1000 || second.Rates[InterfaceCounterOutOctets] != 500 {
+		t.Errorf("OutOctets delta/rate is %d/%f, although 1000/500 is expected.", second.Deltas[InterfaceCounterOutOctets],
+			second.Rates[InterfaceCounterOutOctets])
+	}
+}
+
+func TestInterfaceStatisticsSamplerCounterReset(t *testing.T) {
+
+	f := &fakeIfRowSource{
+		rows: []*IfRow{{InterfaceLuid: 1, InOctets: 5000, InErrors: 10}},
+		now:  time.Unix(1000, 0),
+	}
+	s := f.sampler()
+	_ = s.Sample()
+
+	f.rows = []*IfRow{{InterfaceLuid: 1, InOctets: 100, InErrors: 12}}
+	f.now = f.now.Add(time.Second)
+	_ = s.Sample()
+
+	is := s.StatisticsFor(1)
+
+	if is.Deltas[InterfaceCounterInOctets] != 100 {
+		t.Errorf("InOctets delta after reset is %d, although 100 is expected.", is.Deltas[InterfaceCounterInOctets])
+	}
+
+	if is.Deltas[InterfaceCounterInErrors] != 2 {
+		t.Errorf("InErrors delta is %d, although 2 is expected.", is.Deltas[InterfaceCounterInErrors])
+	}
+
+	if is.Resets != 1 {
+		t.Errorf("Resets is %d, although 1 is expected.", is.Resets)
+	}
+}
+
+func TestInterfaceStatisticsSamplerInterfaceRemoval(t *testing.T) {
+
+	f := &fakeIfRowSource{
+		rows: []*IfRow{{InterfaceLuid: 1}, {InterfaceLuid: 2}},
+		now:  time.Unix(1000, 0),
+	}
+	s := f.sampler()
+	_ = s.Sample()
+
+	if len(s.Statistics()) != 2 {
+		t.Fatalf("Statistics() returned %d items, although 2 are expected.", len(s.Statistics()))
+	}
+
+	f.rows = []*IfRow{{InterfaceLuid: 2}}
+	f.now = f.now.Add(time.Second)
+	_ = s.Sample()
+
+	if s.StatisticsFor(1) != nil {
+		t.Error("Removed interface is still present in statistics.")
+	}
+
+	// Re-appearing interface starts from scratch.
+	f.rows = []*IfRow{{InterfaceLuid: 1, InOctets: 42}, {InterfaceLuid: 2}}
+	f.now = f.now.Add(time.Second)
+	_ = s.Sample()
+
+	if is := s.StatisticsFor(1); is == nil || is.Interval != 0 || is.Deltas[InterfaceCounterInOctets] != 0 {
+		t.Errorf("Re-appeared interface has unexpected statistics: %v", is)
+	}
+}
+
+func TestWritePrometheusText(t *testing.T) {
+
+	f := &fakeIfRowSource{
+		rows: []*IfRow{{InterfaceLuid: 7, InterfaceIndex: 3, Alias: `my "tun"`, InOctets: 10}},
+		now:  time.Unix(1000, 0),
+	}
+	s := f.sampler()
+	_ = s.Sample()
+
+	f.rows[0] = &IfRow{InterfaceLuid: 7, InterfaceIndex: 3, Alias: `my "tun"`, InOctets: 30}
+	f.now = f.now.Add(4 * time.Second)
+	_ = s.Sample()
+
+	var buf bytes.Buffer
+
+	if err := WritePrometheusText(&buf, s); err != nil {
+		t.Fatalf("WritePrometheusText() returned an error: %v", err)
+	}
+
+	out := buf.String()
+
+	for _, expected := range []string{
+		"# TYPE winipcfg_interface_in_octets_total counter\n",
+		`winipcfg_interface_in_octets_total{luid="7",index="3",alias="my \"tun\""} 30` + "\n",
+		"# TYPE winipcfg_interface_in_octets_per_second gauge\n",
+		`winipcfg_interface_in_octets_per_second{luid="7",index="3",alias="my \"tun\""} 5` + "\n",
+		`winipcfg_interface_counter_resets_total{luid="7",index="3",alias="my \"tun\""} 0` + "\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("WritePrometheusText() output doesn't contain %q", expected)
+		}
+	}
+}
+
+func TestInterfaceStatisticsSamplerStartStop(t *testing.T) {
+
+	s := NewInterfaceStatisticsSampler()
+
+	if err := s.Start(0, nil); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Start() with zero interval returned %v, although ErrInvalidParameter is expected.", err)
+	}
+
+	err := s.Start(100*time.Millisecond, func(err error) {
+		t.Errorf("Sampling returned an error: %v", err)
+	})
+
+	if err != nil {
+		t.Fatalf("Start() returned an error: %v", err)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+
+	s.Stop()
+
+	if len(s.Statistics()) < 1 {
+		t.Error("Statistics() returned no interfaces.")
+	}
+}
@@ -6,6 +6,7 @@
 package winipcfg
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -101,6 +102,8 @@ func TestInterfaceFromLUIDNonExisting(t *testing.T) {
 			ifc.Luid, unexistingLuid)
 	} else if err.Error() != "InterfaceFromIndexEx() - interface with specified LUID not found" {
 		t.Errorf("InterfaceFromLUID() returned error: %v", err)
+	} else if !errors.Is(err, ErrInterfaceGone) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("InterfaceFromLUID() returned error %v, which isn't ErrInterfaceGone and ErrNotFound.", err)
 	}
 }
 
@@ -0,0 +1,64 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Serializes calls of a callback, and lets code stopping the callback wait for the running call, unless it's called
+// from the callback itself (which would deadlock). The callback runs with its goroutine locked to the OS thread, so no
+// other goroutine can run on that thread meanwhile, and comparing thread IDs tells whether the caller is the callback.
+type CallbackGuard struct {
+	// ID of the OS thread running the callback, or 0 if it isn't running; accessed atomically. Kept first for 64-bit
+	// alignment on 386.
+	thread uint64
+
+	mutex sync.Mutex
+}
+
+// Locks the guard. Calls of Call() have to be made with the guard locked; Wait() waits until it's unlocked.
+func (g *CallbackGuard) Lock() {
+	g.mutex.Lock()
+}
+
+func (g *CallbackGuard) Unlock() {
+	g.mutex.Unlock()
+}
+
+// Calls 'callback', marking the calling thread as running it. It has to be called with the guard locked.
+func (g *CallbackGuard) Call(callback func()) {
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	atomic.StoreUint64(&g.thread, currentThreadId())
+	defer atomic.StoreUint64(&g.thread, 0)
+
+	callback()
+}
+
+// Returns whether it's called from the callback passed to the running Call().
+func (g *CallbackGuard) InCallback() bool {
+
+	thread := atomic.LoadUint64(&g.thread)
+
+	return thread != 0 && thread == currentThreadId()
+}
+
+// Waits until the guard is unlocked, i.e. until the running call (if any) completes. If it's called from the callback
+// itself, it returns immediately instead.
+func (g *CallbackGuard) Wait() {
+
+	if g.InCallback() {
+		return
+	}
+
+	g.mutex.Lock()
+	g.mutex.Unlock()
+}
@@ -0,0 +1,286 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+// Package notification fans change notifications out to subscribers, and records and replays them. It doesn't depend
+// on anything Windows specific: OS registrations are made through functions supplied by the caller, and rows are opaque
+// values, so the dispatching, recording and replaying logic is tested on any OS.
+package notification
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Number of notifications which can be queued for a single subscriber. If a subscriber's callback doesn't keep up,
+// further notifications for it are dropped (and counted) instead of stalling the OS notification thread.
+const QueueLength = 256
+
+// Subscriber of a Dispatcher, as returned by Dispatcher.Subscribe().
+type Subscriber struct {
+	// Accessed atomically; kept first for 64-bit alignment on 386.
+	dropped uint64
+	// Non-zero once unsubscribed; accessed atomically.
+	stopped int32
+	// Delivered on the goroutine calling Dispatch(), as subscribers of ordered dispatchers are.
+	synchronous bool
+
+	// Families the subscriber needs OS registrations for.
+	families []uint16
+	// Called by Dispatch() to decide whether the event is delivered; nil accepts everything.
+	accept  func(event interface{}) bool
+	deliver func(event interface{})
+
+	// Locked while the callback runs.
+	guard CallbackGuard
+
+	// Only for asynchronous subscribers of dispatchers made by NewDispatcher(), which have their own goroutines.
+	queue chan interface{}
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// Calls the callback, unless the subscriber is stopped.
+func (s *Subscriber) deliverEvent(event interface{}) {
+
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if atomic.LoadInt32(&s.stopped) != 0 {
+		return
+	}
+
+	s.guard.Call(func() { s.deliver(event) })
+}
+
+func (s *Subscriber) run() {
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case event := <-s.queue:
+			s.deliverEvent(event)
+		}
+	}
+}
+
+func (s *Subscriber) enqueue(event interface{}) {
+
+	select {
+	case s.queue <- event:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// Stops delivering, and waits until the running delivery (if any) completes, unless it's called from the callback.
+func (s *Subscriber) close() {
+
+	atomic.StoreInt32(&s.stopped, 1)
+
+	if s.stop != nil {
+		s.once.Do(func() { close(s.stop) })
+	}
+
+	s.guard.Wait()
+}
+
+// Returns the number of notifications dropped because the subscriber's queue was full.
+func (s *Subscriber) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Fans notifications out to subscribers. The OS registration for a family is made when the first subscriber
+// interested in it is added, and canceled when the last one is removed. Callbacks are free to subscribe or unsubscribe
+// (including themselves).
+type Dispatcher struct {
+	// True for dispatchers made by NewOrderedDispatcher().
+	ordered bool
+
+	// Serializes Subscribe/Unsubscribe, and therefore register/cancel calls. Never taken by Dispatch(), since
+	// CancelMibChangeNotify2 waits for running OS callbacks to return.
+	registrationMutex sync.Mutex
+	// Number of subscribers per family.
+	registrations map[uint16]int
+	register      func(family uint16) error
+	cancel        func(family uint16) error
+	// Turns more than one error into a single one.
+	join func(errs []error) error
+
+	// Protects subscribers; held only briefly.
+	mutex       sync.Mutex
+	subscribers map[*Subscriber]bool
+}
+
+// Returns a dispatcher for OS notifications. Each subscriber gets its own goroutine and bounded queue, so callbacks
+// never run on the OS notification thread, and a slow callback doesn't delay others.
+func NewDispatcher(register, cancel func(family uint16) error, join func(errs []error) error) *Dispatcher {
+	return &Dispatcher{
+		registrations: make(map[uint16]int),
+		register:      register,
+		cancel:        cancel,
+		join:          join,
+		subscribers:   make(map[*Subscriber]bool),
+	}
+}
+
+// Returns a dispatcher without OS registrations, which delivers events synchronously on the goroutine calling
+// Dispatch(). If a single goroutine dispatches events to several ordered dispatchers, all their subscribers receive
+// the events in the same order, and nothing is ever dropped.
+func NewOrderedDispatcher() *Dispatcher {
+
+	none := func(family uint16) error { return nil }
+
+	d := NewDispatcher(none, none, nil)
+	d.ordered = true
+
+	return d
+}
+
+// Adds a subscriber interested in 'families'. Events for which 'accept' (if not nil) returns false aren't delivered to
+// it; 'accept' is called by Dispatch() (i.e. on the OS notification thread), so it has to be fast and must not block.
+func (d *Dispatcher) Subscribe(families []uint16, accept func(event interface{}) bool,
+	deliver func(event interface{})) (*Subscriber, error) {
+	return d.subscribe(families, accept, deliver, d.ordered)
+}
+
+// The same as Subscribe(), except that events are delivered on the goroutine calling Dispatch() (i.e. on the OS
+// notification thread), before it returns. 'deliver' has to be fast and must not block, but it receives events in the
+// order they're dispatched, even across dispatchers.
+func (d *Dispatcher) SubscribeSynchronous(families []uint16, accept func(event interface{}) bool,
+	deliver func(event interface{})) (*Subscriber, error) {
+	return d.subscribe(families, accept, deliver, true)
+}
+
+func (d *Dispatcher) subscribe(families []uint16, accept func(event interface{}) bool, deliver func(event interface{}),
+	synchronous bool) (*Subscriber, error) {
+
+	s := &Subscriber{
+		families:    families,
+		accept:      accept,
+		deliver:     deliver,
+		synchronous: synchronous,
+	}
+
+	d.registrationMutex.Lock()
+	defer d.registrationMutex.Unlock()
+
+	for i, family := range families {
+
+		if d.registrations[family] == 0 {
+			if err := d.register(family); err != nil {
+				_ = d.release(families[:i])
+				return nil, err
+			}
+		}
+
+		d.registrations[family]++
+	}
+
+	if !synchronous {
+		s.queue = make(chan interface{}, QueueLength)
+		s.stop = make(chan struct{})
+		go s.run()
+	}
+
+	d.mutex.Lock()
+	d.subscribers[s] = true
+	d.mutex.Unlock()
+
+	return s, nil
+}
+
+// Removes the subscriber, and waits until its running delivery (if any) completes, so no notification is delivered
+// after it returns. When it's called from the subscriber's own callback, it returns without waiting for the callback
+// (which is then the last delivery). Calling it more than once is harmless.
+func (d *Dispatcher) Unsubscribe(s *Subscriber) error {
+
+	if s == nil {
+		return nil
+	}
+
+	d.registrationMutex.Lock()
+
+	d.mutex.Lock()
+	_, subscribed := d.subscribers[s]
+	delete(d.subscribers, s)
+	d.mutex.Unlock()
+
+	var err error
+
+	if subscribed {
+		err = d.release(s.families)
+	}
+
+	d.registrationMutex.Unlock()
+
+	s.close()
+
+	return err
+}
+
+// Decrements subscriber counts of 'families', canceling OS registrations which are no longer needed. Has to be called
+// with registrationMutex held.
+func (d *Dispatcher) release(families []uint16) error {
+
+	var errs []error
+
+	for _, family := range families {
+
+		d.registrations[family]--
+
+		if d.registrations[family] > 0 {
+			continue
+		}
+
+		delete(d.registrations, family)
+
+		if err := d.cancel(family); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return d.join(errs)
+	}
+}
+
+// Delivers the event to all current subscribers which accept it. Except for synchronous subscribers (including all
+// subscribers of ordered dispatchers), it only queues the event and never blocks on subscribers, so it's safe to call
+// from the OS notification thread.
+func (d *Dispatcher) Dispatch(event interface{}) {
+
+	for _, s := range d.currentSubscribers() {
+
+		if s.accept != nil && !s.accept(event) {
+			continue
+		}
+
+		if s.synchronous {
+			s.deliverEvent(event)
+		} else {
+			s.enqueue(event)
+		}
+	}
+}
+
+func (d *Dispatcher) currentSubscribers() []*Subscriber {
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	subscribers := make([]*Subscriber, 0, len(d.subscribers))
+
+	for s := range d.subscribers {
+		subscribers = append(subscribers, s)
+	}
+
+	return subscribers
+}
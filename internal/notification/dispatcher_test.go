@@ -0,0 +1,527 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Values of AF_INET and AF_INET6.
+const (
+	familyIPv4 = 2
+	familyIPv6 = 23
+)
+
+var ipv4Only = []uint16{familyIPv4}
+
+// Simulates an OS notification source: counts registrations and pushes events from its own goroutine.
+type fakeNotificationSource struct {
+	registrations int32
+	cancellations int32
+	failRegister  bool
+	dispatcher    *Dispatcher
+}
+
+func newFakeNotificationSource() *fakeNotificationSource {
+
+	f := &fakeNotificationSource{}
+
+	f.dispatcher = NewDispatcher(func(family uint16) error {
+		if f.failRegister {
+			return errors.New("registration failed")
+		}
+		atomic.AddInt32(&f.registrations, 1)
+		return nil
+	}, func(family uint16) error {
+		atomic.AddInt32(&f.cancellations, 1)
+		return nil
+	}, joinErrors)
+
+	return f
+}
+
+// Emits events 0..count-1 from a separate goroutine, as the OS would, and waits until they're dispatched.
+func (f *fakeNotificationSource) emit(count int) {
+
+	done := make(chan struct{})
+
+	go func() {
+		for i := 0; i < count; i++ {
+			f.dispatcher.Dispatch(i)
+		}
+		close(done)
+	}()
+
+	<-done
+}
+
+func joinErrors(errs []error) error {
+	return fmt.Errorf("%d errors", len(errs))
+}
+
+// Waits until the subscriber's running delivery (if any) completes.
+func waitForDelivery(s *Subscriber) {
+	s.guard.Wait()
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s.", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNotificationDispatcherOrderAndRegistration(t *testing.T) {
+
+	f := newFakeNotificationSource()
+
+	var mutex sync.Mutex
+	var received [2][]int
+
+	subscribers := make([]*Subscriber, 2)
+
+	for i := range subscribers {
+
+		idx := i
+		s, err := f.dispatcher.Subscribe(ipv4Only, nil, func(event interface{}) {
+			mutex.Lock()
+			received[idx] = append(received[idx], event.(int))
+			mutex.Unlock()
+		})
+
+		if err != nil {
+			t.Fatalf("Subscribe() returned an error: %v", err)
+		}
+
+		subscribers[i] = s
+	}
+
+	if f.registrations != 1 {
+		t.Errorf("Source was registered %d times, although 1 is expected.", f.registrations)
+	}
+
+	f.emit(100)
+
+	waitFor(t, "deliveries", func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received[0]) == 100 && len(received[1]) == 100
+	})
+
+	for idx := range received {
+		for i, event := range received[idx] {
+			if event != i {
+				t.Fatalf("Subscriber %d received event %d at position %d.", idx, event, i)
+			}
+		}
+	}
+
+	for _, s := range subscribers {
+		if err := f.dispatcher.Unsubscribe(s); err != nil {
+			t.Errorf("Unsubscribe() returned an error: %v", err)
+		}
+	}
+
+	if atomic.LoadInt32(&f.cancellations) != 1 {
+		t.Errorf("Source was canceled %d times, although 1 is expected.", f.cancellations)
+	}
+
+	f.failRegister = true
+
+	if _, err := f.dispatcher.Subscribe(ipv4Only, nil, func(interface{}) {}); err == nil {
+		t.Error("Subscribe() didn't return an error when registration failed, although it's expected.")
+	}
+}
+
+func TestNotificationDispatcherSlowSubscriber(t *testing.T) {
+
+	f := newFakeNotificationSource()
+
+	release := make(chan struct{})
+	slow, _ := f.dispatcher.Subscribe(ipv4Only, nil, func(interface{}) { <-release })
+
+	fast := int32(0)
+	fastSubscriber, _ := f.dispatcher.Subscribe(ipv4Only, nil, func(interface{}) { atomic.AddInt32(&fast, 1) })
+
+	// emit() returns only if dispatching never blocks on the stuck subscriber. Events are emitted in two batches, so
+	// the fast subscriber's queue never overflows.
+	f.emit(QueueLength)
+	waitFor(t, "fast subscriber", func() bool { return atomic.LoadInt32(&fast) == QueueLength })
+
+	f.emit(10)
+	waitFor(t, "fast subscriber", func() bool { return atomic.LoadInt32(&fast) == QueueLength+10 })
+
+	if slow.Dropped() == 0 {
+		t.Error("Slow subscriber has no dropped notifications, although some are expected.")
+	}
+
+	if fastSubscriber.Dropped() != 0 {
+		t.Errorf("Fast subscriber dropped %d notifications, although none are expected.", fastSubscriber.Dropped())
+	}
+
+	close(release)
+
+	_ = f.dispatcher.Unsubscribe(slow)
+	_ = f.dispatcher.Unsubscribe(fastSubscriber)
+}
+
+func TestNotificationDispatcherReentrancy(t *testing.T) {
+
+	f := newFakeNotificationSource()
+
+	var self *Subscriber
+	var selfMutex sync.Mutex
+	selfCalls := int32(0)
+	registered := make(chan *Subscriber, 1)
+
+	s, err := f.dispatcher.Subscribe(ipv4Only, nil, func(event interface{}) {
+
+		if atomic.AddInt32(&selfCalls, 1) != 1 {
+			return
+		}
+
+		// Registering from within a callback.
+		inner, err := f.dispatcher.Subscribe(ipv4Only, nil, func(interface{}) {})
+
+		if err != nil {
+			t.Errorf("Subscribe() from callback returned an error: %v", err)
+		}
+
+		registered <- inner
+
+		// Unregistering itself from within the callback.
+		selfMutex.Lock()
+		defer selfMutex.Unlock()
+
+		if err := f.dispatcher.Unsubscribe(self); err != nil {
+			t.Errorf("Unsubscribe() from callback returned an error: %v", err)
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("Subscribe() returned an error: %v", err)
+	}
+
+	selfMutex.Lock()
+	self = s
+	selfMutex.Unlock()
+
+	f.emit(1)
+
+	var inner *Subscriber
+
+	select {
+	case inner = <-registered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the callback; it's probably deadlocked.")
+	}
+
+	// Wait for the self-unsubscribing callback to finish.
+	waitForDelivery(s)
+
+	f.emit(5)
+
+	if calls := atomic.LoadInt32(&selfCalls); calls != 1 {
+		t.Errorf("Callback was called %d times after unsubscribing itself, although 1 is expected.", calls)
+	}
+
+	if err := f.dispatcher.Unsubscribe(inner); err != nil {
+		t.Errorf("Unsubscribe() returned an error: %v", err)
+	}
+
+	if atomic.LoadInt32(&f.cancellations) != 1 {
+		t.Errorf("Source was canceled %d times, although 1 is expected.", f.cancellations)
+	}
+}
+
+func TestNotificationDispatcherUnsubscribeDuringDelivery(t *testing.T) {
+
+	f := newFakeNotificationSource()
+
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	calls := int32(0)
+
+	s, _ := f.dispatcher.Subscribe(ipv4Only, nil, func(interface{}) {
+		atomic.AddInt32(&calls, 1)
+		entered <- struct{}{}
+		<-release
+	})
+
+	f.emit(1)
+	<-entered
+
+	// Called from another goroutine, Unsubscribe() waits for the running delivery.
+	unsubscribed := make(chan struct{})
+
+	go func() {
+		_ = f.dispatcher.Unsubscribe(s)
+		close(unsubscribed)
+	}()
+
+	select {
+	case <-unsubscribed:
+		t.Fatal("Unsubscribe() returned while the callback was running, although it's expected to wait.")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	f.emit(5)
+	close(release)
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Unsubscribe() didn't return after the callback returned.")
+	}
+
+	if c := atomic.LoadInt32(&calls); c != 1 {
+		t.Errorf("Callback was called %d times, although 1 is expected.", c)
+	}
+
+	// Second call is harmless.
+	if err := f.dispatcher.Unsubscribe(s); err != nil {
+		t.Errorf("Repeated Unsubscribe() returned an error: %v", err)
+	}
+
+	// Called from the callback itself, Unsubscribe() doesn't wait for it.
+	var self *Subscriber
+	subscribed := make(chan struct{})
+	returned := make(chan struct{})
+
+	self, _ = f.dispatcher.Subscribe(ipv4Only, nil, func(interface{}) {
+		<-subscribed
+		_ = f.dispatcher.Unsubscribe(self)
+		close(returned)
+	})
+
+	close(subscribed)
+	f.emit(1)
+
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Unsubscribe() called from the callback waited for the callback itself.")
+	}
+}
+
+func TestNotificationDispatcherConcurrency(t *testing.T) {
+
+	f := newFakeNotificationSource()
+
+	stop := make(chan struct{})
+	sourceDone := make(chan struct{})
+
+	go func() {
+		defer close(sourceDone)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				f.dispatcher.Dispatch(i)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+
+				delivered := int32(0)
+				s, err := f.dispatcher.Subscribe(ipv4Only, nil, func(interface{}) { atomic.AddInt32(&delivered, 1) })
+
+				if err != nil {
+					t.Errorf("Subscribe() returned an error: %v", err)
+					return
+				}
+
+				if err := f.dispatcher.Unsubscribe(s); err != nil {
+					t.Errorf("Unsubscribe() returned an error: %v", err)
+				}
+
+				// A delivery running during Unsubscribe() may still finish; nothing is delivered afterwards.
+				waitForDelivery(s)
+				after := atomic.LoadInt32(&delivered)
+				time.Sleep(time.Microsecond)
+
+				if atomic.LoadInt32(&delivered) != after {
+					t.Error("Callback was called after Unsubscribe() returned.")
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(stop)
+	<-sourceDone
+
+	if r, c := atomic.LoadInt32(&f.registrations), atomic.LoadInt32(&f.cancellations); r != c {
+		t.Errorf("Source was registered %d times and canceled %d times, although equal counts are expected.", r, c)
+	}
+}
+
+func TestNotificationDispatcherPerFamilyRegistration(t *testing.T) {
+
+	var mutex sync.Mutex
+	registered := make(map[uint16]int)
+
+	d := NewDispatcher(func(family uint16) error {
+		mutex.Lock()
+		registered[family]++
+		mutex.Unlock()
+		return nil
+	}, func(family uint16) error {
+		mutex.Lock()
+		registered[family]--
+		mutex.Unlock()
+		return nil
+	}, joinErrors)
+
+	v4, _ := d.Subscribe([]uint16{familyIPv4}, nil, func(interface{}) {})
+	both, _ := d.Subscribe([]uint16{familyIPv4, familyIPv6}, nil, func(interface{}) {})
+
+	if registered[familyIPv4] != 1 || registered[familyIPv6] != 1 {
+		t.Errorf("Registrations are %v, although one per family is expected.", registered)
+	}
+
+	_ = d.Unsubscribe(both)
+
+	if registered[familyIPv4] != 1 || registered[familyIPv6] != 0 {
+		t.Errorf("Registrations are %v, although only IPv4 is expected.", registered)
+	}
+
+	_ = d.Unsubscribe(v4)
+
+	if registered[familyIPv4] != 0 {
+		t.Errorf("Registrations are %v, although none are expected.", registered)
+	}
+}
+
+func TestNotificationDispatcherAccept(t *testing.T) {
+
+	f := newFakeNotificationSource()
+
+	var mutex sync.Mutex
+	var received []int
+
+	s, _ := f.dispatcher.Subscribe(ipv4Only, func(event interface{}) bool { return event.(int)%2 == 0 },
+		func(event interface{}) {
+			mutex.Lock()
+			received = append(received, event.(int))
+			mutex.Unlock()
+		})
+
+	f.emit(10)
+
+	waitFor(t, "deliveries", func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received) == 5
+	})
+
+	_ = f.dispatcher.Unsubscribe(s)
+
+	for _, event := range received {
+		if event%2 != 0 {
+			t.Errorf("Event %d was delivered, although it should have been filtered out.", event)
+		}
+	}
+}
+
+func TestOrderedDispatcher(t *testing.T) {
+
+	first := NewOrderedDispatcher()
+	second := NewOrderedDispatcher()
+
+	var received []int
+	var self *Subscriber
+
+	record := func(event interface{}) { received = append(received, event.(int)) }
+
+	_, _ = first.Subscribe(ipv4Only, nil, record)
+	_, _ = second.Subscribe(ipv4Only, nil, record)
+	self, _ = second.Subscribe(ipv4Only, nil, func(event interface{}) {
+		if err := second.Unsubscribe(self); err != nil {
+			t.Errorf("Unsubscribe() from callback returned an error: %v", err)
+		}
+	})
+
+	// More events than a queue holds, alternating between the dispatchers.
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for i := 0; i < 2*QueueLength; i++ {
+			if i%2 == 0 {
+				first.Dispatch(i)
+			} else {
+				second.Dispatch(i)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for dispatching; it's probably deadlocked.")
+	}
+
+	if len(received) != 2*QueueLength {
+		t.Fatalf("%d events were delivered, although %d are expected.", len(received), 2*QueueLength)
+	}
+
+	for i, event := range received {
+		if event != i {
+			t.Fatalf("Event %d was delivered at position %d.", event, i)
+		}
+	}
+}
+
+func TestSynchronousSubscriber(t *testing.T) {
+
+	f := newFakeNotificationSource()
+
+	// Dispatch() is called from a single goroutine, and delivers synchronously, so no synchronization is needed.
+	var events []interface{}
+
+	s, err := f.dispatcher.SubscribeSynchronous(ipv4Only, func(event interface{}) bool { return event.(int)%2 == 0 },
+		func(event interface{}) { events = append(events, event) })
+
+	if err != nil {
+		t.Fatalf("SubscribeSynchronous() returned an error: %v", err)
+	}
+
+	// emit() returns once everything is dispatched, so delivered as well.
+	f.emit(5)
+
+	if fmt.Sprint(events) != "[0 2 4]" {
+		t.Errorf("Subscriber received %v, although [0 2 4] is expected.", events)
+	}
+
+	if err := f.dispatcher.Unsubscribe(s); err != nil {
+		t.Errorf("Unsubscribe() returned an error: %v", err)
+	}
+
+	f.emit(1)
+
+	if len(events) != 3 {
+		t.Errorf("Subscriber received %v after unsubscribing, although nothing more is expected.", events[3:])
+	}
+}
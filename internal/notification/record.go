@@ -0,0 +1,82 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Kind of a recorded notification, i.e. which dispatcher it comes from.
+type Kind string
+
+const (
+	KindUnicastAddress Kind = "UnicastAddress"
+	KindInterface      Kind = "Interface"
+	KindRoute          Kind = "Route"
+)
+
+// A single recorded notification; one JSON object per line in recordings.
+type Record struct {
+	// Time the notification was received.
+	Time time.Time
+	// Position of the notification among all recorded ones, starting at 0. Records are written in this order.
+	Sequence         uint64
+	Kind             Kind
+	NotificationType uint32
+	Family           uint16
+	InterfaceLuid    uint64
+	Ip               net.IP `json:",omitempty"`
+	// Converted row, whose type depends on Kind; nil if there's none.
+	Row interface{} `json:",omitempty"`
+}
+
+// Record as it's decoded, before the row type is known.
+type rawRecord struct {
+	Record
+	Row json.RawMessage
+}
+
+// Reads records written by a Recorder. Rows are decoded into values returned by 'newRow' (a pointer to a new row of
+// the kind's type); rows of kinds for which it returns nil are left out.
+func ReadRecords(r io.Reader, newRow func(kind Kind) interface{}) ([]*Record, error) {
+
+	decoder := json.NewDecoder(r)
+
+	var records []*Record
+
+	for {
+
+		raw := &rawRecord{}
+
+		err := decoder.Decode(raw)
+
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading record #%d failed: %w", len(records), err)
+		}
+
+		record := raw.Record
+		record.Row = nil
+
+		if len(raw.Row) > 0 && string(raw.Row) != "null" {
+			if row := newRow(record.Kind); row != nil {
+				if err := json.Unmarshal(raw.Row, row); err != nil {
+					return nil, fmt.Errorf("reading row of record #%d failed: %w", len(records), err)
+				}
+				record.Row = row
+			}
+		}
+
+		records = append(records, &record)
+	}
+}
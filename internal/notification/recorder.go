@@ -0,0 +1,211 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Notifications of one kind to be recorded by a Recorder.
+type Recording struct {
+	Kind     Kind
+	Families []uint16
+	// Filter of events, as Subscribe()'s 'accept'; nil records everything.
+	Accept func(event interface{}) bool
+	// Converts an accepted event into its record. It's called as the notification is received, so it has to copy
+	// whatever the event shares with other subscribers. Time and Sequence are set by the Recorder.
+	Record func(event interface{}) *Record
+}
+
+// Writes notifications to a JSONL stream. Subscriptions are synchronous, so notifications are recorded as they're
+// received (i.e. on the OS notification thread), with a single sequence of numbers and timestamps across all kinds,
+// and written in that order by a single goroutine.
+type Recorder struct {
+	encoder *json.Encoder
+	now     func() time.Time
+	// Turns more than one error into a single one.
+	join func(errs []error) error
+
+	// Protects fields below.
+	mutex    sync.Mutex
+	sequence uint64
+	// Records waiting to be written.
+	pending []*Record
+	closed  bool
+	// The first error encountered while writing.
+	err error
+
+	// Signaled when pending records are added or the recorder is closed.
+	wake chan struct{}
+	// Closed by the writing goroutine when it exits.
+	written chan struct{}
+
+	dispatchers []*Dispatcher
+	subscribers []*Subscriber
+}
+
+// Starts recording 'recordings' from 'source' to 'w', until Close() is called.
+func NewRecorder(source Source, w io.Writer, recordings []Recording, now func() time.Time,
+	join func(errs []error) error) (*Recorder, error) {
+
+	recorder := &Recorder{
+		encoder: json.NewEncoder(w),
+		now:     now,
+		join:    join,
+		wake:    make(chan struct{}, 1),
+		written: make(chan struct{}),
+	}
+
+	for _, recording := range recordings {
+
+		recording := recording
+		dispatcher := source.Dispatcher(recording.Kind)
+
+		if dispatcher == nil {
+			recorder.unsubscribe()
+			return nil, fmt.Errorf("source has no %s notifications", recording.Kind)
+		}
+
+		subscriber, err := dispatcher.SubscribeSynchronous(recording.Families, recording.Accept,
+			func(event interface{}) { recorder.add(recording.Record(event)) })
+
+		if err != nil {
+			recorder.unsubscribe()
+			return nil, err
+		}
+
+		recorder.dispatchers = append(recorder.dispatchers, dispatcher)
+		recorder.subscribers = append(recorder.subscribers, subscriber)
+	}
+
+	go recorder.write()
+
+	return recorder, nil
+}
+
+// Numbers, timestamps and queues the record for writing.
+func (recorder *Recorder) add(record *Record) {
+
+	recorder.mutex.Lock()
+
+	if recorder.closed {
+		recorder.mutex.Unlock()
+		return
+	}
+
+	record.Sequence = recorder.sequence
+	recorder.sequence++
+	record.Time = recorder.now()
+	recorder.pending = append(recorder.pending, record)
+
+	recorder.mutex.Unlock()
+
+	select {
+	case recorder.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Writes pending records in order, until the recorder is closed and nothing is pending.
+func (recorder *Recorder) write() {
+
+	defer close(recorder.written)
+
+	for {
+
+		recorder.mutex.Lock()
+		pending := recorder.pending
+		recorder.pending = nil
+		closed := recorder.closed
+		recorder.mutex.Unlock()
+
+		if len(pending) == 0 {
+			if closed {
+				return
+			}
+			<-recorder.wake
+			continue
+		}
+
+		for _, record := range pending {
+			if err := recorder.encoder.Encode(record); err != nil {
+				recorder.mutex.Lock()
+				if recorder.err == nil {
+					recorder.err = fmt.Errorf("writing record #%d failed: %w", record.Sequence, err)
+				}
+				recorder.mutex.Unlock()
+			}
+		}
+	}
+}
+
+// Returns the first error encountered while writing records, if any. Recording continues after errors.
+func (recorder *Recorder) Err() error {
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.err
+}
+
+// Unsubscribes from all dispatchers subscribed so far, and returns errors of doing so.
+func (recorder *Recorder) unsubscribe() []error {
+
+	var errs []error
+
+	for i, subscriber := range recorder.subscribers {
+		if err := recorder.dispatchers[i].Unsubscribe(subscriber); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Stops recording, and waits until all recorded notifications are written. Returns errors of unsubscribing, or the
+// first write error.
+func (recorder *Recorder) Close() error {
+
+	recorder.mutex.Lock()
+
+	if recorder.closed {
+		err := recorder.err
+		recorder.mutex.Unlock()
+		return err
+	}
+
+	recorder.mutex.Unlock()
+
+	errs := recorder.unsubscribe()
+
+	recorder.mutex.Lock()
+	recorder.closed = true
+	recorder.mutex.Unlock()
+
+	select {
+	case recorder.wake <- struct{}{}:
+	default:
+	}
+
+	<-recorder.written
+
+	if err := recorder.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return recorder.join(errs)
+	}
+}
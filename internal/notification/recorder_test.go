@@ -0,0 +1,150 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Records all notifications of all kinds, except those of interface 99.
+func testRecordings() []Recording {
+
+	var recordings []Recording
+
+	for _, kind := range []Kind{KindUnicastAddress, KindInterface, KindRoute} {
+		recordings = append(recordings, Recording{
+			Kind:     kind,
+			Families: []uint16{familyIPv4, familyIPv6},
+			Accept:   func(event interface{}) bool { return event.(*Record).InterfaceLuid != 99 },
+			Record: func(event interface{}) *Record {
+				record := *event.(*Record)
+				return &record
+			},
+		})
+	}
+
+	return recordings
+}
+
+func TestRecorder(t *testing.T) {
+
+	records := fakeRecords()
+	// Filtered out.
+	records = append(records[:1], append([]*Record{{Kind: KindRoute, InterfaceLuid: 99}}, records[1:]...)...)
+
+	replayer := NewReplayer(records, recordEvent)
+
+	var buffer bytes.Buffer
+	now := replayStart
+
+	recorder, err := NewRecorder(replayer, &buffer, testRecordings(), func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}, joinErrors)
+
+	if err != nil {
+		t.Fatalf("NewRecorder() returned an error: %v", err)
+	}
+
+	if err := replayer.Replay(0); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() returned an error: %v", err)
+	}
+
+	if lines := strings.Count(buffer.String(), "\n"); lines != 3 {
+		t.Fatalf("Recorder wrote %d lines, although 3 are expected.", lines)
+	}
+
+	recorded, err := ReadRecords(&buffer, newTestRow)
+
+	if err != nil {
+		t.Fatalf("ReadRecords() returned an error: %v", err)
+	}
+
+	original := fakeRecords()
+
+	if len(recorded) != len(original) {
+		t.Fatalf("ReadRecords() returned %d records, although %d are expected.", len(recorded), len(original))
+	}
+
+	// Records of all kinds are numbered and timestamped in the order of the notifications.
+	for i, record := range recorded {
+
+		if record.Sequence != uint64(i) || !record.Time.Equal(replayStart.Add(time.Duration(i+1)*time.Second)) {
+			t.Errorf("Record #%d has sequence number %d and timestamp %v, although %d and %v are expected.", i,
+				record.Sequence, record.Time, i, replayStart.Add(time.Duration(i+1)*time.Second))
+		}
+
+		expected := original[i]
+		row, _ := record.Row.(*testRow)
+
+		if record.Kind != expected.Kind || record.NotificationType != expected.NotificationType ||
+			record.Family != expected.Family || record.InterfaceLuid != expected.InterfaceLuid ||
+			!record.Ip.Equal(expected.Ip) || row == nil || *row != *expected.Row.(*testRow) {
+			t.Errorf("Record #%d is %+v, although %+v is expected.", i, record, expected)
+		}
+	}
+
+	// Repeated Close() is harmless.
+	if err := recorder.Close(); err != nil {
+		t.Errorf("Repeated Close() returned an error: %v", err)
+	}
+}
+
+func TestRecorderUnknownKind(t *testing.T) {
+
+	// Unicast address changes are subscribed before interface changes turn out to be missing.
+	dispatcher := NewOrderedDispatcher()
+	source := Dispatchers{KindUnicastAddress: dispatcher}
+
+	if _, err := NewRecorder(source, &bytes.Buffer{}, testRecordings(), time.Now, joinErrors); err == nil {
+		t.Error("NewRecorder() didn't return an error for a source without all kinds, although it's expected.")
+	}
+
+	if subscribers := dispatcher.currentSubscribers(); len(subscribers) != 0 {
+		t.Errorf("Dispatcher has %d subscribers after NewRecorder() failed, although none are expected.",
+			len(subscribers))
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+
+	input := `{"Kind": "Route", "InterfaceLuid": 2, "Row": {"Name": "route"}}
+{"Kind": "Unknown", "Row": {"Name": "unknown"}}
+{"Kind": "Interface"}
+`
+
+	records, err := ReadRecords(strings.NewReader(input), newTestRow)
+
+	if err != nil {
+		t.Fatalf("ReadRecords() returned an error: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("ReadRecords() returned %d records, although 3 are expected.", len(records))
+	}
+
+	if row, ok := records[0].Row.(*testRow); !ok || row.Name != "route" || records[0].InterfaceLuid != 2 {
+		t.Errorf("Route record is %+v, although its row is expected to be decoded.", records[0])
+	}
+
+	if records[1].Row != nil || records[2].Row != nil {
+		t.Errorf("Records without rows of known kinds have rows %v and %v, although none are expected.",
+			records[1].Row, records[2].Row)
+	}
+
+	for _, malformed := range []string{"{\"Kind\": \"Route\"}\nnot json\n", `{"Kind": "Route", "Row": 5}`} {
+		if _, err := ReadRecords(strings.NewReader(malformed), newTestRow); err == nil {
+			t.Errorf("ReadRecords() didn't return an error for %q, although it's expected.", malformed)
+		}
+	}
+}
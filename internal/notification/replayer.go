@@ -0,0 +1,109 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// Provides the dispatchers notifications of each kind come from, e.g. the OS ones, or a Replayer.
+type Source interface {
+	// Returns the dispatcher of 'kind', or nil if there's none.
+	Dispatcher(kind Kind) *Dispatcher
+}
+
+// Source made of a fixed set of dispatchers.
+type Dispatchers map[Kind]*Dispatcher
+
+func (dispatchers Dispatchers) Dispatcher(kind Kind) *Dispatcher {
+	return dispatchers[kind]
+}
+
+// Source delivering recorded notifications. Each kind has its own ordered dispatcher, and all of them are fed from a
+// single goroutine, so subscribers receive the notifications in the recorded order, and nothing is ever dropped.
+type Replayer struct {
+	records     []*Record
+	dispatchers Dispatchers
+	// Converts a record into the event dispatched to subscribers, or returns an error if it can't be replayed.
+	convert func(record *Record) (interface{}, error)
+
+	sleep func(d time.Duration)
+}
+
+func NewReplayer(records []*Record, convert func(record *Record) (event interface{}, err error)) *Replayer {
+
+	return &Replayer{
+		records: records,
+		dispatchers: Dispatchers{
+			KindUnicastAddress: NewOrderedDispatcher(),
+			KindInterface:      NewOrderedDispatcher(),
+			KindRoute:          NewOrderedDispatcher(),
+		},
+		convert: convert,
+		sleep:   time.Sleep,
+	}
+}
+
+func (replayer *Replayer) Dispatcher(kind Kind) *Dispatcher {
+	return replayer.dispatchers[kind]
+}
+
+// Dispatches all records, and returns once they're all delivered. If 'speed' is 0, records are replayed without
+// delays; otherwise delays between records are the recorded ones divided by 'speed' (so 1 means real time, and 10 ten
+// times faster). All records are converted first, so if any of them can't be, nothing is dispatched. It must not be
+// called from a subscriber's callback.
+func (replayer *Replayer) Replay(speed float64) error {
+
+	if speed < 0 {
+		return fmt.Errorf("invalid speed %v", speed)
+	}
+
+	events := make([]interface{}, len(replayer.records))
+
+	for i, record := range replayer.records {
+
+		if replayer.dispatchers[record.Kind] == nil {
+			return fmt.Errorf("record #%d: unknown kind %q", i, record.Kind)
+		}
+
+		event, err := replayer.convert(record)
+
+		if err != nil {
+			return fmt.Errorf("record #%d: %v", i, err)
+		}
+
+		events[i] = event
+	}
+
+	// Records are queued (blocking when the queue is full) for a single goroutine delivering them in order, so
+	// delays aren't affected by slow callbacks, unless they fall behind by a whole queue.
+	queue := make(chan int, QueueLength)
+	delivered := make(chan struct{})
+
+	go func() {
+		defer close(delivered)
+		for i := range queue {
+			replayer.dispatchers[replayer.records[i].Kind].Dispatch(events[i])
+		}
+	}()
+
+	for i, record := range replayer.records {
+
+		if speed > 0 && i > 0 {
+			if delay := time.Duration(float64(record.Time.Sub(replayer.records[i-1].Time)) / speed); delay > 0 {
+				replayer.sleep(delay)
+			}
+		}
+
+		queue <- i
+	}
+
+	close(queue)
+	<-delivered
+
+	return nil
+}
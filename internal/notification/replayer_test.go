@@ -0,0 +1,156 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+var replayStart = time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
+
+// Row type of test records.
+type testRow struct {
+	Name string
+}
+
+func newTestRow(kind Kind) interface{} {
+
+	switch kind {
+	case KindUnicastAddress, KindInterface, KindRoute:
+		return &testRow{}
+	default:
+		return nil
+	}
+}
+
+func fakeRecords() []*Record {
+
+	return []*Record{
+		{Time: replayStart, Kind: KindInterface, NotificationType: 0, Family: familyIPv4, InterfaceLuid: 1,
+			Row: &testRow{"interface"}},
+		{Time: replayStart.Add(time.Second), Kind: KindUnicastAddress, NotificationType: 1, Family: familyIPv4,
+			InterfaceLuid: 1, Ip: net.IP{10, 0, 0, 2}, Row: &testRow{"address"}},
+		{Time: replayStart.Add(3 * time.Second), Kind: KindRoute, NotificationType: 1, Family: familyIPv6,
+			InterfaceLuid: 2, Row: &testRow{"route"}},
+	}
+}
+
+// Dispatches records themselves as events.
+func recordEvent(record *Record) (interface{}, error) {
+	return record, nil
+}
+
+func TestReplayerSpeed(t *testing.T) {
+
+	replayer := NewReplayer(fakeRecords(), recordEvent)
+
+	var delays []time.Duration
+	replayer.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	if err := replayer.Replay(2); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	if len(delays) != 2 || delays[0] != 500*time.Millisecond || delays[1] != time.Second {
+		t.Errorf("Replay(2) slept %v, although [500ms 1s] is expected.", delays)
+	}
+
+	delays = nil
+
+	if err := replayer.Replay(0); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	if len(delays) != 0 {
+		t.Errorf("Replay(0) slept %v, although no delays are expected.", delays)
+	}
+
+	if err := replayer.Replay(-1); err == nil {
+		t.Error("Replay(-1) didn't return an error, although it's expected.")
+	}
+}
+
+func TestReplayerOrder(t *testing.T) {
+
+	// More records than a subscriber's queue holds, alternating between kinds.
+	var records []*Record
+
+	for i := 0; i < 2*QueueLength; i++ {
+		kind := KindInterface
+		if i%2 != 0 {
+			kind = KindRoute
+		}
+		records = append(records, &Record{Kind: kind, Family: familyIPv4, InterfaceLuid: uint64(i)})
+	}
+
+	replayer := NewReplayer(records, recordEvent)
+
+	// Callbacks are called from a single goroutine, so no synchronization is needed.
+	var luids []uint64
+
+	interfaces, _ := replayer.Dispatcher(KindInterface).Subscribe(ipv4Only, nil, func(event interface{}) {
+		time.Sleep(time.Microsecond)
+		luids = append(luids, event.(*Record).InterfaceLuid)
+	})
+
+	routes, _ := replayer.Dispatcher(KindRoute).Subscribe(ipv4Only, nil, func(event interface{}) {
+		luids = append(luids, event.(*Record).InterfaceLuid)
+	})
+
+	if err := replayer.Replay(0); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	if interfaces.Dropped() != 0 || routes.Dropped() != 0 {
+		t.Errorf("Subscribers dropped %d and %d notifications, although none are expected.", interfaces.Dropped(),
+			routes.Dropped())
+	}
+
+	if len(luids) != len(records) {
+		t.Fatalf("Subscribers received %d notifications, although %d are expected.", len(luids), len(records))
+	}
+
+	for i, luid := range luids {
+		if luid != uint64(i) {
+			t.Fatalf("Notification #%d was delivered at position %d.", luid, i)
+		}
+	}
+}
+
+func TestReplayerInvalidRecords(t *testing.T) {
+
+	delivered := 0
+
+	invalid := [][]*Record{
+		append(fakeRecords(), &Record{Kind: "Unknown"}),
+		append(fakeRecords(), &Record{Kind: KindRoute}),
+	}
+
+	for i, records := range invalid {
+
+		replayer := NewReplayer(records, func(record *Record) (interface{}, error) {
+			if record.Row == nil {
+				return nil, errors.New("row is missing")
+			}
+			return record, nil
+		})
+
+		for _, kind := range []Kind{KindUnicastAddress, KindInterface, KindRoute} {
+			_, _ = replayer.Dispatcher(kind).Subscribe(nil, nil, func(interface{}) { delivered++ })
+		}
+
+		if err := replayer.Replay(0); err == nil {
+			t.Errorf("Replay() of records #%d didn't return an error, although it's expected.", i)
+		}
+	}
+
+	if delivered != 0 {
+		t.Errorf("%d notifications were delivered, although none are expected.", delivered)
+	}
+}
@@ -0,0 +1,12 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+import "syscall"
+
+func currentThreadId() uint64 {
+	return uint64(syscall.Gettid())
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !linux
+// +build !windows,!linux
+
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package notification
+
+// OS thread IDs aren't available, so all threads look the same: CallbackGuard can't tell the callback from other
+// callers, and never waits for a running call.
+func currentThreadId() uint64 {
+	return 1
+}
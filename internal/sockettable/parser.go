@@ -0,0 +1,249 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+// Package sockettable decodes raw buffers returned by GetExtendedTcpTable and GetExtendedUdpTable, rather than casting
+// them to Go structs. The layouts are the same on all supported architectures, and decoding doesn't depend on anything
+// Windows specific, so the parsers are tested against recorded table fixtures on any OS.
+package sockettable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// Size of the table header preceding the rows, i.e. the minimum size of a table buffer.
+const HeaderSize = ownerModuleTable_Table_Offset
+
+// Local or remote address of a socket.
+type Endpoint struct {
+	Address net.IP
+	Port    uint16
+	// IPv6 scope ID; always 0 for IPv4.
+	ScopeId uint32
+}
+
+// Decoded MIB_TCPROW_OWNER_MODULE or MIB_TCP6ROW_OWNER_MODULE.
+type TcpRow struct {
+	Local     Endpoint
+	Remote    Endpoint
+	State     uint32
+	OwningPid uint32
+	// Time the socket was created, as FILETIME.
+	CreateTimestamp int64
+	// The row as returned by the OS (a sub-slice of the table), which is needed to query the owning module.
+	Raw []byte
+}
+
+// Decoded MIB_UDPROW_OWNER_MODULE or MIB_UDP6ROW_OWNER_MODULE.
+type UdpRow struct {
+	Local     Endpoint
+	OwningPid uint32
+	// Time the socket was created, as FILETIME.
+	CreateTimestamp  int64
+	SpecificPortBind bool
+	// The row as returned by the OS (a sub-slice of the table), which is needed to query the owning module.
+	Raw []byte
+}
+
+const (
+	// Offset of the first row in MIB_TCPTABLE_OWNER_MODULE, MIB_TCP6TABLE_OWNER_MODULE, MIB_UDPTABLE_OWNER_MODULE and
+	// MIB_UDP6TABLE_OWNER_MODULE (dwNumEntries followed by padding, since rows contain LARGE_INTEGER).
+	ownerModuleTable_Table_Offset = 8
+
+	// MIB_TCPROW_OWNER_MODULE defined in tcpmib.h
+	tcpRowOwnerModule_Size                   = 160
+	tcpRowOwnerModule_State_Offset           = 0
+	tcpRowOwnerModule_LocalAddr_Offset       = 4
+	tcpRowOwnerModule_LocalPort_Offset       = 8
+	tcpRowOwnerModule_RemoteAddr_Offset      = 12
+	tcpRowOwnerModule_RemotePort_Offset      = 16
+	tcpRowOwnerModule_OwningPid_Offset       = 20
+	tcpRowOwnerModule_CreateTimestamp_Offset = 24
+
+	// MIB_TCP6ROW_OWNER_MODULE defined in tcpmib.h
+	tcp6RowOwnerModule_Size                   = 192
+	tcp6RowOwnerModule_LocalAddr_Offset       = 0
+	tcp6RowOwnerModule_LocalScopeId_Offset    = 16
+	tcp6RowOwnerModule_LocalPort_Offset       = 20
+	tcp6RowOwnerModule_RemoteAddr_Offset      = 24
+	tcp6RowOwnerModule_RemoteScopeId_Offset   = 40
+	tcp6RowOwnerModule_RemotePort_Offset      = 44
+	tcp6RowOwnerModule_State_Offset           = 48
+	tcp6RowOwnerModule_OwningPid_Offset       = 52
+	tcp6RowOwnerModule_CreateTimestamp_Offset = 56
+
+	// MIB_UDPROW_OWNER_MODULE defined in udpmib.h
+	udpRowOwnerModule_Size                   = 160
+	udpRowOwnerModule_LocalAddr_Offset       = 0
+	udpRowOwnerModule_LocalPort_Offset       = 4
+	udpRowOwnerModule_OwningPid_Offset       = 8
+	udpRowOwnerModule_CreateTimestamp_Offset = 16
+	udpRowOwnerModule_Flags_Offset           = 24
+
+	// MIB_UDP6ROW_OWNER_MODULE defined in udpmib.h
+	udp6RowOwnerModule_Size                   = 176
+	udp6RowOwnerModule_LocalAddr_Offset       = 0
+	udp6RowOwnerModule_LocalScopeId_Offset    = 16
+	udp6RowOwnerModule_LocalPort_Offset       = 20
+	udp6RowOwnerModule_OwningPid_Offset       = 24
+	udp6RowOwnerModule_CreateTimestamp_Offset = 32
+	udp6RowOwnerModule_Flags_Offset           = 40
+)
+
+// Splits a raw *_OWNER_MODULE table into raw rows. Returned rows are sub-slices of 'table'.
+func splitOwnerModuleTable(table []byte, rowSize int) ([][]byte, error) {
+
+	if len(table) < 4 {
+		return nil, fmt.Errorf("socket table is too short (%d bytes)", len(table))
+	}
+
+	count := int(binary.LittleEndian.Uint32(table))
+
+	if count == 0 {
+		return [][]byte{}, nil
+	}
+
+	if end := ownerModuleTable_Table_Offset + count*rowSize; count < 0 || end > len(table) || end < 0 {
+		return nil, fmt.Errorf("socket table with %d rows doesn't fit in %d bytes", count, len(table))
+	}
+
+	rows := make([][]byte, count)
+
+	for i := range rows {
+		offset := ownerModuleTable_Table_Offset + i*rowSize
+		rows[i] = table[offset : offset+rowSize]
+	}
+
+	return rows, nil
+}
+
+// Decodes port stored in a DWORD, where only the lower two bytes (in network byte order) are used.
+func decodeTablePort(b []byte) uint16 {
+	return binary.BigEndian.Uint16(b[:2])
+}
+
+func decodeEndpoint4(addr, port []byte) Endpoint {
+	return Endpoint{
+		Address: net.IPv4(addr[0], addr[1], addr[2], addr[3]).To4(),
+		Port:    decodeTablePort(port),
+	}
+}
+
+func decodeEndpoint6(addr, scopeId, port []byte) Endpoint {
+
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, addr[:net.IPv6len])
+
+	return Endpoint{
+		Address: ip,
+		Port:    decodeTablePort(port),
+		ScopeId: binary.LittleEndian.Uint32(scopeId),
+	}
+}
+
+// Decodes MIB_TCPTABLE_OWNER_MODULE.
+func ParseTcpTable(table []byte) ([]*TcpRow, error) {
+
+	raw, err := splitOwnerModuleTable(table, tcpRowOwnerModule_Size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*TcpRow, len(raw))
+
+	for i, row := range raw {
+		rows[i] = &TcpRow{
+			Local: decodeEndpoint4(row[tcpRowOwnerModule_LocalAddr_Offset:],
+				row[tcpRowOwnerModule_LocalPort_Offset:]),
+			Remote: decodeEndpoint4(row[tcpRowOwnerModule_RemoteAddr_Offset:],
+				row[tcpRowOwnerModule_RemotePort_Offset:]),
+			State:           binary.LittleEndian.Uint32(row[tcpRowOwnerModule_State_Offset:]),
+			OwningPid:       binary.LittleEndian.Uint32(row[tcpRowOwnerModule_OwningPid_Offset:]),
+			CreateTimestamp: int64(binary.LittleEndian.Uint64(row[tcpRowOwnerModule_CreateTimestamp_Offset:])),
+			Raw:             row,
+		}
+	}
+
+	return rows, nil
+}
+
+// Decodes MIB_TCP6TABLE_OWNER_MODULE.
+func ParseTcp6Table(table []byte) ([]*TcpRow, error) {
+
+	raw, err := splitOwnerModuleTable(table, tcp6RowOwnerModule_Size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*TcpRow, len(raw))
+
+	for i, row := range raw {
+		rows[i] = &TcpRow{
+			Local: decodeEndpoint6(row[tcp6RowOwnerModule_LocalAddr_Offset:],
+				row[tcp6RowOwnerModule_LocalScopeId_Offset:], row[tcp6RowOwnerModule_LocalPort_Offset:]),
+			Remote: decodeEndpoint6(row[tcp6RowOwnerModule_RemoteAddr_Offset:],
+				row[tcp6RowOwnerModule_RemoteScopeId_Offset:], row[tcp6RowOwnerModule_RemotePort_Offset:]),
+			State:           binary.LittleEndian.Uint32(row[tcp6RowOwnerModule_State_Offset:]),
+			OwningPid:       binary.LittleEndian.Uint32(row[tcp6RowOwnerModule_OwningPid_Offset:]),
+			CreateTimestamp: int64(binary.LittleEndian.Uint64(row[tcp6RowOwnerModule_CreateTimestamp_Offset:])),
+			Raw:             row,
+		}
+	}
+
+	return rows, nil
+}
+
+// Decodes MIB_UDPTABLE_OWNER_MODULE.
+func ParseUdpTable(table []byte) ([]*UdpRow, error) {
+
+	raw, err := splitOwnerModuleTable(table, udpRowOwnerModule_Size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*UdpRow, len(raw))
+
+	for i, row := range raw {
+		rows[i] = &UdpRow{
+			Local: decodeEndpoint4(row[udpRowOwnerModule_LocalAddr_Offset:],
+				row[udpRowOwnerModule_LocalPort_Offset:]),
+			OwningPid:        binary.LittleEndian.Uint32(row[udpRowOwnerModule_OwningPid_Offset:]),
+			CreateTimestamp:  int64(binary.LittleEndian.Uint64(row[udpRowOwnerModule_CreateTimestamp_Offset:])),
+			SpecificPortBind: binary.LittleEndian.Uint32(row[udpRowOwnerModule_Flags_Offset:])&1 != 0,
+			Raw:              row,
+		}
+	}
+
+	return rows, nil
+}
+
+// Decodes MIB_UDP6TABLE_OWNER_MODULE.
+func ParseUdp6Table(table []byte) ([]*UdpRow, error) {
+
+	raw, err := splitOwnerModuleTable(table, udp6RowOwnerModule_Size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*UdpRow, len(raw))
+
+	for i, row := range raw {
+		rows[i] = &UdpRow{
+			Local: decodeEndpoint6(row[udp6RowOwnerModule_LocalAddr_Offset:],
+				row[udp6RowOwnerModule_LocalScopeId_Offset:], row[udp6RowOwnerModule_LocalPort_Offset:]),
+			OwningPid:        binary.LittleEndian.Uint32(row[udp6RowOwnerModule_OwningPid_Offset:]),
+			CreateTimestamp:  int64(binary.LittleEndian.Uint64(row[udp6RowOwnerModule_CreateTimestamp_Offset:])),
+			SpecificPortBind: binary.LittleEndian.Uint32(row[udp6RowOwnerModule_Flags_Offset:])&1 != 0,
+			Raw:              row,
+		}
+	}
+
+	return rows, nil
+}
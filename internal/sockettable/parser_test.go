@@ -0,0 +1,183 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package sockettable
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+)
+
+// MIB_TCP_STATE values used by the fixtures.
+const (
+	tcpStateListen = 2
+	tcpStateEstab  = 5
+)
+
+// Raw tables as returned by GetExtendedTcpTable(TCP_TABLE_OWNER_MODULE_ALL) and
+// GetExtendedUdpTable(UDP_TABLE_OWNER_MODULE).
+const (
+	tcpTableOwnerModuleFixture = "" +
+		"0200000000000000020000000000000001bd0000000000000000000004000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000050000000a000005c35000005db8d82201bb0000d2040000" +
+		"00005af64cf5d401000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000"
+
+	tcp6TableOwnerModuleFixture = "" +
+		"0100000000000000fe8000000000000000000000000000010c000000c3510000" +
+		"20010db80000000000000000000000010000000001bb000005000000e1100000" +
+		"0100000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000"
+
+	udpTableOwnerModuleFixture = "" +
+		"01000000000000007f0000010035000063000000000000000700000000000000" +
+		"0100000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000"
+
+	udp6TableOwnerModuleFixture = "" +
+		"0100000000000000000000000000000000000000000000000000000014e90000" +
+		"6400000000000000080000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"000000000000000000000000000000000000000000000000"
+)
+
+func decodeFixture(t *testing.T, fixture string) []byte {
+	b, err := hex.DecodeString(fixture)
+	if err != nil {
+		t.Fatalf("Invalid fixture: %v", err)
+	}
+	return b
+}
+
+func checkEndpoint(t *testing.T, what string, endpoint *Endpoint, ip string, port uint16, scopeId uint32) {
+
+	if !endpoint.Address.Equal(net.ParseIP(ip)) || endpoint.Port != port || endpoint.ScopeId != scopeId {
+		t.Errorf("%s is %s port %d scope %d, although %s port %d scope %d is expected.", what, endpoint.Address,
+			endpoint.Port, endpoint.ScopeId, ip, port, scopeId)
+	}
+}
+
+func TestParseTcpTableOwnerModule(t *testing.T) {
+
+	connections, err := ParseTcpTable(decodeFixture(t, tcpTableOwnerModuleFixture))
+
+	if err != nil {
+		t.Fatalf("ParseTcpTable() returned an error: %v", err)
+	}
+
+	if len(connections) != 2 {
+		t.Fatalf("ParseTcpTable() returned %d rows, although 2 are expected.", len(connections))
+	}
+
+	c := connections[0]
+	checkEndpoint(t, "Local", &c.Local, "0.0.0.0", 445, 0)
+	if c.State != tcpStateListen || c.OwningPid != 4 {
+		t.Errorf("Unexpected first row: %v", c)
+	}
+
+	c = connections[1]
+	checkEndpoint(t, "Local", &c.Local, "10.0.0.5", 50000, 0)
+	checkEndpoint(t, "Remote", &c.Remote, "93.184.216.34", 443, 0)
+	if c.State != tcpStateEstab || c.OwningPid != 1234 || c.CreateTimestamp != 132000000000000000 {
+		t.Errorf("Unexpected second row: %v", c)
+	}
+
+	if len(c.Raw) != tcpRowOwnerModule_Size {
+		t.Errorf("Raw row has %d bytes, although %d are expected.", len(c.Raw), tcpRowOwnerModule_Size)
+	}
+}
+
+func TestParseTcp6TableOwnerModule(t *testing.T) {
+
+	connections, err := ParseTcp6Table(decodeFixture(t, tcp6TableOwnerModuleFixture))
+
+	if err != nil {
+		t.Fatalf("ParseTcp6Table() returned an error: %v", err)
+	}
+
+	if len(connections) != 1 {
+		t.Fatalf("ParseTcp6Table() returned %d rows, although 1 is expected.", len(connections))
+	}
+
+	c := connections[0]
+	checkEndpoint(t, "Local", &c.Local, "fe80::1", 50001, 12)
+	checkEndpoint(t, "Remote", &c.Remote, "2001:db8::1", 443, 0)
+	if c.State != tcpStateEstab || c.OwningPid != 4321 || c.CreateTimestamp != 1 {
+		t.Errorf("Unexpected row: %v", c)
+	}
+}
+
+func TestParseUdpTableOwnerModule(t *testing.T) {
+
+	endpoints, err := ParseUdpTable(decodeFixture(t, udpTableOwnerModuleFixture))
+
+	if err != nil {
+		t.Fatalf("ParseUdpTable() returned an error: %v", err)
+	}
+
+	if len(endpoints) != 1 {
+		t.Fatalf("ParseUdpTable() returned %d rows, although 1 is expected.", len(endpoints))
+	}
+
+	e := endpoints[0]
+	checkEndpoint(t, "Local", &e.Local, "127.0.0.1", 53, 0)
+	if e.OwningPid != 99 || e.CreateTimestamp != 7 || !e.SpecificPortBind {
+		t.Errorf("Unexpected row: %v", e)
+	}
+}
+
+func TestParseUdp6TableOwnerModule(t *testing.T) {
+
+	endpoints, err := ParseUdp6Table(decodeFixture(t, udp6TableOwnerModuleFixture))
+
+	if err != nil {
+		t.Fatalf("ParseUdp6Table() returned an error: %v", err)
+	}
+
+	if len(endpoints) != 1 {
+		t.Fatalf("ParseUdp6Table() returned %d rows, although 1 is expected.", len(endpoints))
+	}
+
+	e := endpoints[0]
+	checkEndpoint(t, "Local", &e.Local, "::", 5353, 0)
+	if e.OwningPid != 100 || e.CreateTimestamp != 8 || e.SpecificPortBind {
+		t.Errorf("Unexpected row: %v", e)
+	}
+}
+
+func TestParseTruncatedSocketTable(t *testing.T) {
+
+	table := decodeFixture(t, tcpTableOwnerModuleFixture)
+
+	if _, err := ParseTcpTable(table[:len(table)-1]); err == nil {
+		t.Error("ParseTcpTable() accepted a truncated table.")
+	}
+
+	if _, err := ParseUdpTable(table[:2]); err == nil {
+		t.Error("ParseUdpTable() accepted a table without header.")
+	}
+
+	empty, err := ParseUdp6Table(make([]byte, ownerModuleTable_Table_Offset))
+
+	if err != nil || len(empty) != 0 {
+		t.Errorf("ParseUdp6Table() returned %v, %v for an empty table.", empty, err)
+	}
+}
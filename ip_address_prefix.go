@@ -39,7 +39,7 @@ func (ap *IpAddressPrefix) toNetIpNet() (*net.IPNet, error) {
 		}, nil
 	}
 
-	return nil, fmt.Errorf("IpAddressPrefix.toNetIpNet() - invalid receiver argument")
+	return nil, newKindError(ErrInvalidParameter, "IpAddressPrefix.toNetIpNet() - invalid receiver argument")
 }
 
 func (ap *IpAddressPrefix) toWtIpAddressPrefix() (*wtIpAddressPrefix, error) {
@@ -0,0 +1,416 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Minimum MTU of an IPv4 interface which Windows accepts.
+	ipv4MinimumMtu = 576
+	// Minimum link MTU required by RFC 8200.
+	ipv6MinimumMtu = 1280
+	// Largest MTU usable without jumbograms.
+	ipMaximumMtu = 65535
+	// Sanity limit of DadTransmits. Neither RFC 4862 nor Windows define one, but larger values only delay address
+	// configuration by seconds per transmit, so they're almost certainly a mistake.
+	ipInterfaceMaxDadTransmits = 10
+)
+
+// Describes a modifiable IpInterface field: how to read it from IpInterface, and how to write it into the row passed
+// to SetIpInterfaceEntry.
+type ipInterfaceFieldSpec struct {
+	get   func(ipifc *IpInterface) interface{}
+	apply func(ipifc *IpInterface, row *wtMibIpinterfaceRow)
+}
+
+var ipInterfaceFieldSpecs = map[string]ipInterfaceFieldSpec{
+	"AdvertisingEnabled": {
+		func(ipifc *IpInterface) interface{} { return ipifc.AdvertisingEnabled },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) {
+			row.AdvertisingEnabled = boolToUint8(ipifc.AdvertisingEnabled)
+		},
+	},
+	"ForwardingEnabled": {
+		func(ipifc *IpInterface) interface{} { return ipifc.ForwardingEnabled },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) {
+			row.ForwardingEnabled = boolToUint8(ipifc.ForwardingEnabled)
+		},
+	},
+	"WeakHostSend": {
+		func(ipifc *IpInterface) interface{} { return ipifc.WeakHostSend },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) { row.WeakHostSend = boolToUint8(ipifc.WeakHostSend) },
+	},
+	"WeakHostReceive": {
+		func(ipifc *IpInterface) interface{} { return ipifc.WeakHostReceive },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) {
+			row.WeakHostReceive = boolToUint8(ipifc.WeakHostReceive)
+		},
+	},
+	"UseAutomaticMetric": {
+		func(ipifc *IpInterface) interface{} { return ipifc.UseAutomaticMetric },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) {
+			row.UseAutomaticMetric = boolToUint8(ipifc.UseAutomaticMetric)
+		},
+	},
+	"RouterDiscoveryBehavior": {
+		func(ipifc *IpInterface) interface{} { return ipifc.RouterDiscoveryBehavior },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) {
+			row.RouterDiscoveryBehavior = ipifc.RouterDiscoveryBehavior
+		},
+	},
+	"DadTransmits": {
+		func(ipifc *IpInterface) interface{} { return ipifc.DadTransmits },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) { row.DadTransmits = ipifc.DadTransmits },
+	},
+	"BaseReachableTime": {
+		func(ipifc *IpInterface) interface{} { return ipifc.BaseReachableTime },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) { row.BaseReachableTime = ipifc.BaseReachableTime },
+	},
+	"RetransmitTime": {
+		func(ipifc *IpInterface) interface{} { return ipifc.RetransmitTime },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) { row.RetransmitTime = ipifc.RetransmitTime },
+	},
+	"SitePrefixLength": {
+		func(ipifc *IpInterface) interface{} { return ipifc.SitePrefixLength },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) { row.SitePrefixLength = ipifc.SitePrefixLength },
+	},
+	"Metric": {
+		func(ipifc *IpInterface) interface{} { return ipifc.Metric },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) { row.Metric = ipifc.Metric },
+	},
+	"NlMtu": {
+		func(ipifc *IpInterface) interface{} { return ipifc.NlMtu },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) { row.NlMtu = ipifc.NlMtu },
+	},
+	"DisableDefaultRoutes": {
+		func(ipifc *IpInterface) interface{} { return ipifc.DisableDefaultRoutes },
+		func(ipifc *IpInterface, row *wtMibIpinterfaceRow) {
+			row.DisableDefaultRoutes = boolToUint8(ipifc.DisableDefaultRoutes)
+		},
+	},
+}
+
+// Builds a change of an IpInterface which touches only explicitly modified fields. Created by IpInterface.Update();
+// setters can be chained, and the change is activated by Apply(). If a setter is called more than once for the same
+// field, the last value wins.
+type IpInterfaceUpdate struct {
+	ipifc  *IpInterface
+	target IpInterface
+	fields []string
+}
+
+// Before and after values of a single IpInterface field modified by IpInterfaceUpdate.Apply().
+type IpInterfaceFieldChange struct {
+	Field  string
+	Before interface{}
+	After  interface{}
+}
+
+// Returns true if the field's value actually differs after the update.
+func (c *IpInterfaceFieldChange) Changed() bool {
+	return c.Before != c.After
+}
+
+func (c *IpInterfaceFieldChange) String() string {
+	if c == nil {
+		return "<nil>"
+	} else {
+		return fmt.Sprintf("%s: %v -> %v", c.Field, c.Before, c.After)
+	}
+}
+
+// Per-field changes reported by IpInterfaceUpdate.Apply(), in the order the fields were first modified, followed by
+// SitePrefixLength if Apply() had to reset it.
+type IpInterfaceChanges []*IpInterfaceFieldChange
+
+// Returns only the changes whose value actually differs.
+func (changes IpInterfaceChanges) Changed() IpInterfaceChanges {
+
+	result := make(IpInterfaceChanges, 0, len(changes))
+
+	for _, c := range changes {
+		if c.Changed() {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+func (changes IpInterfaceChanges) String() string {
+
+	lines := make([]string, len(changes))
+
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Starts building a change of the IP interface. Unlike Set(), only fields modified through the returned builder are
+// written, and the result of the change is reported per field.
+func (ipifc *IpInterface) Update() *IpInterfaceUpdate {
+	return &IpInterfaceUpdate{
+		ipifc:  ipifc,
+		target: *ipifc,
+	}
+}
+
+func (u *IpInterfaceUpdate) modified(field string) *IpInterfaceUpdate {
+
+	if !u.isModified(field) {
+		u.fields = append(u.fields, field)
+	}
+
+	return u
+}
+
+// Sets NlMtu.
+func (u *IpInterfaceUpdate) MTU(mtu uint32) *IpInterfaceUpdate {
+	u.target.NlMtu = mtu
+	return u.modified("NlMtu")
+}
+
+// Sets Metric. Windows ignores the metric while UseAutomaticMetric is true, so it also sets UseAutomaticMetric to
+// false (as the interface properties dialog does when a metric is entered). Calling UseAutomaticMetric(true) later in
+// the same update makes it invalid.
+func (u *IpInterfaceUpdate) Metric(metric uint32) *IpInterfaceUpdate {
+	u.target.Metric = metric
+	u.target.UseAutomaticMetric = false
+	return u.modified("Metric").modified("UseAutomaticMetric")
+}
+
+// Sets UseAutomaticMetric.
+func (u *IpInterfaceUpdate) UseAutomaticMetric(use bool) *IpInterfaceUpdate {
+	u.target.UseAutomaticMetric = use
+	return u.modified("UseAutomaticMetric")
+}
+
+// Sets DisableDefaultRoutes.
+func (u *IpInterfaceUpdate) DisableDefaultRoutes(disable bool) *IpInterfaceUpdate {
+	u.target.DisableDefaultRoutes = disable
+	return u.modified("DisableDefaultRoutes")
+}
+
+// Sets ForwardingEnabled.
+func (u *IpInterfaceUpdate) Forwarding(enabled bool) *IpInterfaceUpdate {
+	u.target.ForwardingEnabled = enabled
+	return u.modified("ForwardingEnabled")
+}
+
+// Sets AdvertisingEnabled.
+func (u *IpInterfaceUpdate) Advertising(enabled bool) *IpInterfaceUpdate {
+	u.target.AdvertisingEnabled = enabled
+	return u.modified("AdvertisingEnabled")
+}
+
+// Sets WeakHostSend.
+func (u *IpInterfaceUpdate) WeakHostSend(enabled bool) *IpInterfaceUpdate {
+	u.target.WeakHostSend = enabled
+	return u.modified("WeakHostSend")
+}
+
+// Sets WeakHostReceive.
+func (u *IpInterfaceUpdate) WeakHostReceive(enabled bool) *IpInterfaceUpdate {
+	u.target.WeakHostReceive = enabled
+	return u.modified("WeakHostReceive")
+}
+
+// Sets RouterDiscoveryBehavior.
+func (u *IpInterfaceUpdate) RouterDiscovery(behavior NlRouterDiscoveryBehavior) *IpInterfaceUpdate {
+	u.target.RouterDiscoveryBehavior = behavior
+	return u.modified("RouterDiscoveryBehavior")
+}
+
+// Sets DadTransmits.
+func (u *IpInterfaceUpdate) DadTransmits(transmits uint32) *IpInterfaceUpdate {
+	u.target.DadTransmits = transmits
+	return u.modified("DadTransmits")
+}
+
+// Sets BaseReachableTime (in ms).
+func (u *IpInterfaceUpdate) BaseReachableTime(ms uint32) *IpInterfaceUpdate {
+	u.target.BaseReachableTime = ms
+	return u.modified("BaseReachableTime")
+}
+
+// Sets RetransmitTime (in ms).
+func (u *IpInterfaceUpdate) RetransmitTime(ms uint32) *IpInterfaceUpdate {
+	u.target.RetransmitTime = ms
+	return u.modified("RetransmitTime")
+}
+
+// Sets SitePrefixLength.
+func (u *IpInterfaceUpdate) SitePrefixLength(length uint32) *IpInterfaceUpdate {
+	u.target.SitePrefixLength = length
+	return u.modified("SitePrefixLength")
+}
+
+// Returns names of the modified fields, in the order they were first modified.
+func (u *IpInterfaceUpdate) Fields() []string {
+	return append([]string(nil), u.fields...)
+}
+
+// Checks the modified fields against the interface's family and the other fields of the update. Validation doesn't
+// access the system, so it can be used before Apply() to report errors early.
+func (u *IpInterfaceUpdate) Validate() error {
+
+	family := u.target.Family
+
+	if family != AF_INET && family != AF_INET6 {
+		return newKindError(ErrInvalidParameter,
+			"IpInterfaceUpdate.Validate() - family %s has to be either AF_INET or AF_INET6",
+			family.String())
+	}
+
+	for _, field := range u.fields {
+		if err := u.validateField(field); err != nil {
+			return newKindError(ErrInvalidParameter, "IpInterfaceUpdate.Validate() - %s: %v", field, err)
+		}
+	}
+
+	return nil
+}
+
+func (u *IpInterfaceUpdate) validateField(field string) error {
+
+	t := &u.target
+
+	switch field {
+	case "NlMtu":
+		min := uint32(ipv4MinimumMtu)
+		if t.Family == AF_INET6 {
+			min = ipv6MinimumMtu
+		}
+		if t.NlMtu < min || t.NlMtu > ipMaximumMtu {
+			return fmt.Errorf("%d is out of range [%d, %d] for %s", t.NlMtu, min, ipMaximumMtu, t.Family.String())
+		}
+	case "Metric":
+		if t.UseAutomaticMetric {
+			return fmt.Errorf("metric is ignored while UseAutomaticMetric is true")
+		}
+	case "DadTransmits":
+		if t.DadTransmits > ipInterfaceMaxDadTransmits {
+			return fmt.Errorf("%d is greater than %d", t.DadTransmits, ipInterfaceMaxDadTransmits)
+		}
+	case "RouterDiscoveryBehavior":
+		switch t.RouterDiscoveryBehavior {
+		case RouterDiscoveryDisabled, RouterDiscoveryEnabled, RouterDiscoveryUnchanged:
+		case RouterDiscoveryDhcp:
+			if t.Family != AF_INET {
+				return fmt.Errorf("%s is supported only for AF_INET", t.RouterDiscoveryBehavior.String())
+			}
+		default:
+			return fmt.Errorf("invalid value %s", t.RouterDiscoveryBehavior.String())
+		}
+	case "SitePrefixLength":
+		max := uint32(32)
+		if t.Family == AF_INET6 {
+			max = 128
+		}
+		if t.SitePrefixLength > max {
+			return fmt.Errorf("%d is greater than %d for %s", t.SitePrefixLength, max, t.Family.String())
+		}
+	case "BaseReachableTime", "RetransmitTime":
+		if ipInterfaceFieldSpecs[field].get(t).(uint32) == 0 {
+			return fmt.Errorf("has to be greater than 0")
+		}
+	}
+
+	return nil
+}
+
+// Validates the update, writes the modified fields by using SetIpInterfaceEntry, re-reads the entry and returns
+// before/after values of every modified field. The IpInterface the update was created from is refreshed with the
+// re-read entry. If nothing was modified, nothing is written and no changes are returned.
+//
+// Fields which weren't modified are written back as currently read from the system, except SitePrefixLength, which
+// is reset to 0 if the system returns a value invalid for the family (otherwise SetIpInterfaceEntry fails, see
+// https://stackoverflow.com/questions/54857292/setipinterfaceentry-returns-error-invalid-parameter). Such a reset is
+// reported as a change of SitePrefixLength, following the modified fields.
+func (u *IpInterfaceUpdate) Apply() (IpInterfaceChanges, error) {
+
+	if len(u.fields) == 0 {
+		return IpInterfaceChanges{}, nil
+	}
+
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+
+	row, err := getWtMibIpinterfaceRow(u.target.InterfaceLuid, u.target.Family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	before := row.toIpInterface()
+
+	for _, field := range u.fields {
+		ipInterfaceFieldSpecs[field].apply(&u.target, row)
+	}
+
+	fields := u.fixSitePrefixLength(row)
+
+	if err := row.set(); err != nil {
+		return nil, err
+	}
+
+	after, err := GetIpInterface(u.target.InterfaceLuid, u.target.Family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	*u.ipifc = *after
+
+	return diffIpInterfaces(before, after, fields), nil
+}
+
+// Resets SitePrefixLength of 'row' to 0 if it wasn't modified and the system returned a value invalid for the family.
+// Returns the fields written by the update, i.e. the modified ones, plus SitePrefixLength if it was reset.
+func (u *IpInterfaceUpdate) fixSitePrefixLength(row *wtMibIpinterfaceRow) []string {
+
+	if u.isModified("SitePrefixLength") ||
+		(row.SitePrefixLength <= 128 && (row.SitePrefixLength <= 32 || row.Family != AF_INET)) {
+		return u.fields
+	}
+
+	row.SitePrefixLength = 0
+
+	return append(append([]string(nil), u.fields...), "SitePrefixLength")
+}
+
+func (u *IpInterfaceUpdate) isModified(field string) bool {
+
+	for _, f := range u.fields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func diffIpInterfaces(before, after *IpInterface, fields []string) IpInterfaceChanges {
+
+	changes := make(IpInterfaceChanges, len(fields))
+
+	for i, field := range fields {
+		spec := ipInterfaceFieldSpecs[field]
+		changes[i] = &IpInterfaceFieldChange{
+			Field:  field,
+			Before: spec.get(before),
+			After:  spec.get(after),
+		}
+	}
+
+	return changes
+}
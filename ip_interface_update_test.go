@@ -0,0 +1,173 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIpInterfaceUpdateFields(t *testing.T) {
+
+	ipifc := &IpInterface{Family: AF_INET, NlMtu: 1500, Metric: 25}
+
+	u := ipifc.Update().MTU(1420).Metric(5).UseAutomaticMetric(false).MTU(1400)
+
+	if fields := u.Fields(); !reflect.DeepEqual(fields, []string{"NlMtu", "Metric", "UseAutomaticMetric"}) {
+		t.Errorf("Fields() returned %v.", fields)
+	}
+
+	if u.target.NlMtu != 1400 {
+		t.Errorf("NlMtu is %d, although the last set value 1400 is expected.", u.target.NlMtu)
+	}
+
+	if ipifc.NlMtu != 1500 {
+		t.Error("Update() modified the source IpInterface before Apply().")
+	}
+}
+
+func TestIpInterfaceUpdateValidate(t *testing.T) {
+
+	v4 := &IpInterface{Family: AF_INET}
+	v6 := &IpInterface{Family: AF_INET6}
+	auto := &IpInterface{Family: AF_INET, UseAutomaticMetric: true}
+
+	tests := []struct {
+		update *IpInterfaceUpdate
+		valid  bool
+	}{
+		{v4.Update().MTU(576), true},
+		{v4.Update().MTU(575), false},
+		{v4.Update().MTU(65536), false},
+		{v6.Update().MTU(1280), true},
+		{v6.Update().MTU(1279), false},
+		{v4.Update().Metric(5), true},
+		{auto.Update().Metric(5), true},
+		{auto.Update().MTU(1420).Metric(5).DisableDefaultRoutes(true), true},
+		{auto.Update().Metric(5).UseAutomaticMetric(false), true},
+		{auto.Update().UseAutomaticMetric(false).Metric(5), true},
+		{v4.Update().Metric(5).UseAutomaticMetric(true), false},
+		{v6.Update().DadTransmits(0), true},
+		{v6.Update().DadTransmits(ipInterfaceMaxDadTransmits + 1), false},
+		{v4.Update().RouterDiscovery(RouterDiscoveryDhcp), true},
+		{v6.Update().RouterDiscovery(RouterDiscoveryDhcp), false},
+		{v6.Update().RouterDiscovery(NlRouterDiscoveryBehavior(7)), false},
+		{v4.Update().SitePrefixLength(33), false},
+		{v6.Update().SitePrefixLength(64), true},
+		{v6.Update().RetransmitTime(0), false},
+		{(&IpInterface{Family: AF_UNSPEC}).Update().Forwarding(true), false},
+	}
+
+	for i, test := range tests {
+		err := test.update.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("Test %d (%v): Validate() returned %v.", i, test.update.Fields(), err)
+		}
+	}
+}
+
+func TestIpInterfaceUpdateMetricDisablesAutomaticMetric(t *testing.T) {
+
+	auto := &IpInterface{Family: AF_INET, NlMtu: 1500, UseAutomaticMetric: true}
+
+	u := auto.Update().MTU(1420).Metric(5).DisableDefaultRoutes(true)
+
+	if err := u.Validate(); err != nil {
+		t.Fatalf("Validate() returned an error: %v", err)
+	}
+
+	if u.target.UseAutomaticMetric || u.target.Metric != 5 || !u.isModified("UseAutomaticMetric") {
+		t.Errorf("Metric() resulted in UseAutomaticMetric %v and metric %d, although false and 5 are expected.",
+			u.target.UseAutomaticMetric, u.target.Metric)
+	}
+}
+
+func TestDiffIpInterfaces(t *testing.T) {
+
+	before := &IpInterface{Family: AF_INET, NlMtu: 1500, Metric: 25, DisableDefaultRoutes: false}
+	after := &IpInterface{Family: AF_INET, NlMtu: 1420, Metric: 25, DisableDefaultRoutes: true}
+
+	changes := diffIpInterfaces(before, after, []string{"NlMtu", "Metric", "DisableDefaultRoutes"})
+
+	expected := IpInterfaceChanges{
+		{"NlMtu", uint32(1500), uint32(1420)},
+		{"Metric", uint32(25), uint32(25)},
+		{"DisableDefaultRoutes", false, true},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("diffIpInterfaces() returned:\n%s\nalthough expected:\n%s", changes, expected)
+	}
+
+	if changed := changes.Changed(); len(changed) != 2 || changed[0].Field != "NlMtu" ||
+		changed[1].Field != "DisableDefaultRoutes" {
+		t.Errorf("Changed() returned:\n%s", changed)
+	}
+
+	if s := changes[0].String(); s != "NlMtu: 1500 -> 1420" {
+		t.Errorf("String() returned %q.", s)
+	}
+}
+
+func TestIpInterfaceUpdateFixSitePrefixLength(t *testing.T) {
+
+	v4 := &IpInterface{Family: AF_INET}
+	v6 := &IpInterface{Family: AF_INET6}
+
+	tests := []struct {
+		update *IpInterfaceUpdate
+		row    wtMibIpinterfaceRow
+		length uint32
+		fields []string
+	}{
+		{v4.Update().MTU(1420), wtMibIpinterfaceRow{Family: AF_INET, SitePrefixLength: 32}, 32,
+			[]string{"NlMtu"}},
+		{v4.Update().MTU(1420), wtMibIpinterfaceRow{Family: AF_INET, SitePrefixLength: 64}, 0,
+			[]string{"NlMtu", "SitePrefixLength"}},
+		{v6.Update().MTU(1420), wtMibIpinterfaceRow{Family: AF_INET6, SitePrefixLength: 64}, 64,
+			[]string{"NlMtu"}},
+		{v6.Update().MTU(1420), wtMibIpinterfaceRow{Family: AF_INET6, SitePrefixLength: 129}, 0,
+			[]string{"NlMtu", "SitePrefixLength"}},
+		{v6.Update().SitePrefixLength(48), wtMibIpinterfaceRow{Family: AF_INET6, SitePrefixLength: 48}, 48,
+			[]string{"SitePrefixLength"}},
+	}
+
+	for i, test := range tests {
+
+		fields := test.update.fixSitePrefixLength(&test.row)
+
+		if test.row.SitePrefixLength != test.length || !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("fixSitePrefixLength() #%d resulted in length %d and fields %v, although %d and %v are "+
+				"expected.", i, test.row.SitePrefixLength, fields, test.length, test.fields)
+		}
+
+		if len(test.update.Fields()) != 1 {
+			t.Errorf("fixSitePrefixLength() #%d changed the update's fields to %v.", i, test.update.Fields())
+		}
+	}
+}
+
+func TestIpInterfaceUpdateApply(t *testing.T) {
+
+	ipifc, err := GetIpInterface(existingLuid, AF_INET)
+
+	if err != nil {
+		t.Errorf("GetIpInterface() returned an error: %v", err)
+		return
+	}
+
+	// Writing the current value back is harmless, and still exercises the whole path.
+	changes, err := ipifc.Update().WeakHostSend(ipifc.WeakHostSend).Apply()
+
+	if err != nil {
+		t.Errorf("IpInterfaceUpdate.Apply() returned an error: %v", err)
+		return
+	}
+
+	if len(changes) != 1 || changes[0].Changed() {
+		t.Errorf("IpInterfaceUpdate.Apply() returned unexpected changes:\n%s", changes)
+	}
+}
@@ -0,0 +1,94 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "fmt"
+
+// Corresponds to MIB_IPSTATS_LH defined in ipmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/ipmib/ns-ipmib-mib_ipstats_lh).
+type IpStatistics struct {
+	Family AddressFamily
+
+	// Whether IP forwarding is enabled.
+	Forwarding bool
+	DefaultTTL uint32
+
+	InReceives      uint32
+	InHdrErrors     uint32
+	InAddrErrors    uint32
+	ForwDatagrams   uint32
+	InUnknownProtos uint32
+	InDiscards      uint32
+	InDelivers      uint32
+	OutRequests     uint32
+	RoutingDiscards uint32
+	OutDiscards     uint32
+	OutNoRoutes     uint32
+
+	// Reassembly.
+	ReasmTimeout uint32
+	ReasmReqds   uint32
+	ReasmOks     uint32
+	ReasmFails   uint32
+
+	// Fragmentation.
+	FragOks     uint32
+	FragFails   uint32
+	FragCreates uint32
+
+	NumIf     uint32
+	NumAddr   uint32
+	NumRoutes uint32
+}
+
+// Returns IP statistics for the specified address family, which has to be either AF_INET or AF_INET6. Corresponds to
+// GetIpStatisticsEx function (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getipstatisticsex).
+func GetIpStatistics(family AddressFamily) (*IpStatistics, error) {
+
+	stats, err := getWtMibIpstats(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.toIpStatistics(family), nil
+}
+
+func (stats *IpStatistics) String() string {
+
+	if stats == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf(`Family: %s
+Forwarding: %v
+DefaultTTL: %d
+InReceives: %d
+InHdrErrors: %d
+InAddrErrors: %d
+ForwDatagrams: %d
+InUnknownProtos: %d
+InDiscards: %d
+InDelivers: %d
+OutRequests: %d
+RoutingDiscards: %d
+OutDiscards: %d
+OutNoRoutes: %d
+ReasmTimeout: %d
+ReasmReqds: %d
+ReasmOks: %d
+ReasmFails: %d
+FragOks: %d
+FragFails: %d
+FragCreates: %d
+NumIf: %d
+NumAddr: %d
+NumRoutes: %d`, stats.Family.String(), stats.Forwarding, stats.DefaultTTL, stats.InReceives, stats.InHdrErrors,
+		stats.InAddrErrors, stats.ForwDatagrams, stats.InUnknownProtos, stats.InDiscards, stats.InDelivers,
+		stats.OutRequests, stats.RoutingDiscards, stats.OutDiscards, stats.OutNoRoutes, stats.ReasmTimeout,
+		stats.ReasmReqds, stats.ReasmOks, stats.ReasmFails, stats.FragOks, stats.FragFails, stats.FragCreates,
+		stats.NumIf, stats.NumAddr, stats.NumRoutes)
+}
@@ -0,0 +1,46 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"testing"
+)
+
+const ipStatistics_print = false
+
+func TestGetIpStatistics(t *testing.T) {
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+
+		stats, err := GetIpStatistics(family)
+
+		if err != nil {
+			t.Errorf("GetIpStatistics(%s) returned an error: %v", family.String(), err)
+			continue
+		}
+
+		if stats == nil || stats.Family != family {
+			t.Errorf("GetIpStatistics(%s) returned unexpected result: %v", family.String(), stats)
+			continue
+		}
+
+		if ipStatistics_print {
+			fmt.Println("======================= IP STATISTICS OUTPUT START =======================")
+			fmt.Println(stats)
+			fmt.Println("======================== IP STATISTICS OUTPUT END ========================")
+		}
+	}
+}
+
+func TestGetIpStatisticsInvalidFamily(t *testing.T) {
+
+	_, err := GetIpStatistics(AF_UNSPEC)
+
+	if err == nil {
+		t.Error("GetIpStatistics(AF_UNSPEC) didn't return an error.")
+	}
+}
@@ -0,0 +1,105 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+)
+
+// Corresponds to MIB_MULTICASTIPADDRESS_ROW defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-_mib_multicastipaddress_row).
+// Each row represents membership of the interface in a multicast group.
+type MulticastIpAddressRow struct {
+	//
+	// Key Structure.
+	//
+	Address        SockaddrInet
+	InterfaceLuid  uint64
+	InterfaceIndex uint32
+
+	//
+	// Read-Only Fields.
+	//
+	ScopeId uint32
+}
+
+// Returns all multicast IP addresses from the system. GetMulticastIpAddressTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getmulticastipaddresstable).
+func GetMulticastIpAddressRows(family AddressFamily) ([]*MulticastIpAddressRow, error) {
+
+	rows, err := getWtMibMulticastipaddressRows(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	length := len(rows)
+
+	addresses := make([]*MulticastIpAddressRow, length, length)
+
+	for idx, row := range rows {
+
+		address, err := row.toMulticastIpAddressRow()
+
+		if err != nil {
+			return nil, err
+		}
+
+		addresses[idx] = address
+	}
+
+	return addresses, nil
+}
+
+// Returns multicast IP address specified by the input criteria. Corresponds to GetMulticastIpAddressEntry function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getmulticastipaddressentry).
+func GetMulticastIpAddressRow(interfaceLuid uint64, ip *net.IP) (*MulticastIpAddressRow, error) {
+
+	row, err := getWtMibMulticastipaddressRowAlt(interfaceLuid, ip)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toMulticastIpAddressRow()
+}
+
+// Returns multicast groups the interface is a member of.
+func (ifc *Interface) GetMulticastIpAddressRows(family AddressFamily) ([]*MulticastIpAddressRow, error) {
+
+	rows, err := GetMulticastIpAddressRows(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return filterMulticastIpAddressRows(rows, ifc.Luid), nil
+}
+
+func filterMulticastIpAddressRows(rows []*MulticastIpAddressRow, interfaceLuid uint64) []*MulticastIpAddressRow {
+
+	result := make([]*MulticastIpAddressRow, 0)
+
+	for _, row := range rows {
+		if row.InterfaceLuid == interfaceLuid {
+			result = append(result, row)
+		}
+	}
+
+	return result
+}
+
+func (mia *MulticastIpAddressRow) String() string {
+	if mia == nil {
+		return "nil"
+	} else {
+		return fmt.Sprintf(`Address: %s
+InterfaceLuid: %d
+InterfaceIndex: %d
+ScopeId: %d`, mia.Address.String(), mia.InterfaceLuid, mia.InterfaceIndex, mia.ScopeId)
+	}
+}
@@ -0,0 +1,84 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"testing"
+)
+
+const multicastIpAddressRow_print = false
+
+func TestGetMulticastIpAddressRows(t *testing.T) {
+
+	addresses, err := GetMulticastIpAddressRows(AF_UNSPEC)
+
+	if err != nil {
+		t.Errorf("GetMulticastIpAddressRows() returned an error: %v", err)
+		return
+	}
+
+	for _, address := range addresses {
+		if !address.Address.Address.IsMulticast() {
+			t.Errorf("GetMulticastIpAddressRows() returned non-multicast address %s.", address.Address.String())
+		}
+	}
+
+	if multicastIpAddressRow_print {
+		for _, address := range addresses {
+			fmt.Println("==================== MULTICAST ADDRESS OUTPUT START ====================")
+			fmt.Println(address)
+			fmt.Println("===================== MULTICAST ADDRESS OUTPUT END =====================")
+		}
+	}
+}
+
+func TestGetMulticastIpAddressRow(t *testing.T) {
+
+	addresses, err := GetMulticastIpAddressRows(AF_UNSPEC)
+
+	if err != nil {
+		t.Errorf("GetMulticastIpAddressRows() returned an error: %v", err)
+		return
+	}
+
+	if len(addresses) == 0 {
+		return
+	}
+
+	expected := addresses[0]
+
+	row, err := GetMulticastIpAddressRow(expected.InterfaceLuid, &expected.Address.Address)
+
+	if err != nil {
+		t.Errorf("GetMulticastIpAddressRow() returned an error: %v", err)
+	} else if row.InterfaceIndex != expected.InterfaceIndex || !row.Address.Address.Equal(expected.Address.Address) {
+		t.Errorf("GetMulticastIpAddressRow() returned %v, although %v is expected.", row, expected)
+	}
+}
+
+func TestInterfaceGetMulticastIpAddressRows(t *testing.T) {
+
+	ifc, err := InterfaceFromLUID(existingLuid)
+
+	if err != nil {
+		t.Errorf("InterfaceFromLUID() returned an error: %v", err)
+		return
+	}
+
+	addresses, err := ifc.GetMulticastIpAddressRows(AF_UNSPEC)
+
+	if err != nil {
+		t.Errorf("Interface.GetMulticastIpAddressRows() returned an error: %v", err)
+		return
+	}
+
+	for _, address := range addresses {
+		if address.InterfaceLuid != existingLuid {
+			t.Errorf("Interface.GetMulticastIpAddressRows() returned a row with LUID %d.", address.InterfaceLuid)
+		}
+	}
+}
@@ -34,19 +34,21 @@ func runNetsh(cmds []string) error {
 	if err != nil {
 		return errors.New(fmt.Sprintf("runNetsh stdin pipe - %v", err))
 	}
+	written := make(chan error, 1)
 	go func() {
 		defer stdin.Close()
 		encoder := simplifiedchinese.GB18030.NewEncoder()
 		transformedInput := transform.NewWriter(stdin, encoder)
 		_, writeErr := transformedInput.Write([]byte(strings.Join(append(cmds, "exit\r\n"), "\r\n")))
-		if writeErr != nil {
-			fmt.Println(writeErr)
-		}
+		written <- writeErr
 	}()
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return errors.New(fmt.Sprintf("runNetsh run - %v", err))
 	}
+	if writeErr := <-written; writeErr != nil {
+		return fmt.Errorf("runNetsh writing commands - %w", writeErr)
+	}
 	// Horrible kludges, sorry.
 	cleaned := bytes.ReplaceAll(output, []byte("netsh>"), []byte{})
 	cleaned = bytes.ReplaceAll(cleaned, []byte("There are no Domain Name Servers (DNS) configured on this computer."), []byte{})
@@ -77,6 +79,7 @@ func runNetshResult(cmds []string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("runNetshResult stdin pipe - %w", err)
 	}
+	written := make(chan error, 1)
 	go func() {
 		defer stdin.Close()
 		// 如果有中文，需要设置编码
@@ -84,15 +87,15 @@ func runNetshResult(cmds []string) (string, error) {
 		encoder := simplifiedchinese.GB18030.NewEncoder()
 		transformedInput := transform.NewWriter(stdin, encoder)
 		_, writeErr := transformedInput.Write([]byte(strings.Join(append(cmds, "exit\r\n"), "\r\n")))
-		if writeErr != nil {
-			fmt.Println(writeErr)
-		}
+		written <- writeErr
 		//io.WriteString(stdin, strings.Join(append(cmds, "exit\r\n"), "\r\n"))
 	}()
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		fmt.Println(ConvertByte2String(output, GB18030))
-		return "", fmt.Errorf("runNetshResult run - %w", err)
+		return "", fmt.Errorf("runNetshResult run - %w; output:\n%s", err, ConvertByte2String(output, GB18030))
+	}
+	if writeErr := <-written; writeErr != nil {
+		return "", fmt.Errorf("runNetshResult writing commands - %w", writeErr)
 	}
 	// Horrible kludges, sorry.
 	cleaned := bytes.ReplaceAll(output, []byte("netsh>"), []byte{})
@@ -128,22 +131,70 @@ func RunNetsh(cmds []string) (string, error) {
 }
 
 const (
-	netshCmdTemplateEnablingInterface  = "interface set interface %s admin=enable"
-	netshCmdTemplateDisablingInterface = "interface set interface %s admin=disable"
-	netshCmdTemplateStatusInterface    = "interface show interface %s"
+	netshCmdTemplateSetInterfaceAdmin = "interface set interface name=%s admin=%s"
+	netshCmdTemplateStatusInterface   = "interface show interface name=%s"
 )
 
-// 开启网卡
+// Quotes an argument for a netsh command line. netsh has no escape sequences, so arguments containing double quotes
+// can't be passed at all, and line breaks would start a new command, so both are rejected.
+func netshQuote(arg string) (string, error) {
+	if arg == "" || strings.ContainsAny(arg, "\"\r\n\x00") {
+		return "", fmt.Errorf("netshQuote() - argument %q can't be passed to netsh", arg)
+	}
+	return `"` + arg + `"`, nil
+}
+
+func netshSetInterfaceAdminCmd(interfaceName string, enable bool) (string, error) {
+	name, err := netshQuote(interfaceName)
+	if err != nil {
+		return "", err
+	}
+	admin := "disable"
+	if enable {
+		admin = "enable"
+	}
+	return fmt.Sprintf(netshCmdTemplateSetInterfaceAdmin, name, admin), nil
+}
+
+func netshShowInterfaceCmd(interfaceName string) (string, error) {
+	name, err := netshQuote(interfaceName)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(netshCmdTemplateStatusInterface, name), nil
+}
+
+// Returns netsh arguments renaming an interface. They're passed on the command line rather than through stdin (as
+// runNetsh() does), since stdin is GB18030 encoded, which only fits ASCII and Chinese names; the command line is
+// UTF-16. Each argument is a single command line argument, so names can't inject further ones.
+func netshRenameInterfaceArgs(oldName, newName string) ([]string, error) {
+	for _, name := range []string{oldName, newName} {
+		if name == "" || strings.ContainsAny(name, "\"\r\n\x00") {
+			return nil, fmt.Errorf("netshRenameInterfaceArgs() - name %q can't be passed to netsh", name)
+		}
+	}
+	return []string{"interface", "set", "interface", "name=" + oldName, "newname=" + newName}, nil
+}
+
+// 开启网卡. Prefer EnableInterface(), which doesn't depend on netsh.
 func EnablingInterface(interfaceName string) error {
-	//netsh interface set interface "StarVPN" admin=enable
-	_, err := runNetshResult([]string{fmt.Sprintf(netshCmdTemplateEnablingInterface, interfaceName)})
+	//netsh interface set interface name="StarVPN" admin=enable
+	cmd, err := netshSetInterfaceAdminCmd(interfaceName, true)
+	if err != nil {
+		return err
+	}
+	_, err = runNetshResult([]string{cmd})
 	return err
 }
 
-// 禁用网卡
+// 禁用网卡. Prefer DisableInterface(), which doesn't depend on netsh.
 func DisablingInterface(interfaceName string) error {
-	//netsh interface set interface "StarVPN" admin=disable
-	_, err := runNetshResult([]string{fmt.Sprintf(netshCmdTemplateDisablingInterface, interfaceName)})
+	//netsh interface set interface name="StarVPN" admin=disable
+	cmd, err := netshSetInterfaceAdminCmd(interfaceName, false)
+	if err != nil {
+		return err
+	}
+	_, err = runNetshResult([]string{cmd})
 	return err
 }
 
@@ -162,8 +213,12 @@ var (
 
 // 查看网卡状态 0-
 func FindInterfaceStatus(interfaceName string) (InterfaceStatus, error) {
-	//netsh interface show interface "StarVPN"
-	result, err := runNetshResult([]string{fmt.Sprintf(netshCmdTemplateStatusInterface, interfaceName)})
+	//netsh interface show interface name="StarVPN"
+	cmd, err := netshShowInterfaceCmd(interfaceName)
+	if err != nil {
+		return INTERFACE_STATUS_UNKNOWN, err
+	}
+	result, err := runNetshResult([]string{cmd})
 	if err != nil {
 		return INTERFACE_STATUS_UNKNOWN, err
 	}
@@ -191,14 +246,18 @@ func FindInterfaceStatus(interfaceName string) (InterfaceStatus, error) {
 	return INTERFACE_STATUS_ENABLED, nil
 }
 
-// 修改网卡名称
+// 修改网卡名称. Prefer SetInterfaceAlias(), which doesn't depend on netsh.
 func RenameInterface(oldName, newName string) error {
 	netshExe, err := getNetshPath()
 	if err != nil {
 		return fmt.Errorf("getNetshPath - %w", err)
 	}
 	//netsh interface set interface name=本地连接 newname=123
-	c := exec.Command(netshExe, "interface", "set", "interface", "name="+oldName, "newname="+newName)
+	args, err := netshRenameInterfaceArgs(oldName, newName)
+	if err != nil {
+		return err
+	}
+	c := exec.Command(netshExe, args...)
 	c.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
 	return c.Run()
 }
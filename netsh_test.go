@@ -2,6 +2,7 @@ package winipcfg
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -23,3 +24,60 @@ func TestRenameInterface(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestNetshInterfaceCmds(t *testing.T) {
+
+	tests := []struct {
+		actual   func() (string, error)
+		expected string
+	}{
+		{func() (string, error) { return netshSetInterfaceAdminCmd("StarVPN", true) },
+			`interface set interface name="StarVPN" admin=enable`},
+		{func() (string, error) { return netshSetInterfaceAdminCmd("Local Area Connection 2", false) },
+			`interface set interface name="Local Area Connection 2" admin=disable`},
+		{func() (string, error) { return netshShowInterfaceCmd("以太网 2") },
+			`interface show interface name="以太网 2"`},
+	}
+
+	for _, test := range tests {
+		cmd, err := test.actual()
+		if err != nil {
+			t.Errorf("Generating %q returned an error: %v", test.expected, err)
+		} else if cmd != test.expected {
+			t.Errorf("Generated command is %q, although %q is expected.", cmd, test.expected)
+		}
+	}
+}
+
+func TestNetshInterfaceCmdsRejectInjection(t *testing.T) {
+
+	for _, name := range []string{
+		"",
+		`evil" admin=disable`,
+		"evil\r\nadvfirewall set allprofiles state off",
+		"evil\nexit",
+		"evil\x00",
+	} {
+		if cmd, err := netshSetInterfaceAdminCmd(name, true); err == nil {
+			t.Errorf("netshSetInterfaceAdminCmd(%q) returned %q, although an error is expected.", name, cmd)
+		}
+		if args, err := netshRenameInterfaceArgs("StarVPN", name); err == nil {
+			t.Errorf("netshRenameInterfaceArgs(%q) returned %q, although an error is expected.", name, args)
+		}
+	}
+}
+
+func TestNetshRenameInterfaceArgs(t *testing.T) {
+
+	args, err := netshRenameInterfaceArgs("本地 连接", "Ethernet ü")
+
+	if err != nil {
+		t.Fatalf("netshRenameInterfaceArgs() returned an error: %v", err)
+	}
+
+	expected := []string{"interface", "set", "interface", "name=本地 连接", "newname=Ethernet ü"}
+
+	if strings.Join(args, "|") != strings.Join(expected, "|") {
+		t.Errorf("netshRenameInterfaceArgs() returned %q, although %q is expected.", args, expected)
+	}
+}
@@ -0,0 +1,564 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/starvpn/winipcfg-go/internal/notification"
+)
+
+// Kind of a NetworkChangeEvent.
+type NetworkChangeKind uint32
+
+const (
+	// The primary default route of a family changed (appeared, disappeared, or moved to another interface or next
+	// hop).
+	NetworkChangePrimaryRoute NetworkChangeKind = iota
+	// A watched interface went up (or appeared being up).
+	NetworkChangeInterfaceUp
+	// A watched interface went down (or disappeared while being up).
+	NetworkChangeInterfaceDown
+	// A watched interface gained an address.
+	NetworkChangeAddressAdded
+	// A watched interface lost an address.
+	NetworkChangeAddressRemoved
+	// DNS servers of a watched interface changed.
+	NetworkChangeDnsServers
+)
+
+func (kind NetworkChangeKind) String() string {
+	switch kind {
+	case NetworkChangePrimaryRoute:
+		return "NetworkChangePrimaryRoute"
+	case NetworkChangeInterfaceUp:
+		return "NetworkChangeInterfaceUp"
+	case NetworkChangeInterfaceDown:
+		return "NetworkChangeInterfaceDown"
+	case NetworkChangeAddressAdded:
+		return "NetworkChangeAddressAdded"
+	case NetworkChangeAddressRemoved:
+		return "NetworkChangeAddressRemoved"
+	case NetworkChangeDnsServers:
+		return "NetworkChangeDnsServers"
+	default:
+		return fmt.Sprintf("NetworkChangeKind_UNKNOWN(%d)", kind)
+	}
+}
+
+// Semantic network change. Besides Kind, only the fields relevant to the kind are set.
+type NetworkChangeEvent struct {
+	Kind NetworkChangeKind
+	// Family of the primary route or of the address.
+	Family AddressFamily
+	// Interface the change relates to; zero for NetworkChangePrimaryRoute.
+	InterfaceLuid uint64
+
+	// NetworkChangePrimaryRoute: the primary route before and after the change; either may be nil.
+	BeforeRoute *Route
+	AfterRoute  *Route
+
+	// NetworkChangeInterfaceUp and NetworkChangeInterfaceDown.
+	BeforeUp bool
+	AfterUp  bool
+
+	// NetworkChangeAddressAdded and NetworkChangeAddressRemoved: the address, and all addresses of the interface
+	// before and after the change.
+	Address         net.IP
+	BeforeAddresses []net.IP
+	AfterAddresses  []net.IP
+
+	// NetworkChangeDnsServers.
+	BeforeDnsServers []net.IP
+	AfterDnsServers  []net.IP
+}
+
+func (event *NetworkChangeEvent) String() string {
+
+	if event == nil {
+		return "<nil>"
+	}
+
+	switch event.Kind {
+	case NetworkChangePrimaryRoute:
+		return fmt.Sprintf("%s %s: %s -> %s", event.Kind.String(), event.Family.String(),
+			primaryRouteString(event.BeforeRoute), primaryRouteString(event.AfterRoute))
+	case NetworkChangeAddressAdded, NetworkChangeAddressRemoved:
+		return fmt.Sprintf("%s: %s on interface %d", event.Kind.String(), event.Address.String(),
+			event.InterfaceLuid)
+	case NetworkChangeDnsServers:
+		return fmt.Sprintf("%s on interface %d: %v -> %v", event.Kind.String(), event.InterfaceLuid,
+			event.BeforeDnsServers, event.AfterDnsServers)
+	default:
+		return fmt.Sprintf("%s: interface %d", event.Kind.String(), event.InterfaceLuid)
+	}
+}
+
+func primaryRouteString(route *Route) string {
+
+	if route == nil {
+		return "none"
+	}
+
+	return fmt.Sprintf("interface %d via %s", route.InterfaceLuid, route.NextHop.Address.String())
+}
+
+type NetworkChangeMonitorConfig struct {
+	// Events are reported once no raw notification arrived for this long. Defaults to 500ms.
+	Debounce time.Duration
+	// Events are reported at the latest this long after the first raw notification of a burst, even if
+	// notifications keep coming. Defaults to 5s.
+	MaxDelay time.Duration
+	// If not empty, interface, address and DNS events are reported only for these interfaces. Primary route events
+	// are always reported.
+	WatchedInterfaces []uint64
+}
+
+const (
+	defaultNetworkChangeDebounce = 500 * time.Millisecond
+	defaultNetworkChangeMaxDelay = 5 * time.Second
+)
+
+// Time source of NetworkChangeMonitor, replaced in tests.
+type monitorClock interface {
+	Now() time.Time
+	// Calls f in its own goroutine after d. The returned function cancels the call.
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// Turns bursts of raw route, address and interface notifications into semantic NetworkChangeEvents. After each burst
+// settles, the network state is read and compared with the previously read one. DNS server changes aren't notified by
+// the OS, so registry keys holding them are watched as another source of raw notifications. If reading the state
+// fails, it's retried after MaxDelay.
+type NetworkChangeMonitor struct {
+	debounce time.Duration
+	maxDelay time.Duration
+	watched  map[uint64]bool
+	callback func(events []*NetworkChangeEvent)
+	onError  func(err error)
+	clock    monitorClock
+	getState func() (*NetworkState, error)
+
+	// Protects fields below.
+	mutex        sync.Mutex
+	closed       bool
+	burstStarted time.Time
+	stopTimer    func() bool
+	// Incremented on every raw notification, so timers which fired late can tell they're stale.
+	generation uint64
+
+	// Locked during state reads and callback calls, serializing them.
+	fireGuard notification.CallbackGuard
+
+	stateMutex sync.Mutex
+	state      *NetworkState
+
+	unicastAddressCallback *UnicastAddressChangeCallback
+	interfaceCallback      *InterfaceChangeCallback
+	routeCallback          *RouteChangeCallback
+	// Stops watching DNS server changes; nil if they aren't watched.
+	stopDnsWatch func() error
+}
+
+// Starts monitoring raw notifications from 'source' (typically SystemNotificationSource). 'callback' receives events
+// of each settled burst which changed something, in order, from a single goroutine at a time. 'onError' (may be nil)
+// receives errors of reading the network state. 'config' may be nil.
+func NewNetworkChangeMonitor(source NotificationSource, config *NetworkChangeMonitorConfig,
+	callback func(events []*NetworkChangeEvent), onError func(err error)) (*NetworkChangeMonitor, error) {
+	return newNetworkChangeMonitor(source, config, callback, onError, systemClock{}, GetNetworkState,
+		watchDnsServerChanges)
+}
+
+// 'watchDns' (may be nil) is the source of DNS server change notifications.
+func newNetworkChangeMonitor(source NotificationSource, config *NetworkChangeMonitorConfig,
+	callback func(events []*NetworkChangeEvent), onError func(err error), clock monitorClock,
+	getState func() (*NetworkState, error), watchDns func(changed func(),
+		onError func(err error)) (stop func() error, err error)) (*NetworkChangeMonitor, error) {
+
+	if config == nil {
+		config = &NetworkChangeMonitorConfig{}
+	}
+
+	if config.Debounce < 0 || config.MaxDelay < 0 {
+		return nil, newKindError(ErrInvalidParameter, "NewNetworkChangeMonitor() - Debounce and MaxDelay can't be negative")
+	}
+
+	m := &NetworkChangeMonitor{
+		debounce: config.Debounce,
+		maxDelay: config.MaxDelay,
+		callback: callback,
+		onError:  onError,
+		clock:    clock,
+		getState: getState,
+	}
+
+	if m.debounce == 0 {
+		m.debounce = defaultNetworkChangeDebounce
+	}
+
+	if m.maxDelay == 0 {
+		m.maxDelay = defaultNetworkChangeMaxDelay
+	}
+
+	if len(config.WatchedInterfaces) > 0 {
+		m.watched = make(map[uint64]bool, len(config.WatchedInterfaces))
+		for _, luid := range config.WatchedInterfaces {
+			m.watched[luid] = true
+		}
+	}
+
+	state, err := getState()
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.state = state
+
+	m.unicastAddressCallback, err = source.RegisterUnicastAddressChangeCallbackEx(nil,
+		func(MibNotificationType, uint64, *net.IP) { m.notify() })
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.interfaceCallback, err = source.RegisterInterfaceChangeCallbackEx(nil,
+		func(MibNotificationType, uint64) { m.notify() })
+
+	if err != nil {
+		_ = m.unicastAddressCallback.Unregister()
+		return nil, err
+	}
+
+	m.routeCallback, err = source.RegisterRouteChangeCallbackEx(nil, func(MibNotificationType, *Route) { m.notify() })
+
+	if err != nil {
+		_ = m.unicastAddressCallback.Unregister()
+		_ = m.interfaceCallback.Unregister()
+		return nil, err
+	}
+
+	if watchDns != nil {
+
+		m.stopDnsWatch, err = watchDns(m.notify, onError)
+
+		if err != nil {
+			_ = m.unicastAddressCallback.Unregister()
+			_ = m.interfaceCallback.Unregister()
+			_ = m.routeCallback.Unregister()
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// (Re)starts the debounce timer, without postponing it past maxDelay since the burst started.
+func (m *NetworkChangeMonitor) notify() {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return
+	}
+
+	now := m.clock.Now()
+
+	if m.stopTimer == nil {
+		m.burstStarted = now
+	} else {
+		m.stopTimer()
+	}
+
+	delay := m.debounce
+
+	if remaining := m.burstStarted.Add(m.maxDelay).Sub(now); remaining < delay {
+		delay = remaining
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	m.generation++
+	generation := m.generation
+
+	m.stopTimer = m.clock.AfterFunc(delay, func() { m.fire(generation) })
+}
+
+func (m *NetworkChangeMonitor) fire(generation uint64) {
+
+	m.fireGuard.Lock()
+	defer m.fireGuard.Unlock()
+
+	m.mutex.Lock()
+
+	if m.closed || generation != m.generation {
+		m.mutex.Unlock()
+		return
+	}
+
+	m.stopTimer = nil
+	m.mutex.Unlock()
+
+	state, err := m.getState()
+
+	if err != nil {
+		if m.onError != nil {
+			m.onError(err)
+		}
+		m.retry(generation)
+		return
+	}
+
+	m.stateMutex.Lock()
+	events := diffNetworkStates(m.state, state, m.watched)
+	m.state = state
+	m.stateMutex.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	m.fireGuard.Call(func() { m.callback(events) })
+}
+
+// Schedules reading the state again after a failed attempt, unless a new notification has already scheduled it.
+func (m *NetworkChangeMonitor) retry(generation uint64) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed || generation != m.generation || m.stopTimer != nil {
+		return
+	}
+
+	m.burstStarted = m.clock.Now()
+	m.stopTimer = m.clock.AfterFunc(m.maxDelay, func() { m.fire(generation) })
+}
+
+// Returns the network state as of the last reported events.
+func (m *NetworkChangeMonitor) State() *NetworkState {
+
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.state
+}
+
+// Stops monitoring, waiting for the running state read and callback call (if any) to complete, so the callback won't be
+// called after Close returns. Called from the callback itself, Close doesn't wait for it.
+func (m *NetworkChangeMonitor) Close() error {
+
+	m.mutex.Lock()
+
+	if m.closed {
+		m.mutex.Unlock()
+		return nil
+	}
+
+	m.closed = true
+
+	if m.stopTimer != nil {
+		m.stopTimer()
+		m.stopTimer = nil
+	}
+
+	m.mutex.Unlock()
+
+	var errs []error
+
+	for _, unregister := range []func() error{
+		m.unicastAddressCallback.Unregister,
+		m.interfaceCallback.Unregister,
+		m.routeCallback.Unregister,
+		m.stopDnsWatch,
+	} {
+		if unregister == nil {
+			continue
+		}
+		if err := unregister(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	m.fireGuard.Wait()
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return multiError(errs)
+	}
+}
+
+// Returns events turning 'before' into 'after': primary route changes first (IPv4, then IPv6), followed by changes of
+// watched interfaces (all if 'watched' is nil) ordered by LUID.
+func diffNetworkStates(before, after *NetworkState, watched map[uint64]bool) []*NetworkChangeEvent {
+
+	if before == nil {
+		before = &NetworkState{}
+	}
+
+	if after == nil {
+		after = &NetworkState{}
+	}
+
+	var events []*NetworkChangeEvent
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+
+		beforeRoute := before.PrimaryRoutes[family]
+		afterRoute := after.PrimaryRoutes[family]
+
+		if !samePrimaryRoute(beforeRoute, afterRoute) {
+			events = append(events, &NetworkChangeEvent{
+				Kind:        NetworkChangePrimaryRoute,
+				Family:      family,
+				BeforeRoute: beforeRoute,
+				AfterRoute:  afterRoute,
+			})
+		}
+	}
+
+	luids := make([]uint64, 0, len(before.Interfaces)+len(after.Interfaces))
+	seen := make(map[uint64]bool)
+
+	for _, interfaces := range []map[uint64]*InterfaceNetworkState{before.Interfaces, after.Interfaces} {
+		for luid := range interfaces {
+			if !seen[luid] && (watched == nil || watched[luid]) {
+				seen[luid] = true
+				luids = append(luids, luid)
+			}
+		}
+	}
+
+	sort.Slice(luids, func(i, j int) bool { return luids[i] < luids[j] })
+
+	for _, luid := range luids {
+		events = append(events, diffInterfaceNetworkStates(luid, before.Interfaces[luid], after.Interfaces[luid])...)
+	}
+
+	return events
+}
+
+// Either state may be nil, if the interface didn't exist before or doesn't exist after the change.
+func diffInterfaceNetworkStates(luid uint64, before, after *InterfaceNetworkState) []*NetworkChangeEvent {
+
+	if before == nil {
+		before = &InterfaceNetworkState{InterfaceLuid: luid}
+	}
+
+	if after == nil {
+		after = &InterfaceNetworkState{InterfaceLuid: luid}
+	}
+
+	var events []*NetworkChangeEvent
+
+	if before.Up != after.Up {
+
+		kind := NetworkChangeInterfaceDown
+
+		if after.Up {
+			kind = NetworkChangeInterfaceUp
+		}
+
+		events = append(events, &NetworkChangeEvent{Kind: kind, InterfaceLuid: luid, BeforeUp: before.Up,
+			AfterUp: after.Up})
+	}
+
+	for _, address := range before.Addresses {
+		if !containsIP(after.Addresses, address) {
+			events = append(events, &NetworkChangeEvent{
+				Kind:            NetworkChangeAddressRemoved,
+				Family:          ipFamily(address),
+				InterfaceLuid:   luid,
+				Address:         address,
+				BeforeAddresses: before.Addresses,
+				AfterAddresses:  after.Addresses,
+			})
+		}
+	}
+
+	for _, address := range after.Addresses {
+		if !containsIP(before.Addresses, address) {
+			events = append(events, &NetworkChangeEvent{
+				Kind:            NetworkChangeAddressAdded,
+				Family:          ipFamily(address),
+				InterfaceLuid:   luid,
+				Address:         address,
+				BeforeAddresses: before.Addresses,
+				AfterAddresses:  after.Addresses,
+			})
+		}
+	}
+
+	if !sameIPs(before.DnsServers, after.DnsServers) {
+		events = append(events, &NetworkChangeEvent{
+			Kind:             NetworkChangeDnsServers,
+			InterfaceLuid:    luid,
+			BeforeDnsServers: before.DnsServers,
+			AfterDnsServers:  after.DnsServers,
+		})
+	}
+
+	return events
+}
+
+// Primary routes are considered the same if they go through the same interface and next hop; metric changes alone
+// aren't reported.
+func samePrimaryRoute(a, b *Route) bool {
+
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.InterfaceLuid == b.InterfaceLuid && a.NextHop.Address.Equal(b.NextHop.Address)
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+
+	for _, other := range ips {
+		if other.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DNS server order matters (it's the order they're queried in).
+func sameIPs(a, b []net.IP) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
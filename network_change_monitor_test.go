@@ -0,0 +1,487 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// monitorClock whose timers fire synchronously from Advance().
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (c *fakeClock) Now() time.Time {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) func() bool {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	timer := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+
+	return func() bool {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		wasPending := !timer.stopped
+		timer.stopped = true
+		return wasPending
+	}
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+
+	var due []*fakeTimer
+	pending := c.timers[:0]
+
+	for _, timer := range c.timers {
+		if timer.stopped {
+			continue
+		}
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.stopped = true
+		due = append(due, timer)
+	}
+
+	c.timers = pending
+	c.mutex.Unlock()
+
+	for _, timer := range due {
+		timer.f()
+	}
+}
+
+// Collects events and feeds the monitor with scripted network states.
+type monitorFixture struct {
+	t        *testing.T
+	clock    *fakeClock
+	replayer *NotificationReplayer
+	monitor  *NetworkChangeMonitor
+
+	mutex  sync.Mutex
+	state  *NetworkState
+	reads  int
+	events [][]*NetworkChangeEvent
+	// Number of upcoming state reads which fail.
+	failures int
+	errors   []error
+	// Simulates a DNS server change.
+	dnsChanged func()
+	// If not nil, called by the monitor's callback after collecting events.
+	onEvents func()
+}
+
+func newMonitorFixture(t *testing.T, config *NetworkChangeMonitorConfig, initial *NetworkState) *monitorFixture {
+
+	f := &monitorFixture{
+		t:     t,
+		clock: &fakeClock{now: replayStart},
+		state: initial,
+		replayer: NewNotificationReplayer([]*NotificationRecord{{
+			Kind:             NotificationKindRoute,
+			NotificationType: MibParameterNotification,
+			Route:            fakeRoute(1, "0.0.0.0/0", 0),
+		}}),
+	}
+
+	monitor, err := newNetworkChangeMonitor(f.replayer, config, func(events []*NetworkChangeEvent) {
+		f.mutex.Lock()
+		f.events = append(f.events, events)
+		onEvents := f.onEvents
+		f.mutex.Unlock()
+		if onEvents != nil {
+			onEvents()
+		}
+	}, func(err error) {
+		f.mutex.Lock()
+		f.errors = append(f.errors, err)
+		f.mutex.Unlock()
+	}, f.clock, func() (*NetworkState, error) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.reads++
+		if f.failures > 0 {
+			f.failures--
+			return nil, errors.New("reading state failed")
+		}
+		return f.state, nil
+	}, func(changed func(), onError func(err error)) (func() error, error) {
+		f.dnsChanged = changed
+		return func() error { return nil }, nil
+	})
+
+	if err != nil {
+		t.Fatalf("newNetworkChangeMonitor() returned an error: %v", err)
+	}
+
+	f.monitor = monitor
+
+	return f
+}
+
+// Delivers 'count' raw notifications to the monitor.
+func (f *monitorFixture) notify(count int) {
+	for i := 0; i < count; i++ {
+		if err := f.replayer.Replay(0); err != nil {
+			f.t.Fatalf("Replay() returned an error: %v", err)
+		}
+	}
+}
+
+func (f *monitorFixture) setState(state *NetworkState) {
+	f.mutex.Lock()
+	f.state = state
+	f.mutex.Unlock()
+}
+
+func (f *monitorFixture) counts() (reads int, batches int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.reads, len(f.events)
+}
+
+func fakeNetworkState(primary *Route, interfaces ...*InterfaceNetworkState) *NetworkState {
+
+	state := &NetworkState{
+		PrimaryRoutes: make(map[AddressFamily]*Route),
+		Interfaces:    make(map[uint64]*InterfaceNetworkState),
+	}
+
+	if primary != nil {
+		state.PrimaryRoutes[primary.DestinationPrefix.Prefix.Family] = primary
+	}
+
+	for _, ifc := range interfaces {
+		state.Interfaces[ifc.InterfaceLuid] = ifc
+	}
+
+	return state
+}
+
+func TestNetworkChangeMonitorDebounce(t *testing.T) {
+
+	initial := fakeNetworkState(fakeRoute(1, "0.0.0.0/0", 0))
+	f := newMonitorFixture(t, &NetworkChangeMonitorConfig{Debounce: 500 * time.Millisecond}, initial)
+	defer f.monitor.Close()
+
+	f.setState(fakeNetworkState(fakeRoute(2, "0.0.0.0/0", 0)))
+
+	// A storm of notifications, 100ms apart.
+	for i := 0; i < 10; i++ {
+		f.notify(5)
+		f.clock.Advance(100 * time.Millisecond)
+	}
+
+	if reads, batches := f.counts(); reads != 1 || batches != 0 {
+		t.Fatalf("During the storm state was read %d times and %d batches were reported, although 1 and 0 are "+
+			"expected.", reads, batches)
+	}
+
+	f.clock.Advance(400 * time.Millisecond)
+
+	if reads, batches := f.counts(); reads != 2 || batches != 1 {
+		t.Fatalf("After the storm state was read %d times and %d batches were reported, although 2 and 1 are "+
+			"expected.", reads, batches)
+	}
+
+	events := f.events[0]
+
+	if len(events) != 1 || events[0].Kind != NetworkChangePrimaryRoute || events[0].Family != AF_INET ||
+		events[0].BeforeRoute.InterfaceLuid != 1 || events[0].AfterRoute.InterfaceLuid != 2 {
+		t.Errorf("Reported events are %v, although a single primary route change from 1 to 2 is expected.", events)
+	}
+
+	// Nothing changed since.
+	f.notify(1)
+	f.clock.Advance(time.Second)
+
+	if reads, batches := f.counts(); reads != 3 || batches != 1 {
+		t.Errorf("State was read %d times and %d batches were reported, although 3 and 1 are expected.", reads,
+			batches)
+	}
+}
+
+func TestNetworkChangeMonitorMaxDelay(t *testing.T) {
+
+	f := newMonitorFixture(t, &NetworkChangeMonitorConfig{Debounce: 500 * time.Millisecond, MaxDelay: 2 * time.Second},
+		fakeNetworkState(nil))
+	defer f.monitor.Close()
+
+	f.setState(fakeNetworkState(nil, &InterfaceNetworkState{InterfaceLuid: 1, Up: true}))
+
+	// Notifications never pause long enough for the debounce to settle.
+	for i := 0; i < 6; i++ {
+		f.notify(1)
+		f.clock.Advance(400 * time.Millisecond)
+	}
+
+	if reads, batches := f.counts(); reads != 2 || batches != 1 {
+		t.Errorf("State was read %d times and %d batches were reported, although 2 and 1 are expected.", reads,
+			batches)
+	}
+}
+
+func TestNetworkChangeMonitorInvalidConfig(t *testing.T) {
+
+	for _, config := range []*NetworkChangeMonitorConfig{{Debounce: -time.Second}, {MaxDelay: -time.Second}} {
+		if _, err := newNetworkChangeMonitor(NewNotificationReplayer(nil), config, nil, nil, &fakeClock{}, nil,
+			nil); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("newNetworkChangeMonitor() with %+v returned %v, although ErrInvalidParameter is expected.",
+				*config, err)
+		}
+	}
+}
+
+func TestNetworkChangeMonitorClose(t *testing.T) {
+
+	f := newMonitorFixture(t, nil, fakeNetworkState(nil))
+
+	f.setState(fakeNetworkState(nil, &InterfaceNetworkState{InterfaceLuid: 1, Up: true}))
+	f.notify(1)
+
+	if err := f.monitor.Close(); err != nil {
+		t.Errorf("Close() returned an error: %v", err)
+	}
+
+	f.clock.Advance(time.Minute)
+	f.notify(1)
+	f.clock.Advance(time.Minute)
+
+	if reads, batches := f.counts(); reads != 1 || batches != 0 {
+		t.Errorf("After Close() state was read %d times and %d batches were reported, although 1 and 0 are "+
+			"expected.", reads, batches)
+	}
+}
+
+func TestNetworkChangeMonitorCloseDuringCallback(t *testing.T) {
+
+	f := newMonitorFixture(t, nil, fakeNetworkState(nil))
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	f.mutex.Lock()
+	f.onEvents = func() {
+		close(entered)
+		<-release
+	}
+	f.mutex.Unlock()
+
+	f.setState(fakeNetworkState(nil, &InterfaceNetworkState{InterfaceLuid: 1, Up: true}))
+	f.notify(1)
+
+	go f.clock.Advance(time.Second)
+	<-entered
+
+	// Called from another goroutine, Close() waits for the running callback.
+	closed := make(chan struct{})
+
+	go func() {
+		_ = f.monitor.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("Close() returned while the callback was running, although it's expected to wait.")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() didn't return after the callback returned.")
+	}
+
+	// Called from the callback itself, Close() doesn't wait for it.
+	f = newMonitorFixture(t, nil, fakeNetworkState(nil))
+
+	f.mutex.Lock()
+	f.onEvents = func() {
+		if err := f.monitor.Close(); err != nil {
+			t.Errorf("Close() returned an error: %v", err)
+		}
+	}
+	f.mutex.Unlock()
+
+	f.setState(fakeNetworkState(nil, &InterfaceNetworkState{InterfaceLuid: 1, Up: true}))
+	f.notify(1)
+
+	advanced := make(chan struct{})
+
+	go func() {
+		f.clock.Advance(time.Second)
+		close(advanced)
+	}()
+
+	select {
+	case <-advanced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() called from the callback waited for the callback itself.")
+	}
+}
+
+func TestNetworkChangeMonitorDnsChange(t *testing.T) {
+
+	dns := &InterfaceNetworkState{InterfaceLuid: 1, Up: true, DnsServers: []net.IP{{192, 168, 1, 1}}}
+	f := newMonitorFixture(t, nil, fakeNetworkState(nil, dns))
+	defer f.monitor.Close()
+
+	f.setState(fakeNetworkState(nil, &InterfaceNetworkState{InterfaceLuid: 1, Up: true,
+		DnsServers: []net.IP{{1, 1, 1, 1}}}))
+
+	// Only the registry watch notices the change.
+	f.dnsChanged()
+	f.clock.Advance(time.Second)
+
+	if reads, batches := f.counts(); reads != 2 || batches != 1 {
+		t.Fatalf("State was read %d times and %d batches were reported, although 2 and 1 are expected.", reads,
+			batches)
+	}
+
+	if events := f.events[0]; len(events) != 1 || events[0].Kind != NetworkChangeDnsServers {
+		t.Errorf("Reported events are %v, although a single DNS server change is expected.", events)
+	}
+}
+
+func TestNetworkChangeMonitorRetry(t *testing.T) {
+
+	f := newMonitorFixture(t, &NetworkChangeMonitorConfig{Debounce: 500 * time.Millisecond, MaxDelay: 2 * time.Second},
+		fakeNetworkState(nil))
+	defer f.monitor.Close()
+
+	f.setState(fakeNetworkState(nil, &InterfaceNetworkState{InterfaceLuid: 1, Up: true}))
+	f.failures = 1
+
+	f.notify(1)
+	f.clock.Advance(time.Second)
+
+	if reads, batches := f.counts(); reads != 2 || batches != 0 || len(f.errors) != 1 {
+		t.Fatalf("State was read %d times, %d batches and %d errors were reported, although 2, 0 and 1 are "+
+			"expected.", reads, batches, len(f.errors))
+	}
+
+	// No further notification arrives, but the failed read is retried.
+	f.clock.Advance(2 * time.Second)
+
+	if reads, batches := f.counts(); reads != 3 || batches != 1 {
+		t.Errorf("After the retry state was read %d times and %d batches were reported, although 3 and 1 are "+
+			"expected.", reads, batches)
+	}
+}
+
+func TestDiffNetworkStates(t *testing.T) {
+
+	v4 := net.IP{192, 168, 1, 10}
+	v6 := net.ParseIP("fd00::10")
+	dns := net.IP{192, 168, 1, 1}
+
+	before := fakeNetworkState(fakeRoute(1, "0.0.0.0/0", 0),
+		&InterfaceNetworkState{InterfaceLuid: 1, Up: true, Addresses: []net.IP{v4}, DnsServers: []net.IP{dns}},
+		&InterfaceNetworkState{InterfaceLuid: 2, Up: false},
+		&InterfaceNetworkState{InterfaceLuid: 3, Up: true, Addresses: []net.IP{v4}})
+
+	after := fakeNetworkState(nil,
+		&InterfaceNetworkState{InterfaceLuid: 1, Up: false, Addresses: []net.IP{v6}},
+		&InterfaceNetworkState{InterfaceLuid: 2, Up: true},
+		&InterfaceNetworkState{InterfaceLuid: 3, Up: true})
+
+	events := diffNetworkStates(before, after, map[uint64]bool{1: true, 2: true})
+
+	expected := []struct {
+		kind    NetworkChangeKind
+		luid    uint64
+		address net.IP
+	}{
+		{NetworkChangePrimaryRoute, 0, nil},
+		{NetworkChangeInterfaceDown, 1, nil},
+		{NetworkChangeAddressRemoved, 1, v4},
+		{NetworkChangeAddressAdded, 1, v6},
+		{NetworkChangeDnsServers, 1, nil},
+		{NetworkChangeInterfaceUp, 2, nil},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("diffNetworkStates() returned %v, although %d events are expected.", events, len(expected))
+	}
+
+	for i, e := range expected {
+		event := events[i]
+		if event.Kind != e.kind || event.InterfaceLuid != e.luid || (e.address != nil && !event.Address.Equal(e.address)) {
+			t.Errorf("Event #%d is %s, although %s on interface %d is expected.", i, event.String(), e.kind.String(),
+				e.luid)
+		}
+	}
+
+	if events[0].BeforeRoute == nil || events[0].AfterRoute != nil {
+		t.Errorf("Primary route event is %s, although a removal is expected.", events[0].String())
+	}
+
+	if events[3].Family != AF_INET6 || len(events[3].BeforeAddresses) != 1 || len(events[3].AfterAddresses) != 1 {
+		t.Errorf("Address event is %+v, although before/after addresses are expected.", events[3])
+	}
+
+	if len(events[4].BeforeDnsServers) != 1 || len(events[4].AfterDnsServers) != 0 {
+		t.Errorf("DNS event is %+v, although before/after DNS servers are expected.", events[4])
+	}
+
+	if events := diffNetworkStates(before, before, nil); len(events) != 0 {
+		t.Errorf("diffNetworkStates() of equal states returned %v, although no events are expected.", events)
+	}
+}
+
+func TestPrimaryDefaultRoute(t *testing.T) {
+
+	routes := []*Route{
+		fakeRoute(1, "0.0.0.0/0", 10),
+		fakeRoute(2, "0.0.0.0/0", 5),
+		fakeRoute(3, "0.0.0.0/0", 0),
+		fakeRoute(4, "10.0.0.0/8", 0),
+		fakeRoute(5, "::/0", 0),
+	}
+
+	// Interface 3 is down; 1 and 2 tie on the effective metric.
+	metrics := map[uint64]uint32{1: 20, 2: 25, 4: 0, 5: 0}
+
+	if route := primaryDefaultRoute(routes, AF_INET, metrics); route == nil || route.InterfaceLuid != 1 {
+		t.Errorf("primaryDefaultRoute() returned %v, although the route of interface 1 is expected.", route)
+	}
+
+	if route := primaryDefaultRoute(routes, AF_INET6, metrics); route == nil || route.InterfaceLuid != 5 {
+		t.Errorf("primaryDefaultRoute() returned %v, although the route of interface 5 is expected.", route)
+	}
+
+	if route := primaryDefaultRoute(routes, AF_INET6, map[uint64]uint32{}); route != nil {
+		t.Errorf("primaryDefaultRoute() returned %v, although nil is expected.", route)
+	}
+}
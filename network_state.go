@@ -0,0 +1,124 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Snapshot of the parts of the network configuration NetworkChangeMonitor reports changes of.
+type NetworkState struct {
+	// Default route with the lowest effective metric (route metric plus interface metric) per family, considering
+	// only interfaces which are up, and preferring half-default route pairs. A family is missing if there's no such
+	// route.
+	PrimaryRoutes map[AddressFamily]*Route
+	Interfaces    map[uint64]*InterfaceNetworkState
+}
+
+type InterfaceNetworkState struct {
+	InterfaceLuid uint64
+	// Whether OperStatus is IfOperStatusUp.
+	Up         bool
+	Addresses  []net.IP
+	DnsServers []net.IP
+}
+
+// Returns the current NetworkState.
+func GetNetworkState() (*NetworkState, error) {
+
+	ifcs, err := GetInterfaces()
+
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := GetRoutes(AF_UNSPEC)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return networkStateFrom(ifcs, routes), nil
+}
+
+func networkStateFrom(ifcs []*Interface, routes []*Route) *NetworkState {
+
+	state := &NetworkState{
+		PrimaryRoutes: make(map[AddressFamily]*Route),
+		Interfaces:    make(map[uint64]*InterfaceNetworkState, len(ifcs)),
+	}
+
+	interfaceMetrics := make(map[AddressFamily]map[uint64]uint32)
+	interfaceMetrics[AF_INET] = make(map[uint64]uint32)
+	interfaceMetrics[AF_INET6] = make(map[uint64]uint32)
+
+	for _, ifc := range ifcs {
+
+		ifcState := &InterfaceNetworkState{InterfaceLuid: ifc.Luid, Up: ifc.OperStatus == IfOperStatusUp}
+
+		for _, address := range ifc.UnicastAddresses {
+			ifcState.Addresses = append(ifcState.Addresses, address.Address.Address)
+		}
+
+		for _, dns := range ifc.DnsServerAddresses {
+			ifcState.DnsServers = append(ifcState.DnsServers, dns.Address.Address)
+		}
+
+		state.Interfaces[ifc.Luid] = ifcState
+
+		if ifcState.Up {
+			interfaceMetrics[AF_INET][ifc.Luid] = ifc.Ipv4Metric
+			interfaceMetrics[AF_INET6][ifc.Luid] = ifc.Ipv6Metric
+		}
+	}
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+		if route := primaryDefaultRoute(routes, family, interfaceMetrics[family]); route != nil {
+			state.PrimaryRoutes[family] = route
+		}
+	}
+
+	return state
+}
+
+// Returns the best default route of 'family' (see rankDefaultRoutes()), among routes of interfaces present in
+// 'interfaceMetrics'.
+func primaryDefaultRoute(routes []*Route, family AddressFamily, interfaceMetrics map[uint64]uint32) *Route {
+
+	interfaces := make(map[uint64]*defaultRouteInterface, len(interfaceMetrics))
+
+	for luid, metric := range interfaceMetrics {
+		interfaces[luid] = &defaultRouteInterface{metric: metric, up: true, connected: true}
+	}
+
+	if candidates := rankDefaultRoutes(routes, family, interfaces); len(candidates) > 0 {
+		return candidates[0].route
+	}
+
+	return nil
+}
+
+func (state *NetworkState) String() string {
+
+	if state == nil {
+		return "<nil>"
+	}
+
+	var sb strings.Builder
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+		if route, ok := state.PrimaryRoutes[family]; ok {
+			sb.WriteString(fmt.Sprintf("Primary %s route: interface %d via %s\n", family.String(),
+				route.InterfaceLuid, route.NextHop.Address.String()))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("Interfaces: %d", len(state.Interfaces)))
+
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "github.com/starvpn/winipcfg-go/internal/notification"
+
+type notificationDispatcher = notification.Dispatcher
+type notificationSubscriber = notification.Subscriber
+
+// Returns a dispatcher fanning notifications from per-family OS registrations out to subscribers. See
+// notification.NewDispatcher() for details.
+func newNotificationDispatcher(register, cancel func(family AddressFamily) error) *notificationDispatcher {
+	return notification.NewDispatcher(func(family uint16) error { return register(AddressFamily(family)) },
+		func(family uint16) error { return cancel(AddressFamily(family)) },
+		func(errs []error) error { return multiError(errs) })
+}
+
+// OS notification handles of one kind of change notifications, per address family.
+type notificationHandles struct {
+	ipv4 uintptr
+	ipv6 uintptr
+}
+
+// Returns pointer to the handle of 'family', which has to be AF_INET or AF_INET6.
+func (handles *notificationHandles) of(family AddressFamily) *uintptr {
+
+	if family == AF_INET6 {
+		return &handles.ipv6
+	}
+
+	return &handles.ipv4
+}
@@ -0,0 +1,259 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/starvpn/winipcfg-go/internal/notification"
+)
+
+// Kind of a recorded notification, i.e. which Register*ChangeCallback API it comes from.
+type NotificationKind = notification.Kind
+
+const (
+	NotificationKindUnicastAddress = notification.KindUnicastAddress
+	NotificationKindInterface      = notification.KindInterface
+	NotificationKindRoute          = notification.KindRoute
+)
+
+// A single recorded notification. Depending on Kind, one of UnicastAddress, IpInterface and Route holds the converted
+// row (UnicastAddress may be missing, e.g. for MibInitialNotification); in recordings it's the record's Row.
+type NotificationRecord struct {
+	// Time the OS notification was received.
+	Time time.Time
+	// Position of the notification among all recorded ones, starting at 0. Records are written in this order.
+	Sequence         uint64
+	Kind             NotificationKind
+	NotificationType MibNotificationType
+	Family           AddressFamily
+	InterfaceLuid    uint64
+	Ip               net.IP
+	UnicastAddress   *UnicastIpAddressRow
+	IpInterface      *IpInterface
+	Route            *Route
+}
+
+// Returns the record as recordings are made of.
+func (record *NotificationRecord) toRecord() *notification.Record {
+
+	converted := &notification.Record{
+		Time:             record.Time,
+		Sequence:         record.Sequence,
+		Kind:             record.Kind,
+		NotificationType: uint32(record.NotificationType),
+		Family:           uint16(record.Family),
+		InterfaceLuid:    record.InterfaceLuid,
+		Ip:               record.Ip,
+	}
+
+	// Typed nil pointers would make non-nil rows.
+	switch {
+	case record.Kind == NotificationKindUnicastAddress && record.UnicastAddress != nil:
+		converted.Row = record.UnicastAddress
+	case record.Kind == NotificationKindInterface && record.IpInterface != nil:
+		converted.Row = record.IpInterface
+	case record.Kind == NotificationKindRoute && record.Route != nil:
+		converted.Row = record.Route
+	}
+
+	return converted
+}
+
+func notificationRecordFrom(record *notification.Record) *NotificationRecord {
+
+	converted := &NotificationRecord{
+		Time:             record.Time,
+		Sequence:         record.Sequence,
+		Kind:             record.Kind,
+		NotificationType: MibNotificationType(record.NotificationType),
+		Family:           AddressFamily(record.Family),
+		InterfaceLuid:    record.InterfaceLuid,
+		Ip:               record.Ip,
+	}
+
+	switch row := record.Row.(type) {
+	case *UnicastIpAddressRow:
+		converted.UnicastAddress = row
+	case *IpInterface:
+		converted.IpInterface = row
+	case *Route:
+		converted.Route = row
+	}
+
+	return converted
+}
+
+// Returns a pointer to a new row of the kind's type, or nil if the kind is unknown.
+func newNotificationRow(kind NotificationKind) interface{} {
+
+	switch kind {
+	case NotificationKindUnicastAddress:
+		return &UnicastIpAddressRow{}
+	case NotificationKindInterface:
+		return &IpInterface{}
+	case NotificationKindRoute:
+		return &Route{}
+	default:
+		return nil
+	}
+}
+
+// Sources whose notifications can be recorded, i.e. SystemNotificationSource and NotificationReplayer.
+type recordableNotificationSource interface {
+	changeSource() notification.Source
+}
+
+// Writes every notification received from a NotificationSource to a JSONL stream, which can be replayed later by
+// NotificationReplayer. Notifications are recorded as the source receives them (i.e. on the OS notification thread),
+// with a single sequence of numbers and timestamps across all kinds, and written in that order by a single goroutine.
+type NotificationRecorder struct {
+	recorder *notification.Recorder
+}
+
+// Starts recording notifications from 'source' (SystemNotificationSource or NotificationReplayer) matching 'filter'
+// (nil matches everything) to 'w'. Filter's DestinationPrefix restricts route changes only. Recording lasts until
+// Close() is called.
+func NewNotificationRecorder(source NotificationSource, w io.Writer, filter *ChangeFilter) (*NotificationRecorder, error) {
+	return newNotificationRecorder(source, w, filter, time.Now)
+}
+
+func newNotificationRecorder(source NotificationSource, w io.Writer, filter *ChangeFilter,
+	now func() time.Time) (*NotificationRecorder, error) {
+
+	recordable, ok := source.(recordableNotificationSource)
+
+	if !ok {
+		return nil, newKindError(ErrInvalidParameter,
+			"NewNotificationRecorder() - notifications of %T can't be recorded", source)
+	}
+
+	// Unicast address and interface changes can't be filtered by DestinationPrefix, but they can be by its family.
+	var addressFilter *ChangeFilter
+
+	if filter != nil {
+
+		f := *filter
+		f.DestinationPrefix = nil
+
+		if filter.DestinationPrefix != nil && f.Family == AF_UNSPEC {
+			f.Family = ipFamily(filter.DestinationPrefix.IP)
+		}
+
+		addressFilter = &f
+	}
+
+	compiledAddressFilter, err := addressFilter.compile(false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compiledRouteFilter, err := filter.compile(true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recorder, err := notification.NewRecorder(recordable.changeSource(), w, []notification.Recording{
+		{
+			Kind:     NotificationKindUnicastAddress,
+			Families: compiledAddressFilter.families(),
+			Accept: func(event interface{}) bool {
+				change := event.(*unicastAddressChange)
+				return compiledAddressFilter.matches(change.family, change.interfaceLuid, change.notificationType)
+			},
+			Record: unicastAddressChangeRecord,
+		},
+		{
+			Kind:     NotificationKindInterface,
+			Families: compiledAddressFilter.families(),
+			Accept: func(event interface{}) bool {
+				change := event.(*interfaceChange)
+				return compiledAddressFilter.matches(change.family, change.interfaceLuid, change.notificationType)
+			},
+			Record: interfaceChangeRecord,
+		},
+		{
+			Kind:     NotificationKindRoute,
+			Families: compiledRouteFilter.families(),
+			Accept: func(event interface{}) bool {
+				change := event.(*routeChange)
+				return compiledRouteFilter.matchesRoute(change.route, change.notificationType)
+			},
+			Record: routeChangeRecord,
+		},
+	}, now, func(errs []error) error { return multiError(errs) })
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationRecorder{recorder}, nil
+}
+
+func unicastAddressChangeRecord(event interface{}) *notification.Record {
+
+	change := event.(*unicastAddressChange)
+
+	record := &notification.Record{
+		Kind:             NotificationKindUnicastAddress,
+		NotificationType: uint32(change.notificationType),
+		Family:           uint16(change.family),
+		InterfaceLuid:    change.interfaceLuid,
+	}
+
+	if change.row != nil {
+		record.Row = change.row.clone()
+	}
+
+	if change.ip != nil {
+		record.Ip = append(net.IP(nil), change.ip...)
+	}
+
+	return record
+}
+
+func interfaceChangeRecord(event interface{}) *notification.Record {
+
+	change := event.(*interfaceChange)
+
+	ipInterface := *change.ipInterface
+
+	return &notification.Record{
+		Kind:             NotificationKindInterface,
+		NotificationType: uint32(change.notificationType),
+		Family:           uint16(change.family),
+		InterfaceLuid:    change.interfaceLuid,
+		Row:              &ipInterface,
+	}
+}
+
+func routeChangeRecord(event interface{}) *notification.Record {
+
+	change := event.(*routeChange)
+
+	return &notification.Record{
+		Kind:             NotificationKindRoute,
+		NotificationType: uint32(change.notificationType),
+		Family:           uint16(change.route.DestinationPrefix.Prefix.Family),
+		InterfaceLuid:    change.route.InterfaceLuid,
+		Row:              change.route.clone(),
+	}
+}
+
+// Returns the first error encountered while writing notifications, if any. Recording continues after errors.
+func (recorder *NotificationRecorder) Err() error {
+	return recorder.recorder.Err()
+}
+
+// Stops recording, and waits until all recorded notifications are written. Returns errors of unregistering from the
+// source, or the first write error.
+func (recorder *NotificationRecorder) Close() error {
+	return recorder.recorder.Close()
+}
@@ -0,0 +1,129 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/starvpn/winipcfg-go/internal/notification"
+)
+
+// Reads notifications recorded by NotificationRecorder.
+func ReadNotificationRecords(r io.Reader) ([]*NotificationRecord, error) {
+
+	records, err := notification.ReadRecords(r, newNotificationRow)
+
+	if err != nil {
+		return nil, fmt.Errorf("ReadNotificationRecords() - %w", err)
+	}
+
+	converted := make([]*NotificationRecord, len(records))
+
+	for i, record := range records {
+		converted[i] = notificationRecordFrom(record)
+	}
+
+	return converted, nil
+}
+
+// NotificationSource delivering recorded notifications instead of the OS ones, so recorded traces can be used in
+// tests. Callbacks are filtered exactly as the system ones, but nothing is delivered until Replay() is called. Unlike
+// the system callbacks, all callbacks are called from a single goroutine, in the recorded order, and no notification
+// is ever dropped.
+type NotificationReplayer struct {
+	replayer *notification.Replayer
+}
+
+func NewNotificationReplayer(records []*NotificationRecord) *NotificationReplayer {
+
+	converted := make([]*notification.Record, len(records))
+
+	for i, record := range records {
+		converted[i] = record.toRecord()
+	}
+
+	return &NotificationReplayer{notification.NewReplayer(converted, notificationRecordEvent)}
+}
+
+// Delivers all records to the registered callbacks, and returns once they're all delivered. If 'speed' is 0,
+// records are replayed without delays; otherwise delays between records are the recorded ones divided by 'speed' (so
+// 1 means real time, and 10 ten times faster). Records are checked before anything is delivered. It must not be
+// called from a callback.
+func (replayer *NotificationReplayer) Replay(speed float64) error {
+
+	if err := replayer.replayer.Replay(speed); err != nil {
+		return newKindError(ErrInvalidParameter, "NotificationReplayer.Replay() - %v", err)
+	}
+
+	return nil
+}
+
+// Returns the change delivered to callbacks for the record, or an error if the record can't be replayed.
+func notificationRecordEvent(record *notification.Record) (interface{}, error) {
+
+	notificationType := MibNotificationType(record.NotificationType)
+	family := AddressFamily(record.Family)
+
+	switch record.Kind {
+	case NotificationKindUnicastAddress:
+		row, _ := record.Row.(*UnicastIpAddressRow)
+		return &unicastAddressChange{
+			notificationType: notificationType,
+			family:           family,
+			interfaceLuid:    record.InterfaceLuid,
+			ip:               record.Ip,
+			row:              row,
+		}, nil
+	case NotificationKindInterface:
+		ipInterface, _ := record.Row.(*IpInterface)
+		if ipInterface == nil {
+			ipInterface = &IpInterface{Family: family, InterfaceLuid: record.InterfaceLuid}
+		}
+		return &interfaceChange{notificationType, family, record.InterfaceLuid, ipInterface}, nil
+	case NotificationKindRoute:
+		route, _ := record.Row.(*Route)
+		if route == nil {
+			return nil, fmt.Errorf("route is missing")
+		}
+		return &routeChange{notificationType, route}, nil
+	default:
+		return nil, fmt.Errorf("unknown kind %q", record.Kind)
+	}
+}
+
+func (replayer *NotificationReplayer) changeSource() notification.Source {
+	return replayer.replayer
+}
+
+func (replayer *NotificationReplayer) RegisterUnicastAddressChangeCallbackEx(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, interfaceLuid uint64,
+		ip *net.IP)) (*UnicastAddressChangeCallback, error) {
+	return subscribeUnicastAddressChange(replayer.replayer.Dispatcher(NotificationKindUnicastAddress), filter,
+		callback)
+}
+
+func (replayer *NotificationReplayer) RegisterUnicastIpAddressRowChangeCallback(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, row *UnicastIpAddressRow)) (*UnicastAddressChangeCallback, error) {
+	return subscribeUnicastIpAddressRowChange(replayer.replayer.Dispatcher(NotificationKindUnicastAddress), filter,
+		callback)
+}
+
+func (replayer *NotificationReplayer) RegisterInterfaceChangeCallbackEx(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, interfaceLuid uint64)) (*InterfaceChangeCallback, error) {
+	return subscribeInterfaceChange(replayer.replayer.Dispatcher(NotificationKindInterface), filter, callback)
+}
+
+func (replayer *NotificationReplayer) RegisterIpInterfaceChangeCallback(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, ipInterface *IpInterface)) (*InterfaceChangeCallback, error) {
+	return subscribeIpInterfaceChange(replayer.replayer.Dispatcher(NotificationKindInterface), filter, callback)
+}
+
+func (replayer *NotificationReplayer) RegisterRouteChangeCallbackEx(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, route *Route)) (*RouteChangeCallback, error) {
+	return subscribeRouteChange(replayer.replayer.Dispatcher(NotificationKindRoute), filter, callback)
+}
@@ -0,0 +1,261 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/starvpn/winipcfg-go/internal/notification"
+)
+
+var replayStart = time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
+
+func fakeNotificationRecords() []*NotificationRecord {
+
+	address := &UnicastIpAddressRow{
+		Address:           &SockaddrInet{Family: AF_INET, Address: net.IP{10, 0, 0, 2}},
+		InterfaceLuid:     1,
+		PrefixOrigin:      IpPrefixOriginDhcp,
+		ValidLifetime:     600,
+		PreferredLifetime: 300,
+		DadState:          IpDadStateTentative,
+	}
+
+	return []*NotificationRecord{
+		{
+			Time:             replayStart,
+			Kind:             NotificationKindInterface,
+			NotificationType: MibParameterNotification,
+			Family:           AF_INET,
+			InterfaceLuid:    1,
+			IpInterface:      &IpInterface{Family: AF_INET, InterfaceLuid: 1, Connected: true, NlMtu: 1420},
+		},
+		{
+			Time:             replayStart.Add(time.Second),
+			Kind:             NotificationKindUnicastAddress,
+			NotificationType: MibAddInstance,
+			Family:           AF_INET,
+			InterfaceLuid:    1,
+			Ip:               address.Address.Address,
+			UnicastAddress:   address,
+		},
+		{
+			Time:             replayStart.Add(3 * time.Second),
+			Kind:             NotificationKindRoute,
+			NotificationType: MibAddInstance,
+			Family:           AF_INET,
+			InterfaceLuid:    2,
+			Route:            fakeRoute(2, "0.0.0.0/0", 10),
+		},
+	}
+}
+
+func TestNotificationRecordAndReplay(t *testing.T) {
+
+	source := NewNotificationReplayer(fakeNotificationRecords())
+
+	var buffer bytes.Buffer
+	now := replayStart
+
+	recorder, err := newNotificationRecorder(source, &buffer, nil, func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+
+	if err != nil {
+		t.Fatalf("newNotificationRecorder() returned an error: %v", err)
+	}
+
+	if err := source.Replay(0); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() returned an error: %v", err)
+	}
+
+	if lines := strings.Count(buffer.String(), "\n"); lines != 3 {
+		t.Fatalf("Recorder wrote %d lines, although 3 are expected.", lines)
+	}
+
+	records, err := ReadNotificationRecords(&buffer)
+
+	if err != nil {
+		t.Fatalf("ReadNotificationRecords() returned an error: %v", err)
+	}
+
+	original := fakeNotificationRecords()
+
+	if len(records) != len(original) {
+		t.Fatalf("ReadNotificationRecords() returned %d records, although %d are expected.", len(records),
+			len(original))
+	}
+
+	// Records of all kinds are numbered and timestamped in the order of the notifications.
+	for i, record := range records {
+
+		if record.Sequence != uint64(i) || !record.Time.Equal(replayStart.Add(time.Duration(i+1)*time.Second)) {
+			t.Errorf("Record #%d has sequence number %d and timestamp %v, although %d and %v are expected.", i,
+				record.Sequence, record.Time, i, replayStart.Add(time.Duration(i+1)*time.Second))
+		}
+
+		if record.Kind != original[i].Kind {
+			t.Errorf("Record #%d is %s, although %s is expected.", i, record.Kind, original[i].Kind)
+		}
+	}
+
+	if record := records[1]; record.UnicastAddress == nil ||
+		!record.UnicastAddress.equal(original[1].UnicastAddress) || !record.Ip.Equal(original[1].Ip) ||
+		record.InterfaceLuid != 1 || record.Family != AF_INET || record.NotificationType != MibAddInstance {
+		t.Errorf("Recorded unicast address change is %+v, although %+v is expected.", record, original[1])
+	}
+
+	if record := records[0]; record.IpInterface == nil ||
+		*record.IpInterface != *original[0].IpInterface || record.NotificationType != MibParameterNotification {
+		t.Errorf("Recorded interface change is %+v, although %+v is expected.", record, original[0])
+	}
+
+	if record := records[2]; record.Route == nil ||
+		!record.Route.DestinationPrefix.Prefix.equal(&original[2].Route.DestinationPrefix.Prefix) ||
+		record.Route.DestinationPrefix.PrefixLength != 0 || record.Route.Metric != 10 ||
+		record.InterfaceLuid != 2 {
+		t.Errorf("Recorded route change is %+v, although %+v is expected.", record, original[2])
+	}
+}
+
+func TestNotificationReplayOrder(t *testing.T) {
+
+	// More records than a subscriber's queue holds, alternating between kinds.
+	var records []*NotificationRecord
+
+	for i := 0; i < 2*notification.QueueLength; i++ {
+		if i%2 == 0 {
+			records = append(records, &NotificationRecord{Kind: NotificationKindInterface,
+				NotificationType: MibParameterNotification, Family: AF_INET, InterfaceLuid: uint64(i)})
+		} else {
+			records = append(records, &NotificationRecord{Kind: NotificationKindRoute, NotificationType: MibAddInstance,
+				Family: AF_INET, InterfaceLuid: uint64(i), Route: fakeRoute(uint64(i), "0.0.0.0/0", 10)})
+		}
+	}
+
+	replayer := NewNotificationReplayer(records)
+
+	// Callbacks are called from a single goroutine, so no synchronization is needed.
+	var luids []uint64
+
+	interfaceCallback, _ := replayer.RegisterInterfaceChangeCallbackEx(nil,
+		func(notificationType MibNotificationType, interfaceLuid uint64) {
+			time.Sleep(time.Microsecond)
+			luids = append(luids, interfaceLuid)
+		})
+
+	routeCallback, _ := replayer.RegisterRouteChangeCallbackEx(nil,
+		func(notificationType MibNotificationType, route *Route) { luids = append(luids, route.InterfaceLuid) })
+
+	if err := replayer.Replay(0); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	_ = interfaceCallback.Unregister()
+	_ = routeCallback.Unregister()
+
+	if interfaceCallback.Dropped() != 0 || routeCallback.Dropped() != 0 {
+		t.Errorf("Callbacks dropped %d and %d notifications, although none are expected.",
+			interfaceCallback.Dropped(), routeCallback.Dropped())
+	}
+
+	if len(luids) != len(records) {
+		t.Fatalf("Callbacks received %d notifications, although %d are expected.", len(luids), len(records))
+	}
+
+	for i, luid := range luids {
+		if luid != uint64(i) {
+			t.Fatalf("Notification #%d was delivered at position %d.", luid, i)
+		}
+	}
+}
+
+func TestNotificationReplayFilter(t *testing.T) {
+
+	replayer := NewNotificationReplayer(fakeNotificationRecords())
+
+	var mutex sync.Mutex
+	var luids []uint64
+	var routes []*Route
+
+	addressCallback, err := replayer.RegisterUnicastAddressChangeCallbackEx(&ChangeFilter{InterfaceLuids: []uint64{1}},
+		func(notificationType MibNotificationType, interfaceLuid uint64, ip *net.IP) {
+			mutex.Lock()
+			luids = append(luids, interfaceLuid)
+			mutex.Unlock()
+		})
+
+	if err != nil {
+		t.Fatalf("RegisterUnicastAddressChangeCallbackEx() returned an error: %v", err)
+	}
+
+	routeCallback, err := replayer.RegisterRouteChangeCallbackEx(&ChangeFilter{Family: AF_INET6},
+		func(notificationType MibNotificationType, route *Route) {
+			mutex.Lock()
+			routes = append(routes, route)
+			mutex.Unlock()
+		})
+
+	if err != nil {
+		t.Fatalf("RegisterRouteChangeCallbackEx() returned an error: %v", err)
+	}
+
+	if err := replayer.Replay(0); err != nil {
+		t.Fatalf("Replay() returned an error: %v", err)
+	}
+
+	_ = addressCallback.Unregister()
+	_ = routeCallback.Unregister()
+
+	if len(luids) != 1 || luids[0] != 1 {
+		t.Errorf("Unicast address callback received interfaces %v, although [1] is expected.", luids)
+	}
+
+	if len(routes) != 0 {
+		t.Errorf("IPv6 route callback received %d routes, although none are expected.", len(routes))
+	}
+}
+
+func TestReadNotificationRecordsErrors(t *testing.T) {
+
+	if _, err := ReadNotificationRecords(strings.NewReader("{\"Kind\": \"Route\"}\nnot json\n")); err == nil {
+		t.Error("ReadNotificationRecords() didn't return an error for malformed input, although it's expected.")
+	}
+
+	records, err := ReadNotificationRecords(strings.NewReader("{\"Kind\": \"Unknown\"}\n"))
+
+	if err != nil {
+		t.Fatalf("ReadNotificationRecords() returned an error: %v", err)
+	}
+
+	if err := NewNotificationReplayer(records).Replay(0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Replay() returned %v for unknown record kind, although an ErrInvalidParameter error is expected.",
+			err)
+	}
+
+	// A route record without the route.
+	records = []*NotificationRecord{{Kind: NotificationKindRoute, NotificationType: MibAddInstance}}
+
+	if err := NewNotificationReplayer(records).Replay(0); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Replay() returned %v for a record without route, although an ErrInvalidParameter error is "+
+			"expected.", err)
+	}
+
+	if err := NewNotificationReplayer(nil).Replay(-1); !errors.Is(err, ErrInvalidParameter) {
+		t.Errorf("Replay(-1) returned %v, although an ErrInvalidParameter error is expected.", err)
+	}
+}
@@ -0,0 +1,67 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"net"
+
+	"github.com/starvpn/winipcfg-go/internal/notification"
+)
+
+// Source of unicast address, interface and route change notifications. Code written against it can be driven either
+// by the OS (SystemNotificationSource) or by recorded notifications (NotificationReplayer).
+type NotificationSource interface {
+	RegisterUnicastAddressChangeCallbackEx(filter *ChangeFilter, callback func(notificationType MibNotificationType,
+		interfaceLuid uint64, ip *net.IP)) (*UnicastAddressChangeCallback, error)
+	RegisterUnicastIpAddressRowChangeCallback(filter *ChangeFilter, callback func(notificationType MibNotificationType,
+		row *UnicastIpAddressRow)) (*UnicastAddressChangeCallback, error)
+	RegisterInterfaceChangeCallbackEx(filter *ChangeFilter, callback func(notificationType MibNotificationType,
+		interfaceLuid uint64)) (*InterfaceChangeCallback, error)
+	RegisterIpInterfaceChangeCallback(filter *ChangeFilter, callback func(notificationType MibNotificationType,
+		ipInterface *IpInterface)) (*InterfaceChangeCallback, error)
+	RegisterRouteChangeCallbackEx(filter *ChangeFilter, callback func(notificationType MibNotificationType,
+		route *Route)) (*RouteChangeCallback, error)
+}
+
+// NotificationSource delivering notifications from the OS, through the package level Register*ChangeCallback
+// functions.
+var SystemNotificationSource NotificationSource = systemNotificationSource{}
+
+type systemNotificationSource struct{}
+
+func (systemNotificationSource) RegisterUnicastAddressChangeCallbackEx(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, interfaceLuid uint64,
+		ip *net.IP)) (*UnicastAddressChangeCallback, error) {
+	return RegisterUnicastAddressChangeCallbackEx(filter, callback)
+}
+
+func (systemNotificationSource) RegisterUnicastIpAddressRowChangeCallback(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, row *UnicastIpAddressRow)) (*UnicastAddressChangeCallback, error) {
+	return RegisterUnicastIpAddressRowChangeCallback(filter, callback)
+}
+
+func (systemNotificationSource) RegisterInterfaceChangeCallbackEx(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, interfaceLuid uint64)) (*InterfaceChangeCallback, error) {
+	return RegisterInterfaceChangeCallbackEx(filter, callback)
+}
+
+func (systemNotificationSource) RegisterIpInterfaceChangeCallback(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, ipInterface *IpInterface)) (*InterfaceChangeCallback, error) {
+	return RegisterIpInterfaceChangeCallback(filter, callback)
+}
+
+func (systemNotificationSource) RegisterRouteChangeCallbackEx(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, route *Route)) (*RouteChangeCallback, error) {
+	return RegisterRouteChangeCallbackEx(filter, callback)
+}
+
+func (systemNotificationSource) changeSource() notification.Source {
+	return notification.Dispatchers{
+		NotificationKindUnicastAddress: unicastAddressChangeDispatcher,
+		NotificationKindInterface:      interfaceChangeDispatcher,
+		NotificationKindRoute:          routeChangeDispatcher,
+	}
+}
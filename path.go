@@ -0,0 +1,195 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/windows"
+)
+
+// Cached per-destination path information. Corresponds to MIB_IPPATH_ROW defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_ippath_row).
+type Path struct {
+	Source         SockaddrInet
+	Destination    SockaddrInet
+	InterfaceLuid  uint64
+	InterfaceIndex uint32
+	CurrentNextHop SockaddrInet
+	PathMtu        uint32
+	// Estimated mean round-trip time, in milliseconds.
+	RttMean uint32
+	// Estimated round-trip time deviation, in milliseconds.
+	RttDeviation uint32
+	IsReachable  bool
+	// Milliseconds elapsed since the destination was last reachable. Valid only if IsReachable is true.
+	LastReachable uint32
+	// Milliseconds elapsed since the destination was last unreachable. Valid only if IsReachable is false.
+	LastUnreachable   uint32
+	LinkTransmitSpeed uint64
+	LinkReceiveSpeed  uint64
+}
+
+// Returns all cached paths. Corresponds to GetIpPathTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getippathtable).
+func GetPaths(family AddressFamily) ([]*Path, error) {
+
+	rows, err := getWtMibIppathRows(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	length := len(rows)
+
+	paths := make([]*Path, length, length)
+
+	for idx, row := range rows {
+
+		path, err := row.toPath()
+
+		if err != nil {
+			return nil, err
+		}
+
+		paths[idx] = path
+	}
+
+	return paths, nil
+}
+
+// Returns the cached path to 'destination' through the interface with specified LUID. Corresponds to GetIpPathEntry
+// function (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getippathentry).
+func GetPath(interfaceLuid uint64, destination *net.IP) (*Path, error) {
+
+	wtdest, err := createWtSockaddrInet(destination, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := getWtMibIppathRow(interfaceLuid, wtdest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toPath()
+}
+
+// Flushes all cached paths of the specified family. Corresponds to FlushIpPathTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-flushippathtable).
+func FlushPaths(family AddressFamily) error {
+
+	result := flushIpPathTable(family)
+
+	if result == 0 {
+		return nil
+	} else {
+		return newOperationError("iphlpapi.FlushIpPathTable", 0, "", windows.Errno(result))
+	}
+}
+
+// Returns cached paths going through the interface.
+func (ifc *Interface) GetPaths(family AddressFamily) ([]*Path, error) {
+
+	paths, err := GetPaths(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return filterPaths(paths, func(path *Path) bool { return path.InterfaceLuid == ifc.Luid }), nil
+}
+
+// Flushes cached paths if any of them goes through the interface. Windows doesn't support deleting individual
+// paths, so the whole path table of the affected family is flushed; paths through other interfaces are simply
+// re-learned. Returns true if anything was flushed.
+func (ifc *Interface) FlushPaths(family AddressFamily) (bool, error) {
+	return flushPathsMatching(family, func(path *Path) bool { return path.InterfaceLuid == ifc.Luid })
+}
+
+// Flushes cached paths if any of them leads to 'destination'. The same limitations as for Interface.FlushPaths()
+// apply. Returns true if anything was flushed.
+func FlushPathsTo(destination net.IP) (bool, error) {
+
+	if destination.To16() == nil {
+		return false, newKindError(ErrInvalidParameter, "FlushPathsTo() - invalid destination IP")
+	}
+
+	return flushPathsMatching(AF_UNSPEC, func(path *Path) bool { return path.Destination.Address.Equal(destination) })
+}
+
+func flushPathsMatching(family AddressFamily, match func(*Path) bool) (bool, error) {
+
+	paths, err := GetPaths(family)
+
+	if err != nil {
+		return false, err
+	}
+
+	flushed := false
+
+	for _, f := range pathFamilies(filterPaths(paths, match)) {
+
+		if err := FlushPaths(f); err != nil {
+			return flushed, err
+		}
+
+		flushed = true
+	}
+
+	return flushed, nil
+}
+
+func filterPaths(paths []*Path, match func(*Path) bool) []*Path {
+
+	result := make([]*Path, 0)
+
+	for _, path := range paths {
+		if match(path) {
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
+
+// Returns distinct families of paths' destinations, IPv4 first.
+func pathFamilies(paths []*Path) []AddressFamily {
+
+	families := make([]AddressFamily, len(paths))
+
+	for i, path := range paths {
+		families[i] = path.Destination.Family
+	}
+
+	return distinctFamilies(families)
+}
+
+func (path *Path) String() string {
+
+	if path == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf(`Source: %s
+Destination: %s
+InterfaceLuid: %d
+InterfaceIndex: %d
+CurrentNextHop: %s
+PathMtu: %d
+RttMean: %d
+RttDeviation: %d
+IsReachable: %v
+LastReachable: %d
+LastUnreachable: %d
+LinkTransmitSpeed: %d
+LinkReceiveSpeed: %d`, path.Source.String(), path.Destination.String(), path.InterfaceLuid, path.InterfaceIndex,
+		path.CurrentNextHop.String(), path.PathMtu, path.RttMean, path.RttDeviation, path.IsReachable, path.LastReachable,
+		path.LastUnreachable, path.LinkTransmitSpeed, path.LinkReceiveSpeed)
+}
@@ -0,0 +1,94 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+const path_print = false
+
+func TestPathFamilies(t *testing.T) {
+
+	paths := []*Path{
+		{Destination: SockaddrInet{Family: AF_INET6, Address: net.ParseIP("2001:db8::1")}, InterfaceLuid: 1},
+		{Destination: SockaddrInet{Family: AF_INET, Address: net.ParseIP("192.0.2.1").To4()}, InterfaceLuid: 2},
+		{Destination: SockaddrInet{Family: AF_INET6, Address: net.ParseIP("2001:db8::2")}, InterfaceLuid: 2},
+	}
+
+	if families := pathFamilies(paths); !reflect.DeepEqual(families, []AddressFamily{AF_INET, AF_INET6}) {
+		t.Errorf("pathFamilies() returned %v, although [AF_INET AF_INET6] is expected.", families)
+	}
+
+	filtered := filterPaths(paths, func(path *Path) bool { return path.InterfaceLuid == 1 })
+
+	if families := pathFamilies(filtered); !reflect.DeepEqual(families, []AddressFamily{AF_INET6}) {
+		t.Errorf("pathFamilies() returned %v, although [AF_INET6] is expected.", families)
+	}
+
+	if families := pathFamilies(nil); len(families) != 0 {
+		t.Errorf("pathFamilies() returned %v, although no families are expected.", families)
+	}
+}
+
+func TestGetPaths(t *testing.T) {
+
+	paths, err := GetPaths(AF_UNSPEC)
+
+	if err != nil {
+		t.Errorf("GetPaths() returned an error: %v", err)
+		return
+	}
+
+	if path_print {
+		for _, path := range paths {
+			fmt.Println("========================== PATH OUTPUT START ===========================")
+			fmt.Println(path)
+			fmt.Println("=========================== PATH OUTPUT END ============================")
+		}
+	}
+
+	if len(paths) == 0 {
+		return
+	}
+
+	expected := paths[0]
+
+	path, err := GetPath(expected.InterfaceLuid, &expected.Destination.Address)
+
+	if err != nil {
+		t.Errorf("GetPath() returned an error: %v", err)
+	} else if !path.Destination.Address.Equal(expected.Destination.Address) {
+		t.Errorf("GetPath() returned path to %s, although %s is expected.", path.Destination.String(),
+			expected.Destination.String())
+	}
+}
+
+func TestInterfaceGetPaths(t *testing.T) {
+
+	ifc, err := InterfaceFromLUID(existingLuid)
+
+	if err != nil {
+		t.Errorf("InterfaceFromLUID() returned an error: %v", err)
+		return
+	}
+
+	paths, err := ifc.GetPaths(AF_UNSPEC)
+
+	if err != nil {
+		t.Errorf("Interface.GetPaths() returned an error: %v", err)
+		return
+	}
+
+	for _, path := range paths {
+		if path.InterfaceLuid != existingLuid {
+			t.Errorf("Interface.GetPaths() returned a path with LUID %d.", path.InterfaceLuid)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"sync"
+	"time"
+)
+
+// Calls a function periodically in a background goroutine, between start() and stop(). The zero value is stopped.
+type periodicRunner struct {
+	mutex sync.Mutex
+	// Closed to stop the goroutine; nil if it isn't running.
+	quit chan struct{}
+	// Closed by the goroutine when it exits.
+	done chan struct{}
+}
+
+// Starts calling 'run' every 'interval' (which has to be positive); the first call is made immediately. Errors
+// returned by 'run' are passed to 'onError' if it isn't nil, and calling continues regardless. Calling it while
+// already running does nothing.
+func (p *periodicRunner) start(interval time.Duration, run func() error, onError func(error)) {
+
+	p.mutex.Lock()
+
+	if p.quit != nil {
+		p.mutex.Unlock()
+		return
+	}
+
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+	quit, done := p.quit, p.done
+
+	p.mutex.Unlock()
+
+	go func() {
+
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+
+			if err := run(); err != nil && onError != nil {
+				onError(err)
+			}
+
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stops calling started by start(), and waits until the running call (if any) returns. Does nothing if not running.
+func (p *periodicRunner) stop() {
+
+	p.mutex.Lock()
+	quit, done := p.quit, p.done
+	p.quit, p.done = nil, nil
+	p.mutex.Unlock()
+
+	if quit == nil {
+		return
+	}
+
+	close(quit)
+	<-done
+}
@@ -0,0 +1,61 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPeriodicRunner(t *testing.T) {
+
+	var runner periodicRunner
+	var calls, errs int32
+
+	run := func() error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("run failed")
+	}
+
+	onError := func(err error) {
+		atomic.AddInt32(&errs, 1)
+	}
+
+	// Stopping a runner which isn't running does nothing.
+	runner.stop()
+
+	runner.start(time.Hour, run, onError)
+	// Already running, so it's ignored.
+	runner.start(time.Millisecond, run, onError)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for atomic.LoadInt32(&errs) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	runner.stop()
+	runner.stop()
+
+	if c, e := atomic.LoadInt32(&calls), atomic.LoadInt32(&errs); c != 1 || e != 1 {
+		t.Errorf("run was called %d times and onError %d times, although 1 and 1 are expected.", c, e)
+	}
+
+	// It can be started again after stopping.
+	runner.start(time.Millisecond, run, nil)
+
+	for atomic.LoadInt32(&calls) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	runner.stop()
+
+	if c := atomic.LoadInt32(&calls); c < 3 {
+		t.Errorf("run was called %d times after restarting, although at least 3 calls are expected.", c)
+	}
+}
@@ -0,0 +1,231 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+	"sort"
+)
+
+// The interface carrying default traffic of a family, as returned by GetPrimaryInterface().
+type PrimaryInterface struct {
+	Interface *Interface
+	// Next hop of the winning default route; unspecified address (0.0.0.0 or ::) if the route is on-link.
+	Gateway net.IP
+	// Effective metric: route metric plus interface metric.
+	Metric uint32
+	// The winning default route. For a pair of half-default routes (0.0.0.0/1 and 128.0.0.0/1, or ::/1 and 8000::/1),
+	// the lower half.
+	Route *Route
+}
+
+// Properties of an interface which determine whether its default routes are used.
+type defaultRouteInterface struct {
+	// IpInterface.Metric of the family.
+	metric uint32
+	// Whether OperStatus is IfOperStatusUp.
+	up bool
+	// IpInterface.Connected.
+	connected bool
+	// IpInterface.DisableDefaultRoutes.
+	disableDefaultRoutes bool
+}
+
+func (ifc *defaultRouteInterface) usable() bool {
+	return ifc != nil && ifc.up && ifc.connected && !ifc.disableDefaultRoutes
+}
+
+type defaultRouteCandidate struct {
+	route *Route
+	// True for a pair of half-default routes, which wins over default routes regardless of metrics, since both halves
+	// are more specific than /0.
+	halfDefault bool
+	metric      uint64
+}
+
+// Returns usable default routes of 'family' from the best to the worst. A half-default route counts only if the same
+// interface has the other half too. Candidates are ordered by specificity (half-default pairs first), then by the
+// effective metric, then by interface LUID and next hop, so the order doesn't depend on the order of 'routes'.
+func rankDefaultRoutes(routes []*Route, family AddressFamily,
+	interfaces map[uint64]*defaultRouteInterface) []*defaultRouteCandidate {
+
+	var candidates []*defaultRouteCandidate
+
+	// Lower and upper halves per interface.
+	halves := make(map[uint64]*[2]*Route)
+
+	for _, route := range routes {
+
+		prefix := &route.DestinationPrefix
+
+		if prefix.Prefix.Family != family || prefix.PrefixLength > 1 {
+			continue
+		}
+
+		ifc := interfaces[route.InterfaceLuid]
+
+		if !ifc.usable() {
+			continue
+		}
+
+		if prefix.PrefixLength == 0 {
+			candidates = append(candidates, &defaultRouteCandidate{
+				route:  route,
+				metric: uint64(route.Metric) + uint64(ifc.metric),
+			})
+			continue
+		}
+
+		pair := halves[route.InterfaceLuid]
+
+		if pair == nil {
+			pair = &[2]*Route{}
+			halves[route.InterfaceLuid] = pair
+		}
+
+		half := 0
+
+		if isUpperHalf(prefix.Prefix.Address) {
+			half = 1
+		}
+
+		// Of several routes for the same half, the one with the lowest metric is used.
+		if pair[half] == nil || route.Metric < pair[half].Metric {
+			pair[half] = route
+		}
+	}
+
+	for luid, pair := range halves {
+
+		if pair[0] == nil || pair[1] == nil {
+			continue
+		}
+
+		// Traffic is split between the halves, so the pair is as good as its worse half.
+		routeMetric := pair[0].Metric
+
+		if pair[1].Metric > routeMetric {
+			routeMetric = pair[1].Metric
+		}
+
+		candidates = append(candidates, &defaultRouteCandidate{
+			route:       pair[0],
+			halfDefault: true,
+			metric:      uint64(routeMetric) + uint64(interfaces[luid].metric),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+
+		a, b := candidates[i], candidates[j]
+
+		if a.halfDefault != b.halfDefault {
+			return a.halfDefault
+		}
+
+		if a.metric != b.metric {
+			return a.metric < b.metric
+		}
+
+		if a.route.InterfaceLuid != b.route.InterfaceLuid {
+			return a.route.InterfaceLuid < b.route.InterfaceLuid
+		}
+
+		return a.route.NextHop.Address.String() < b.route.NextHop.Address.String()
+	})
+
+	return candidates
+}
+
+// Whether the most significant bit of the address is set, i.e. it belongs to the upper half of the address space.
+func isUpperHalf(ip net.IP) bool {
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4[0]&0x80 != 0
+	}
+
+	return len(ip) == net.IPv6len && ip[0]&0x80 != 0
+}
+
+// Returns the interface currently carrying default traffic of 'family' (AF_INET or AF_INET6), i.e. the "internet
+// uplink". Default routes from GetRoutes() are ranked taking into account IpInterface.Metric, OperStatus, Connected
+// and DisableDefaultRoutes of their interfaces.
+func GetPrimaryInterface(family AddressFamily) (*PrimaryInterface, error) {
+
+	if family != AF_INET && family != AF_INET6 {
+		return nil, newKindError(ErrInvalidParameter, "GetPrimaryInterface() - family has to be AF_INET or AF_INET6")
+	}
+
+	routes, err := GetRoutes(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ipifcs, err := GetIpInterfaces(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ifcs, err := GetInterfaces()
+
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := make(map[uint64]*defaultRouteInterface, len(ipifcs))
+
+	for _, ipifc := range ipifcs {
+		interfaces[ipifc.InterfaceLuid] = &defaultRouteInterface{
+			metric:               ipifc.Metric,
+			connected:            ipifc.Connected,
+			disableDefaultRoutes: ipifc.DisableDefaultRoutes,
+		}
+	}
+
+	ifcsByLuid := make(map[uint64]*Interface, len(ifcs))
+
+	for _, ifc := range ifcs {
+
+		ifcsByLuid[ifc.Luid] = ifc
+
+		if ifcData, ok := interfaces[ifc.Luid]; ok {
+			ifcData.up = ifc.OperStatus == IfOperStatusUp
+		}
+	}
+
+	for _, candidate := range rankDefaultRoutes(routes, family, interfaces) {
+
+		// The interface may have disappeared in the meantime.
+		ifc, ok := ifcsByLuid[candidate.route.InterfaceLuid]
+
+		if !ok {
+			continue
+		}
+
+		return &PrimaryInterface{
+			Interface: ifc,
+			Gateway:   candidate.route.NextHop.Address,
+			Metric:    uint32(candidate.metric),
+			Route:     candidate.route,
+		}, nil
+	}
+
+	return nil, newKindError(ErrNotFound, "GetPrimaryInterface() - no usable %s default route found",
+		family.String())
+}
+
+func (primary *PrimaryInterface) String() string {
+
+	if primary == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("Interface: %s; Gateway: %s; Metric: %d", primary.Interface.FriendlyName,
+		primary.Gateway.String(), primary.Metric)
+}
@@ -0,0 +1,201 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+const primaryInterface_print = false
+
+func fakeGatewayRoute(luid uint64, prefix string, metric uint32, gateway string) *Route {
+
+	route := fakeRoute(luid, prefix, metric)
+	route.NextHop = SockaddrInet{Family: route.DestinationPrefix.Prefix.Family, Address: net.ParseIP(gateway)}
+
+	return route
+}
+
+func usableInterface(metric uint32) *defaultRouteInterface {
+	return &defaultRouteInterface{metric: metric, up: true, connected: true}
+}
+
+func TestRankDefaultRoutes(t *testing.T) {
+
+	tests := []struct {
+		name string
+		// AF_INET if not set.
+		family     AddressFamily
+		routes     []*Route
+		interfaces map[uint64]*defaultRouteInterface
+		// Expected LUIDs and effective metrics, from the best to the worst.
+		luids   []uint64
+		metrics []uint64
+	}{
+		{
+			name: "lowest effective metric wins",
+			routes: []*Route{
+				fakeGatewayRoute(1, "0.0.0.0/0", 0, "192.168.1.1"),
+				fakeGatewayRoute(2, "0.0.0.0/0", 0, "10.0.0.1"),
+				fakeGatewayRoute(2, "10.0.0.0/8", 0, "0.0.0.0"),
+			},
+			interfaces: map[uint64]*defaultRouteInterface{1: usableInterface(35), 2: usableInterface(25)},
+			luids:      []uint64{2, 1},
+			metrics:    []uint64{25, 35},
+		},
+		{
+			name: "route metric counts",
+			routes: []*Route{
+				fakeGatewayRoute(1, "0.0.0.0/0", 0, "192.168.1.1"),
+				fakeGatewayRoute(2, "0.0.0.0/0", 20, "10.0.0.1"),
+			},
+			interfaces: map[uint64]*defaultRouteInterface{1: usableInterface(35), 2: usableInterface(25)},
+			luids:      []uint64{1, 2},
+			metrics:    []uint64{35, 45},
+		},
+		{
+			name: "ties are broken by LUID regardless of route order",
+			routes: []*Route{
+				fakeGatewayRoute(7, "0.0.0.0/0", 5, "10.0.0.1"),
+				fakeGatewayRoute(3, "0.0.0.0/0", 0, "192.168.1.1"),
+			},
+			interfaces: map[uint64]*defaultRouteInterface{3: usableInterface(25), 7: usableInterface(20)},
+			luids:      []uint64{3, 7},
+			metrics:    []uint64{25, 25},
+		},
+		{
+			name: "unusable interfaces are skipped",
+			routes: []*Route{
+				fakeGatewayRoute(1, "0.0.0.0/0", 0, "192.168.1.1"),
+				fakeGatewayRoute(2, "0.0.0.0/0", 0, "10.0.0.1"),
+				fakeGatewayRoute(3, "0.0.0.0/0", 0, "10.1.0.1"),
+				fakeGatewayRoute(4, "0.0.0.0/0", 0, "10.2.0.1"),
+				fakeGatewayRoute(5, "0.0.0.0/0", 0, "10.3.0.1"),
+			},
+			interfaces: map[uint64]*defaultRouteInterface{
+				1: usableInterface(50),
+				2: {metric: 1, up: false, connected: true},
+				3: {metric: 1, up: true, connected: false},
+				4: {metric: 1, up: true, connected: true, disableDefaultRoutes: true},
+			},
+			luids:   []uint64{1},
+			metrics: []uint64{50},
+		},
+		{
+			name: "half-default pair wins over default routes",
+			routes: []*Route{
+				fakeGatewayRoute(1, "0.0.0.0/0", 0, "192.168.1.1"),
+				fakeGatewayRoute(9, "128.0.0.0/1", 10, "0.0.0.0"),
+				fakeGatewayRoute(9, "0.0.0.0/1", 5, "0.0.0.0"),
+			},
+			interfaces: map[uint64]*defaultRouteInterface{1: usableInterface(5), 9: usableInterface(100)},
+			luids:      []uint64{9, 1},
+			metrics:    []uint64{110, 5},
+		},
+		{
+			name: "lone half-default route doesn't count",
+			routes: []*Route{
+				fakeGatewayRoute(1, "0.0.0.0/0", 0, "192.168.1.1"),
+				fakeGatewayRoute(9, "0.0.0.0/1", 0, "0.0.0.0"),
+				fakeGatewayRoute(8, "128.0.0.0/1", 0, "0.0.0.0"),
+			},
+			interfaces: map[uint64]*defaultRouteInterface{1: usableInterface(5), 8: usableInterface(1),
+				9: usableInterface(1)},
+			luids:   []uint64{1},
+			metrics: []uint64{5},
+		},
+		{
+			name:   "IPv6 half-default pair",
+			family: AF_INET6,
+			routes: []*Route{
+				fakeGatewayRoute(1, "::/0", 0, "fe80::1"),
+				fakeGatewayRoute(9, "::/1", 0, "::"),
+				fakeGatewayRoute(9, "8000::/1", 0, "::"),
+				fakeGatewayRoute(2, "0.0.0.0/0", 0, "10.0.0.1"),
+			},
+			interfaces: map[uint64]*defaultRouteInterface{1: usableInterface(5), 2: usableInterface(1),
+				9: usableInterface(1)},
+			luids:   []uint64{9, 1},
+			metrics: []uint64{1, 5},
+		},
+		{
+			name:       "no default routes",
+			routes:     []*Route{fakeGatewayRoute(1, "10.0.0.0/8", 0, "0.0.0.0")},
+			interfaces: map[uint64]*defaultRouteInterface{1: usableInterface(5)},
+		},
+	}
+
+	for _, test := range tests {
+
+		family := test.family
+
+		if family == AF_UNSPEC {
+			family = AF_INET
+		}
+
+		candidates := rankDefaultRoutes(test.routes, family, test.interfaces)
+
+		if len(candidates) != len(test.luids) {
+			t.Errorf("%s: rankDefaultRoutes() returned %d candidates, although %d are expected.", test.name,
+				len(candidates), len(test.luids))
+			continue
+		}
+
+		for i, candidate := range candidates {
+			if candidate.route.InterfaceLuid != test.luids[i] || candidate.metric != test.metrics[i] {
+				t.Errorf("%s: candidate #%d is interface %d with metric %d, although interface %d with metric %d is "+
+					"expected.", test.name, i, candidate.route.InterfaceLuid, candidate.metric, test.luids[i],
+					test.metrics[i])
+			}
+		}
+	}
+}
+
+func TestRankDefaultRoutesHalfDefaultRoute(t *testing.T) {
+
+	candidates := rankDefaultRoutes([]*Route{
+		fakeGatewayRoute(9, "128.0.0.0/1", 0, "10.8.0.1"),
+		fakeGatewayRoute(9, "0.0.0.0/1", 0, "10.8.0.1"),
+	}, AF_INET, map[uint64]*defaultRouteInterface{9: usableInterface(1)})
+
+	if len(candidates) != 1 || !candidates[0].halfDefault {
+		t.Fatalf("rankDefaultRoutes() returned %d candidates, although a single half-default one is expected.",
+			len(candidates))
+	}
+
+	if candidates[0].route.DestinationPrefix.Prefix.Address.To4()[0] != 0 {
+		t.Errorf("Half-default candidate holds route to %s, although the lower half is expected.",
+			candidates[0].route.DestinationPrefix.Prefix.Address.String())
+	}
+}
+
+func TestGetPrimaryInterface(t *testing.T) {
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+
+		primary, err := GetPrimaryInterface(family)
+
+		if err != nil {
+			// There may be no IPv6 connectivity.
+			t.Logf("GetPrimaryInterface(%s) returned an error: %v", family.String(), err)
+			continue
+		}
+
+		if primary.Interface == nil || primary.Route == nil {
+			t.Errorf("GetPrimaryInterface(%s) returned incomplete result: %s", family.String(), primary.String())
+		}
+
+		if primaryInterface_print {
+			fmt.Println(primary)
+		}
+	}
+
+	if _, err := GetPrimaryInterface(AF_UNSPEC); err == nil {
+		t.Error("GetPrimaryInterface(AF_UNSPEC) didn't return an error, although it's expected.")
+	}
+}
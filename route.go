@@ -65,6 +65,20 @@ func getRoute(interfaceLuid uint64, destination *net.IPNet, nextHop *net.IP) (*R
 	}
 }
 
+// Returns a deep copy of the route, which doesn't share address slices with it.
+func (route *Route) clone() *Route {
+
+	if route == nil {
+		return nil
+	}
+
+	clone := *route
+	clone.DestinationPrefix.Prefix.Address = append(net.IP(nil), route.DestinationPrefix.Prefix.Address...)
+	clone.NextHop.Address = append(net.IP(nil), route.NextHop.Address...)
+
+	return &clone
+}
+
 func (route *Route) copyChangeableFieldsTo(row *wtMibIpforwardRow2) {
 
 	row.SitePrefixLength = route.SitePrefixLength
@@ -7,48 +7,85 @@ package winipcfg
 
 import (
 	"golang.org/x/sys/windows"
-	"os"
-	"sync"
 	"unsafe"
 )
 
 type RouteChangeCallback struct {
-	cb func(notificationType MibNotificationType, route *Route)
+	cb         func(notificationType MibNotificationType, route *Route)
+	dispatcher *notificationDispatcher
+	subscriber *notificationSubscriber
+}
+
+type routeChange struct {
+	notificationType MibNotificationType
+	route            *Route
 }
 
 var (
-	routeChangeMutex     = sync.Mutex{}
-	routeChangeCallbacks = make(map[*RouteChangeCallback]bool)
-	routeChangeHandle    = uintptr(0)
+	routeChangeHandles    notificationHandles
+	routeChangeDispatcher *notificationDispatcher
 )
 
+func init() {
+	routeChangeDispatcher = newNotificationDispatcher(registerRouteChange,
+		func(family AddressFamily) error { return cancelChangeNotification(routeChangeHandles.of(family)) })
+}
+
+// Registers a callback which is called on every route change. The callback is called from its own goroutine (never
+// from the OS notification thread), notifications are delivered in order, and the callback may register and
+// unregister callbacks, including itself. Each callback receives its own copy of the route.
 func RegisterRouteChangeCallback(cb func(notificationType MibNotificationType, route *Route)) (*RouteChangeCallback, error) {
-	routeChangeMutex.Lock()
-	defer routeChangeMutex.Unlock()
-	s := &RouteChangeCallback{cb}
-	routeChangeCallbacks[s] = true
-	if routeChangeHandle == 0 {
-		result := notifyRouteChange2(AF_UNSPEC, windows.NewCallback(routeChanged), 0, false,
-			unsafe.Pointer(&routeChangeHandle))
-		if result != 0 {
-			delete(routeChangeCallbacks, s)
-			routeChangeHandle = 0
-			return nil, os.NewSyscallError("iphlpapi.NotifyRouteChange2", windows.Errno(result))
-		}
+	return RegisterRouteChangeCallbackEx(nil, cb)
+}
+
+// The same as RegisterRouteChangeCallback(), but the callback receives only changes matching 'filter' (nil matches
+// everything).
+func RegisterRouteChangeCallbackEx(filter *ChangeFilter, cb func(notificationType MibNotificationType,
+	route *Route)) (*RouteChangeCallback, error) {
+	return subscribeRouteChange(routeChangeDispatcher, filter, cb)
+}
+
+func subscribeRouteChange(dispatcher *notificationDispatcher, filter *ChangeFilter,
+	cb func(notificationType MibNotificationType, route *Route)) (*RouteChangeCallback, error) {
+	compiled, err := filter.compile(true)
+	if err != nil {
+		return nil, err
 	}
+	s := &RouteChangeCallback{cb: cb, dispatcher: dispatcher}
+	subscriber, err := dispatcher.Subscribe(compiled.families(), func(event interface{}) bool {
+		change := event.(*routeChange)
+		return compiled.matchesRoute(change.route, change.notificationType)
+	}, func(event interface{}) {
+		change := event.(*routeChange)
+		s.cb(change.notificationType, change.route.clone())
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.subscriber = subscriber
 	return s, nil
 }
 
+// Unregisters the callback, waiting for its running call (if any) to return, so the callback won't be called after
+// Unregister returns. Called from the callback itself, Unregister doesn't wait for it.
 func (cb *RouteChangeCallback) Unregister() error {
-	routeChangeMutex.Lock()
-	defer routeChangeMutex.Unlock()
-	delete(routeChangeCallbacks, cb)
-	if len(routeChangeCallbacks) == 0 && routeChangeHandle != 0 {
-		result := cancelMibChangeNotify2(routeChangeHandle)
-		if result != 0 {
-			return os.NewSyscallError("iphlpapi.CancelMibChangeNotify2", windows.Errno(result))
-		}
-		routeChangeHandle = uintptr(0)
+	return cb.dispatcher.Unsubscribe(cb.subscriber)
+}
+
+// Returns the number of notifications dropped because the callback didn't keep up with them.
+func (cb *RouteChangeCallback) Dropped() uint64 {
+	return cb.subscriber.Dropped()
+}
+
+func registerRouteChange(family AddressFamily) error {
+	handle := routeChangeHandles.of(family)
+	if *handle != 0 {
+		return nil
+	}
+	result := notifyRouteChange2(family, windows.NewCallback(routeChanged), 0, false, unsafe.Pointer(handle))
+	if result != 0 {
+		*handle = 0
+		return newOperationError("iphlpapi.NotifyRouteChange2", 0, "", windows.Errno(result))
 	}
 	return nil
 }
@@ -58,10 +95,6 @@ func routeChanged(callerContext unsafe.Pointer, wtr *wtMibIpforwardRow2, notific
 	if route == nil || err != nil {
 		return 0
 	}
-	routeChangeMutex.Lock()
-	for cb := range routeChangeCallbacks {
-		cb.cb(notificationType, route)
-	}
-	routeChangeMutex.Unlock()
+	routeChangeDispatcher.Dispatch(&routeChange{notificationType, route})
 	return 0
 }
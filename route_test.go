@@ -7,6 +7,7 @@ package winipcfg
 
 import (
 	"fmt"
+	"net"
 	"testing"
 )
 
@@ -34,3 +35,36 @@ func TestGetRoutes(t *testing.T) {
 		}
 	}
 }
+
+func TestRouteClone(t *testing.T) {
+
+	if (*Route)(nil).clone() != nil {
+		t.Error("clone() of nil returned non-nil.")
+	}
+
+	route := &Route{
+		InterfaceLuid: 42,
+		DestinationPrefix: IpAddressPrefix{
+			Prefix:       SockaddrInet{Family: AF_INET, Address: net.IP{10, 0, 0, 0}},
+			PrefixLength: 8,
+		},
+		NextHop: SockaddrInet{Family: AF_INET, Address: net.IP{10, 0, 0, 1}},
+		Metric:  5,
+	}
+
+	clone := route.clone()
+
+	if clone.InterfaceLuid != 42 || clone.Metric != 5 || clone.DestinationPrefix.PrefixLength != 8 ||
+		!clone.DestinationPrefix.Prefix.Address.Equal(net.IP{10, 0, 0, 0}) ||
+		!clone.NextHop.Address.Equal(net.IP{10, 0, 0, 1}) {
+		t.Errorf("clone() returned %v, although %v is expected.", clone, route)
+	}
+
+	clone.DestinationPrefix.Prefix.Address[0] = 192
+	clone.NextHop.Address[3] = 2
+
+	if !route.DestinationPrefix.Prefix.Address.Equal(net.IP{10, 0, 0, 0}) ||
+		!route.NextHop.Address.Equal(net.IP{10, 0, 0, 1}) {
+		t.Error("clone() returned a route sharing its addresses with the original.")
+	}
+}
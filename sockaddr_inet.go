@@ -78,7 +78,7 @@ func createSockaddrInet(ip net.IP) (*SockaddrInet, error) {
 		return &sainet, nil
 	}
 
-	return nil, fmt.Errorf("createSockaddrInet() - invalid input IP")
+	return nil, newKindError(ErrInvalidParameter, "createSockaddrInet() - invalid input IP")
 }
 
 func (sainet *SockaddrInet) toWtSockaddrInet() (*wtSockaddrInet, error) {
@@ -96,15 +96,15 @@ func (sainet *SockaddrInet) toWtSockaddrInet() (*wtSockaddrInet, error) {
 	if wtsainet.sin6_family != sainet.Family {
 		switch sainet.Family {
 		case AF_INET:
-			return nil,
-				fmt.Errorf("toWtSockaddrInet() - receiver argument family is AF_INET but its address isn't IPv4")
+			return nil, newKindError(ErrInvalidParameter,
+				"toWtSockaddrInet() - receiver argument family is AF_INET but its address isn't IPv4")
 		case AF_INET6:
-			return nil,
-				fmt.Errorf("toWtSockaddrInet() - receiver argument family is AF_INET6 but its address isn't IPv6")
+			return nil, newKindError(ErrInvalidParameter,
+				"toWtSockaddrInet() - receiver argument family is AF_INET6 but its address isn't IPv6")
 		default:
-			return nil,
-				fmt.Errorf("toWtSockaddrInet() - receiver argument family is %s but it has to be AF_INET or AF_INET6",
-					sainet.Family.String())
+			return nil, newKindError(ErrInvalidParameter,
+				"toWtSockaddrInet() - receiver argument family is %s but it has to be AF_INET or AF_INET6",
+				sainet.Family.String())
 		}
 	}
 
@@ -0,0 +1,110 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "github.com/starvpn/winipcfg-go/internal/sockettable"
+
+// Raw tables returned by GetExtendedTcpTable and GetExtendedUdpTable are decoded by package sockettable; the functions
+// below only convert its rows.
+
+func sockaddrInetFromEndpoint(family AddressFamily, endpoint *sockettable.Endpoint) SockaddrInet {
+	return SockaddrInet{
+		Family:      family,
+		Port:        endpoint.Port,
+		Address:     endpoint.Address,
+		IPv6ScopeId: endpoint.ScopeId,
+	}
+}
+
+// Decodes MIB_TCPTABLE_OWNER_MODULE (if 'family' is AF_INET) or MIB_TCP6TABLE_OWNER_MODULE (if it's AF_INET6).
+func parseTcpTableOwnerModule(family AddressFamily, table []byte) ([]*TcpConnection, error) {
+
+	parse := sockettable.ParseTcpTable
+
+	if family == AF_INET6 {
+		parse = sockettable.ParseTcp6Table
+	}
+
+	rows, err := parse(table)
+
+	if err != nil {
+		return nil, err
+	}
+
+	connections := make([]*TcpConnection, len(rows))
+
+	for i, row := range rows {
+		connections[i] = &TcpConnection{
+			Local:           sockaddrInetFromEndpoint(family, &row.Local),
+			Remote:          sockaddrInetFromEndpoint(family, &row.Remote),
+			State:           TcpState(row.State),
+			OwningPid:       row.OwningPid,
+			CreateTimestamp: row.CreateTimestamp,
+			rawRow:          row.Raw,
+		}
+	}
+
+	return connections, nil
+}
+
+// Decodes MIB_UDPTABLE_OWNER_MODULE (if 'family' is AF_INET) or MIB_UDP6TABLE_OWNER_MODULE (if it's AF_INET6).
+func parseUdpTableOwnerModule(family AddressFamily, table []byte) ([]*UdpEndpoint, error) {
+
+	parse := sockettable.ParseUdpTable
+
+	if family == AF_INET6 {
+		parse = sockettable.ParseUdp6Table
+	}
+
+	rows, err := parse(table)
+
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*UdpEndpoint, len(rows))
+
+	for i, row := range rows {
+		endpoints[i] = &UdpEndpoint{
+			Local:            sockaddrInetFromEndpoint(family, &row.Local),
+			OwningPid:        row.OwningPid,
+			CreateTimestamp:  row.CreateTimestamp,
+			SpecificPortBind: row.SpecificPortBind,
+			rawRow:           row.Raw,
+		}
+	}
+
+	return endpoints, nil
+}
+
+// Returns LUID of the interface which has 'local' address assigned, or 0 if there's no such interface (i.e. the
+// socket is bound to the unspecified address). IPv6 scope ID is taken into account for link-local addresses.
+func socketInterfaceLuid(local *SockaddrInet, addresses []*UnicastIpAddressRow) uint64 {
+
+	if local.Address == nil || local.Address.IsUnspecified() {
+		return 0
+	}
+
+	for _, address := range addresses {
+
+		if address == nil || address.Address == nil || address.Address.Family != local.Family {
+			continue
+		}
+
+		if !address.Address.Address.Equal(local.Address) {
+			continue
+		}
+
+		if local.Family == AF_INET6 && local.Address.IsLinkLocalUnicast() && local.IPv6ScopeId != 0 &&
+			address.ScopeId != local.IPv6ScopeId {
+			continue
+		}
+
+		return address.InterfaceLuid
+	}
+
+	return 0
+}
@@ -0,0 +1,77 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+func TestParseTcp6TableOwnerModule(t *testing.T) {
+
+	// A single MIB_TCP6ROW_OWNER_MODULE from fe80::1%12 port 50001 to 2001:db8::1 port 443; decoding itself is tested
+	// in package sockettable.
+	table := make([]byte, 8+192)
+	binary.LittleEndian.PutUint32(table, 1)
+	row := table[8:]
+	copy(row, net.ParseIP("fe80::1"))
+	binary.LittleEndian.PutUint32(row[16:], 12)
+	binary.BigEndian.PutUint16(row[20:], 50001)
+	copy(row[24:], net.ParseIP("2001:db8::1"))
+	binary.BigEndian.PutUint16(row[44:], 443)
+	binary.LittleEndian.PutUint32(row[48:], uint32(TcpStateEstab))
+	binary.LittleEndian.PutUint32(row[52:], 4321)
+
+	connections, err := parseTcpTableOwnerModule(AF_INET6, table)
+
+	if err != nil || len(connections) != 1 {
+		t.Fatalf("parseTcpTableOwnerModule() returned %v, %v, although a single connection is expected.",
+			connections, err)
+	}
+
+	c := connections[0]
+
+	if c.Local.Family != AF_INET6 || c.Local.Port != 50001 || c.Local.IPv6ScopeId != 12 ||
+		!c.Local.Address.Equal(net.ParseIP("fe80::1")) {
+		t.Errorf("Local is %s, although fe80::1%%12 port 50001 is expected.", c.Local.String())
+	}
+
+	if c.Remote.Family != AF_INET6 || c.Remote.Port != 443 || !c.Remote.Address.Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("Remote is %s, although 2001:db8::1 port 443 is expected.", c.Remote.String())
+	}
+
+	if c.State != TcpStateEstab || c.OwningPid != 4321 || len(c.rawRow) != 192 {
+		t.Errorf("Unexpected connection: %v", c)
+	}
+}
+
+func TestSocketInterfaceLuid(t *testing.T) {
+
+	addresses := []*UnicastIpAddressRow{
+		{Address: &SockaddrInet{Family: AF_INET, Address: net.ParseIP("10.0.0.5").To4()}, InterfaceLuid: 1},
+		{Address: &SockaddrInet{Family: AF_INET6, Address: net.ParseIP("fe80::1")}, InterfaceLuid: 2, ScopeId: 11},
+		{Address: &SockaddrInet{Family: AF_INET6, Address: net.ParseIP("fe80::1")}, InterfaceLuid: 3, ScopeId: 12},
+	}
+
+	tests := []struct {
+		local    SockaddrInet
+		expected uint64
+	}{
+		{SockaddrInet{Family: AF_INET, Address: net.ParseIP("10.0.0.5").To4()}, 1},
+		{SockaddrInet{Family: AF_INET, Address: net.ParseIP("0.0.0.0").To4()}, 0},
+		{SockaddrInet{Family: AF_INET, Address: net.ParseIP("10.0.0.6").To4()}, 0},
+		{SockaddrInet{Family: AF_INET6, Address: net.ParseIP("fe80::1"), IPv6ScopeId: 12}, 3},
+		{SockaddrInet{Family: AF_INET6, Address: net.ParseIP("::")}, 0},
+	}
+
+	for _, test := range tests {
+		if luid := socketInterfaceLuid(&test.local, addresses); luid != test.expected {
+			t.Errorf("socketInterfaceLuid(%s) returned %d, although %d is expected.", test.local.String(), luid,
+				test.expected)
+		}
+	}
+}
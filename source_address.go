@@ -0,0 +1,495 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Address scopes as defined in RFC 4291, section 2.7 (only those relevant to unicast source selection).
+const (
+	addressScopeLinkLocal = 0x2
+	addressScopeSiteLocal = 0x5
+	addressScopeGlobal    = 0xe
+)
+
+type addressLabel struct {
+	prefix *net.IPNet
+	label  int
+}
+
+// Default policy table labels from RFC 6724, section 2.1. IPv4 addresses are matched as IPv4-mapped IPv6 addresses.
+var defaultAddressLabels = []addressLabel{
+	{mustParseCIDR("::1/128"), 0},
+	{mustParseCIDR("::ffff:0:0/96"), 4},
+	{mustParseCIDR("2002::/16"), 2},
+	{mustParseCIDR("2001::/32"), 5},
+	{mustParseCIDR("fc00::/7"), 13},
+	{mustParseCIDR("::/96"), 3},
+	{mustParseCIDR("fec0::/10"), 11},
+	{mustParseCIDR("3ffe::/16"), 12},
+	{mustParseCIDR("::/0"), 1},
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+
+	_, ipnet, err := net.ParseCIDR(s)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return ipnet
+}
+
+// Result of source address prediction for a destination.
+type SourceAddressPrediction struct {
+	Destination net.IP
+	// The route selected for the destination (longest prefix, then lowest metric), or nil if no route matches.
+	Route *Route
+	// The source address selected by RFC 6724 rules.
+	Source *UnicastIpAddressRow
+	// The source address selected by the system (CreateSortedAddressPairs), or nil if it isn't available. In that case
+	// SystemError holds the reason.
+	SystemSource net.IP
+	SystemError  error
+}
+
+// Returns true if the system's choice is known and differs from the predicted one.
+func (p *SourceAddressPrediction) Mismatch() bool {
+
+	if p == nil || p.SystemSource == nil || p.Source == nil || p.Source.Address == nil {
+		return false
+	}
+
+	return !p.SystemSource.Equal(p.Source.Address.Address)
+}
+
+func (p *SourceAddressPrediction) String() string {
+
+	if p == nil {
+		return "<nil>"
+	}
+
+	source := "<nil>"
+
+	if p.Source != nil && p.Source.Address != nil {
+		source = p.Source.Address.Address.String()
+	}
+
+	system := "<nil>"
+
+	if p.SystemSource != nil {
+		system = p.SystemSource.String()
+	} else if p.SystemError != nil {
+		system = fmt.Sprintf("<%v>", p.SystemError)
+	}
+
+	return fmt.Sprintf("Destination: %s\nSource: %s\nSystemSource: %s", p.Destination.String(), source, system)
+}
+
+// Predicts the source address the system selects for 'destination', by taking current unicast address and route
+// snapshots and applying SelectSourceAddress(). The prediction is cross-checked against CreateSortedAddressPairs
+// function (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-createsortedaddresspairs);
+// use SourceAddressPrediction.Mismatch() to find out if they disagree.
+func PredictSourceAddress(destination net.IP) (*SourceAddressPrediction, error) {
+
+	family := ipFamily(destination)
+
+	if family == AF_UNSPEC {
+		return nil, newKindError(ErrInvalidParameter, "PredictSourceAddress() - invalid destination IP")
+	}
+
+	addresses, err := GetUnicastAddresses(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := GetRoutes(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prediction, err := SelectSourceAddress(destination, addresses, routes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	prediction.SystemSource, prediction.SystemError = getSystemSourceAddress(destination)
+
+	return prediction, nil
+}
+
+// Selects the source address for 'destination' among 'addresses' by applying rules from RFC 6724, section 5. The
+// outgoing interface is determined from 'routes'. Addresses marked SkipAsSource, and addresses which aren't usable
+// (tentative, duplicate or invalid) are never selected. Rules 4 (home addresses) and 5.5 (next-hop advertised
+// prefixes) aren't applied, since the required information isn't available in the snapshots. This is a pure
+// function; see PredictSourceAddress() for a variant which works on the current system state.
+func SelectSourceAddress(destination net.IP, addresses []*UnicastIpAddressRow, routes []*Route) (*SourceAddressPrediction,
+	error) {
+
+	family := ipFamily(destination)
+
+	if family == AF_UNSPEC {
+		return nil, newKindError(ErrInvalidParameter, "SelectSourceAddress() - invalid destination IP")
+	}
+
+	prediction := &SourceAddressPrediction{Destination: destination, Route: selectRoute(destination, routes)}
+
+	var best *UnicastIpAddressRow
+
+	for _, candidate := range addresses {
+
+		if !isSourceCandidate(candidate, family) {
+			continue
+		}
+
+		if best == nil || preferSource(candidate, best, destination, prediction.Route) {
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		return nil, newKindError(ErrNotFound, "SelectSourceAddress() - no candidate source address for %s",
+			destination.String())
+	}
+
+	prediction.Source = best
+
+	return prediction, nil
+}
+
+func ipFamily(ip net.IP) AddressFamily {
+
+	if ip.To4() != nil {
+		return AF_INET
+	}
+
+	if ip.To16() != nil {
+		return AF_INET6
+	}
+
+	return AF_UNSPEC
+}
+
+// Selects the route with the longest matching prefix, then with the lowest metric.
+func selectRoute(destination net.IP, routes []*Route) *Route {
+
+	family := ipFamily(destination)
+
+	var best *Route
+
+	for _, route := range routes {
+
+		if route == nil || route.DestinationPrefix.Prefix.Family != family {
+			continue
+		}
+
+		ipnet, err := route.DestinationPrefix.toNetIpNet()
+
+		if err != nil || !ipnet.Contains(destination) {
+			continue
+		}
+
+		if best == nil || route.DestinationPrefix.PrefixLength > best.DestinationPrefix.PrefixLength ||
+			(route.DestinationPrefix.PrefixLength == best.DestinationPrefix.PrefixLength && route.Metric < best.Metric) {
+			best = route
+		}
+	}
+
+	return best
+}
+
+func isSourceCandidate(address *UnicastIpAddressRow, family AddressFamily) bool {
+
+	if address == nil || address.Address == nil || address.SkipAsSource {
+		return false
+	}
+
+	if ipFamily(address.Address.Address) != family {
+		return false
+	}
+
+	return address.DadState == IpDadStatePreferred || address.DadState == IpDadStateDeprecated
+}
+
+func isDeprecatedAddress(address *UnicastIpAddressRow) bool {
+	return address.DadState == IpDadStateDeprecated || address.PreferredLifetime == 0
+}
+
+// Returns true if 'a' is a better source address than 'b' for 'destination' according to RFC 6724, section 5.
+func preferSource(a, b *UnicastIpAddressRow, destination net.IP, route *Route) bool {
+
+	ipA := a.Address.Address
+	ipB := b.Address.Address
+
+	// Rule 1: Prefer same address.
+	if sameA, sameB := ipA.Equal(destination), ipB.Equal(destination); sameA != sameB {
+		return sameA
+	}
+
+	// Rule 2: Prefer appropriate scope.
+	scopeA, scopeB, scopeD := addressScope(ipA), addressScope(ipB), addressScope(destination)
+
+	if scopeA < scopeB {
+		return scopeA >= scopeD
+	}
+
+	if scopeB < scopeA {
+		return scopeB < scopeD
+	}
+
+	// Rule 3: Avoid deprecated addresses.
+	if deprecatedA, deprecatedB := isDeprecatedAddress(a), isDeprecatedAddress(b); deprecatedA != deprecatedB {
+		return deprecatedB
+	}
+
+	// Rule 5: Prefer outgoing interface.
+	if route != nil {
+		if outA, outB := a.InterfaceLuid == route.InterfaceLuid, b.InterfaceLuid == route.InterfaceLuid; outA != outB {
+			return outA
+		}
+	}
+
+	// Rule 6: Prefer matching label.
+	labelD := addressLabelOf(destination)
+
+	if matchA, matchB := addressLabelOf(ipA) == labelD, addressLabelOf(ipB) == labelD; matchA != matchB {
+		return matchA
+	}
+
+	// Rule 7: Prefer temporary addresses.
+	if tempA, tempB := a.SuffixOrigin == IpSuffixOriginRandom, b.SuffixOrigin == IpSuffixOriginRandom; tempA != tempB {
+		return tempA
+	}
+
+	// Rule 8: Use longest matching prefix.
+	return sourcePrefixMatch(a, destination) > sourcePrefixMatch(b, destination)
+}
+
+func addressScope(ip net.IP) int {
+
+	if ip4 := ip.To4(); ip4 != nil {
+		// RFC 6724, section 3.2: loopback and auto-configuration addresses have link-local scope.
+		if ip4[0] == 127 || (ip4[0] == 169 && ip4[1] == 254) {
+			return addressScopeLinkLocal
+		}
+		return addressScopeGlobal
+	}
+
+	if ip.IsMulticast() {
+		return int(ip[1] & 0x0f)
+	}
+
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return addressScopeLinkLocal
+	}
+
+	if ip[0] == 0xfe && ip[1]&0xc0 == 0xc0 {
+		return addressScopeSiteLocal
+	}
+
+	return addressScopeGlobal
+}
+
+func addressLabelOf(ip net.IP) int {
+
+	ip16 := ip.To16()
+
+	for _, entry := range defaultAddressLabels {
+		if entry.prefix.Contains(ip16) {
+			return entry.label
+		}
+	}
+
+	return 1
+}
+
+// Returns the length of the common prefix of the address and 'destination', limited to the address's on-link prefix
+// length.
+func sourcePrefixMatch(address *UnicastIpAddressRow, destination net.IP) int {
+
+	ip := address.Address.Address
+
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		destination = destination.To4()
+	} else {
+		ip = ip.To16()
+		destination = destination.To16()
+	}
+
+	length := 0
+
+	for i := 0; i < len(ip) && i < len(destination); i++ {
+
+		x := ip[i] ^ destination[i]
+
+		if x == 0 {
+			length += 8
+			continue
+		}
+
+		for x&0x80 == 0 {
+			length++
+			x <<= 1
+		}
+
+		break
+	}
+
+	if length > int(address.OnLinkPrefixLength) {
+		length = int(address.OnLinkPrefixLength)
+	}
+
+	return length
+}
+
+func getSystemSourceAddress(destination net.IP) (net.IP, error) {
+
+	ip16 := destination.To16()
+
+	if ip16 == nil {
+		return nil, newKindError(ErrInvalidParameter, "getSystemSourceAddress() - invalid destination IP")
+	}
+
+	if err := procCreateSortedAddressPairs.Find(); err != nil {
+		return nil, err
+	}
+
+	// IPv4 destinations are passed as IPv4-mapped IPv6 addresses, which is what To16() returns.
+	dest := wtSockaddrIn6{sin6_family: AF_INET6}
+	copy(dest.sin6_addr.Byte[:], ip16)
+
+	var pPairs *wtSockaddrIn6Pair = nil
+	count := uint32(0)
+
+	result := createSortedAddressPairs(nil, 0, &dest, 1, 0, unsafe.Pointer(&pPairs), &count)
+
+	if result != 0 {
+		return nil, newOperationError("iphlpapi.CreateSortedAddressPairs", 0, "", windows.Errno(result))
+	}
+
+	defer freeMibTable(unsafe.Pointer(pPairs))
+
+	if count == 0 || pPairs == nil || pPairs.SourceAddress == nil {
+		return nil, newKindError(ErrNotFound, "getSystemSourceAddress() - no source address for %s",
+			destination.String())
+	}
+
+	source := pPairs.SourceAddress.sin6_addr.toNetIp()
+
+	if source.IsUnspecified() {
+		return nil, newKindError(ErrNotFound, "getSystemSourceAddress() - no source address for %s",
+			destination.String())
+	}
+
+	if destination.To4() != nil {
+		return source.To4(), nil
+	}
+
+	return source, nil
+}
+
+// Sets SkipAsSource flag of the interface's unicast address 'ip'. Addresses with the flag set aren't selected as a
+// source address, unless an application explicitly binds to them.
+func (ifc *Interface) SetAddressSkipAsSource(ip *net.IP, skip bool) error {
+
+	row, err := getWtMibUnicastipaddressRow(ifc.Luid, ip)
+
+	if err != nil {
+		return err
+	}
+
+	if (row.SkipAsSource != 0) == skip {
+		return nil
+	}
+
+	row.SkipAsSource = boolToUint8(skip)
+
+	return row.set()
+}
+
+// Makes 'ip' the only interface's address of its family and scope which can be selected as a source address, by
+// clearing its SkipAsSource flag and setting the flag on the others. Addresses of other scopes (i.e. IPv6 link-local
+// addresses when 'ip' is global) aren't touched, since they're selected only for destinations of their own scope.
+func (ifc *Interface) PreferSourceAddress(ip *net.IP) error {
+
+	family := ipFamily(*ip)
+
+	if family == AF_UNSPEC {
+		return newKindError(ErrInvalidParameter, "Interface.PreferSourceAddress() - invalid IP")
+	}
+
+	rows, err := getWtMibUnicastipaddressRows(family)
+
+	if err != nil {
+		return err
+	}
+
+	scope := addressScope(*ip)
+	found := false
+
+	for _, row := range rows {
+		if row.InterfaceLuid == ifc.Luid && row.Address.matches(ip) {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return newKindError(ErrNotFound,
+			"Interface.PreferSourceAddress() - address %s not found on the interface", ip.String())
+	}
+
+	var errs []error
+
+	for _, row := range rows {
+
+		if row.InterfaceLuid != ifc.Luid {
+			continue
+		}
+
+		address, err := row.toUnicastIpAddressRow()
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if addressScope(address.Address.Address) != scope {
+			continue
+		}
+
+		skip := !row.Address.matches(ip)
+
+		if (row.SkipAsSource != 0) == skip {
+			continue
+		}
+
+		row.SkipAsSource = boolToUint8(skip)
+
+		if err := row.set(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return multiError(errs)
+	}
+}
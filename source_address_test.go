@@ -0,0 +1,217 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+const (
+	sourceAddress_print = false
+)
+
+func fakeUnicastAddress(luid uint64, ip string, prefixLength uint8) *UnicastIpAddressRow {
+
+	address := net.ParseIP(ip)
+	family := AF_INET6
+
+	if address.To4() != nil {
+		family = AF_INET
+	}
+
+	return &UnicastIpAddressRow{
+		Address:            &SockaddrInet{Family: family, Address: address},
+		InterfaceLuid:      luid,
+		OnLinkPrefixLength: prefixLength,
+		PreferredLifetime:  InfiniteAddressLifetime,
+		ValidLifetime:      InfiniteAddressLifetime,
+		DadState:           IpDadStatePreferred,
+	}
+}
+
+func fakeRoute(luid uint64, prefix string, metric uint32) *Route {
+
+	_, ipnet, _ := net.ParseCIDR(prefix)
+	length, _ := ipnet.Mask.Size()
+	family := AF_INET6
+
+	if ipnet.IP.To4() != nil {
+		family = AF_INET
+	}
+
+	return &Route{
+		InterfaceLuid: luid,
+		DestinationPrefix: IpAddressPrefix{
+			Prefix:       SockaddrInet{Family: family, Address: ipnet.IP},
+			PrefixLength: uint8(length),
+		},
+		Metric: metric,
+	}
+}
+
+func expectSource(t *testing.T, destination string, addresses []*UnicastIpAddressRow, routes []*Route,
+	expected string) {
+
+	prediction, err := SelectSourceAddress(net.ParseIP(destination), addresses, routes)
+
+	if err != nil {
+		t.Errorf("SelectSourceAddress() for %s returned an error: %v", destination, err)
+		return
+	}
+
+	if !prediction.Source.Address.Address.Equal(net.ParseIP(expected)) {
+		t.Errorf("SelectSourceAddress() for %s selected %s, although %s is expected.", destination,
+			prediction.Source.Address.Address.String(), expected)
+	}
+}
+
+func TestSelectRoute(t *testing.T) {
+
+	routes := []*Route{
+		fakeRoute(1, "0.0.0.0/0", 25),
+		fakeRoute(2, "0.0.0.0/1", 5),
+		fakeRoute(2, "128.0.0.0/1", 5),
+		fakeRoute(3, "10.0.0.0/8", 10),
+		fakeRoute(4, "10.0.0.0/8", 1),
+	}
+
+	tests := []struct {
+		destination string
+		luid        uint64
+	}{
+		{"8.8.8.8", 2},
+		{"200.1.1.1", 2},
+		{"10.1.2.3", 4},
+	}
+
+	for _, test := range tests {
+
+		route := selectRoute(net.ParseIP(test.destination), routes)
+
+		if route == nil || route.InterfaceLuid != test.luid {
+			t.Errorf("selectRoute() for %s returned %v, although route through %d is expected.", test.destination,
+				route, test.luid)
+		}
+	}
+
+	if route := selectRoute(net.ParseIP("2001:db8::1"), routes); route != nil {
+		t.Errorf("selectRoute() returned %v for IPv6 destination, although nil is expected.", route)
+	}
+}
+
+func TestSelectSourceAddressSkipAsSource(t *testing.T) {
+
+	management := fakeUnicastAddress(1, "10.10.0.2", 24)
+	management.SkipAsSource = true
+	routable := fakeUnicastAddress(1, "10.20.0.2", 24)
+	routes := []*Route{fakeRoute(1, "0.0.0.0/0", 0)}
+
+	// Management address matches the destination better, but it's excluded.
+	expectSource(t, "10.10.0.100", []*UnicastIpAddressRow{management, routable}, routes, "10.20.0.2")
+
+	routable.SkipAsSource = true
+
+	if _, err := SelectSourceAddress(net.ParseIP("10.10.0.100"), []*UnicastIpAddressRow{management, routable},
+		routes); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SelectSourceAddress() without candidates returned %v, although ErrNotFound is expected.", err)
+	}
+}
+
+func TestSelectSourceAddressRules(t *testing.T) {
+
+	routes := []*Route{fakeRoute(1, "::/0", 0), fakeRoute(2, "fd00:2::/64", 0), fakeRoute(1, "0.0.0.0/0", 0)}
+
+	// Rule 1: same address.
+	expectSource(t, "2001:db8::2", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "2001:db8::1", 64),
+		fakeUnicastAddress(2, "2001:db8::2", 64),
+	}, routes, "2001:db8::2")
+
+	// Rule 2: appropriate scope.
+	expectSource(t, "2001:db8::1", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "fe80::1", 64),
+		fakeUnicastAddress(1, "2001:db8:1::1", 64),
+	}, routes, "2001:db8:1::1")
+	expectSource(t, "fe80::2", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "2001:db8:1::1", 64),
+		fakeUnicastAddress(1, "fe80::1", 64),
+	}, routes, "fe80::1")
+
+	// Rule 3: avoid deprecated addresses.
+	deprecated := fakeUnicastAddress(1, "2001:db8::1", 64)
+	deprecated.DadState = IpDadStateDeprecated
+	expectSource(t, "2001:db8::100", []*UnicastIpAddressRow{
+		deprecated,
+		fakeUnicastAddress(1, "2001:db9::1", 64),
+	}, routes, "2001:db9::1")
+
+	// Rule 5: outgoing interface.
+	expectSource(t, "fd00:2::100", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "fd00:2::1", 64),
+		fakeUnicastAddress(2, "fd00:3::1", 64),
+	}, routes, "fd00:3::1")
+
+	// Rule 6: matching label (ULA destination prefers ULA source).
+	expectSource(t, "fd00:9::1", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "2001:db8::1", 64),
+		fakeUnicastAddress(1, "fd00:1::1", 64),
+	}, routes, "fd00:1::1")
+
+	// Rule 7: temporary addresses.
+	temporary := fakeUnicastAddress(1, "2001:db8::2", 64)
+	temporary.SuffixOrigin = IpSuffixOriginRandom
+	expectSource(t, "2001:db9::1", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "2001:db8::1", 64),
+		temporary,
+	}, routes, "2001:db8::2")
+
+	// Rule 8: longest matching prefix, limited to the on-link prefix length.
+	expectSource(t, "10.1.2.3", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "192.168.1.2", 24),
+		fakeUnicastAddress(1, "10.1.0.2", 16),
+	}, routes, "10.1.0.2")
+	expectSource(t, "10.1.2.3", []*UnicastIpAddressRow{
+		fakeUnicastAddress(1, "10.9.0.2", 8),
+		fakeUnicastAddress(1, "10.1.0.2", 8),
+	}, routes, "10.9.0.2")
+}
+
+func TestSourcePrefixMatch(t *testing.T) {
+
+	address := fakeUnicastAddress(1, "10.1.0.2", 32)
+
+	if length := sourcePrefixMatch(address, net.ParseIP("10.1.128.1")); length != 16 {
+		t.Errorf("sourcePrefixMatch() returned %d, although 16 is expected.", length)
+	}
+
+	address.OnLinkPrefixLength = 8
+
+	if length := sourcePrefixMatch(address, net.ParseIP("10.1.128.1")); length != 8 {
+		t.Errorf("sourcePrefixMatch() returned %d, although 8 is expected.", length)
+	}
+}
+
+func TestPredictSourceAddress(t *testing.T) {
+
+	prediction, err := PredictSourceAddress(net.ParseIP("8.8.8.8"))
+
+	if err != nil {
+		t.Errorf("PredictSourceAddress() returned an error: %v", err)
+		return
+	}
+
+	if prediction.SystemError != nil {
+		t.Logf("CreateSortedAddressPairs() isn't available: %v", prediction.SystemError)
+	} else if prediction.Mismatch() {
+		t.Logf("Predicted source differs from the system's choice:\n%s", prediction.String())
+	}
+
+	if sourceAddress_print {
+		t.Logf("Source address prediction:\n%s", prediction.String())
+	}
+}
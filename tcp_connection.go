@@ -0,0 +1,124 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "fmt"
+
+// Corresponds to MIB_TCPROW_OWNER_MODULE and MIB_TCP6ROW_OWNER_MODULE defined in tcpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/tcpmib/ns-tcpmib-mib_tcprow_owner_module).
+type TcpConnection struct {
+	Local     SockaddrInet
+	Remote    SockaddrInet
+	State     TcpState
+	OwningPid uint32
+	// Time the socket was created, as FILETIME.
+	CreateTimestamp int64
+
+	// Name and path of the owning module (for service hosted sockets - the service name). Empty if the owning module
+	// couldn't be determined (i.e. for System and Idle processes).
+	ModuleName string
+	ModulePath string
+
+	// LUID of the interface whose unicast address the socket is bound to, or 0 if it's bound to the unspecified
+	// address.
+	InterfaceLuid uint64
+
+	rawRow []byte
+}
+
+// Returns all TCP connections (including listening sockets) together with their owning processes. Argument 'family'
+// can be AF_INET, AF_INET6 or AF_UNSPEC (both). Corresponds to GetExtendedTcpTable function with
+// TCP_TABLE_OWNER_MODULE_ALL class
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getextendedtcptable).
+func GetTcpConnections(family AddressFamily) ([]*TcpConnection, error) {
+
+	families, err := socketTableFamilies(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := GetUnicastAddresses(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var connections []*TcpConnection
+
+	for _, f := range families {
+
+		table, err := getExtendedTcpTableBytes(f)
+
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseTcpTableOwnerModule(f, table)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, connection := range parsed {
+			connection.ModuleName, connection.ModulePath = getTcpOwnerModule(f, connection.rawRow)
+			connection.InterfaceLuid = socketInterfaceLuid(&connection.Local, addresses)
+			connection.rawRow = nil
+		}
+
+		connections = append(connections, parsed...)
+	}
+
+	return connections, nil
+}
+
+// Returns TCP connections bound to the interface's addresses.
+func (ifc *Interface) GetTcpConnections(family AddressFamily) ([]*TcpConnection, error) {
+
+	connections, err := GetTcpConnections(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*TcpConnection, 0, len(connections))
+
+	for _, connection := range connections {
+		if connection.InterfaceLuid == ifc.Luid {
+			matches = append(matches, connection)
+		}
+	}
+
+	return matches, nil
+}
+
+func socketTableFamilies(family AddressFamily) ([]AddressFamily, error) {
+	switch family {
+	case AF_INET, AF_INET6:
+		return []AddressFamily{family}, nil
+	case AF_UNSPEC:
+		return []AddressFamily{AF_INET, AF_INET6}, nil
+	default:
+		return nil, newKindError(ErrInvalidParameter, "argument 'family' has to be AF_INET, AF_INET6 or AF_UNSPEC")
+	}
+}
+
+func (c *TcpConnection) String() string {
+
+	if c == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf(`Local: %s
+Remote: %s
+State: %s
+OwningPid: %d
+CreateTimestamp: %d
+ModuleName: %s
+ModulePath: %s
+InterfaceLuid: %d`, c.Local.String(), c.Remote.String(), c.State.String(), c.OwningPid, c.CreateTimestamp,
+		c.ModuleName, c.ModulePath, c.InterfaceLuid)
+}
@@ -0,0 +1,49 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"testing"
+)
+
+const tcpConnection_print = false
+
+func TestGetTcpConnections(t *testing.T) {
+
+	connections, err := GetTcpConnections(AF_UNSPEC)
+
+	if err != nil {
+		t.Errorf("GetTcpConnections() returned an error: %v", err)
+		return
+	}
+
+	if tcpConnection_print {
+		for _, connection := range connections {
+			fmt.Println("====================== TCP CONNECTION OUTPUT START =====================")
+			fmt.Println(connection)
+			fmt.Println("======================= TCP CONNECTION OUTPUT END ======================")
+		}
+	}
+}
+
+func TestGetUdpEndpoints(t *testing.T) {
+
+	endpoints, err := GetUdpEndpoints(AF_UNSPEC)
+
+	if err != nil {
+		t.Errorf("GetUdpEndpoints() returned an error: %v", err)
+		return
+	}
+
+	if tcpConnection_print {
+		for _, endpoint := range endpoints {
+			fmt.Println("======================= UDP ENDPOINT OUTPUT START ======================")
+			fmt.Println(endpoint)
+			fmt.Println("======================== UDP ENDPOINT OUTPUT END =======================")
+		}
+	}
+}
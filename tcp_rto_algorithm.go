@@ -0,0 +1,34 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "fmt"
+
+// TCP_RTO_ALGORITHM defined in tcpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/tcpmib/ne-tcpmib-tcp_rto_algorithm)
+type TcpRtoAlgorithm uint32
+
+const (
+	TcpRtoAlgorithmOther    TcpRtoAlgorithm = 1
+	TcpRtoAlgorithmConstant TcpRtoAlgorithm = 2
+	TcpRtoAlgorithmRsre     TcpRtoAlgorithm = 3
+	TcpRtoAlgorithmVanj     TcpRtoAlgorithm = 4
+)
+
+func (a TcpRtoAlgorithm) String() string {
+	switch a {
+	case TcpRtoAlgorithmOther:
+		return "TcpRtoAlgorithmOther"
+	case TcpRtoAlgorithmConstant:
+		return "TcpRtoAlgorithmConstant"
+	case TcpRtoAlgorithmRsre:
+		return "TcpRtoAlgorithmRsre"
+	case TcpRtoAlgorithmVanj:
+		return "TcpRtoAlgorithmVanj"
+	default:
+		return fmt.Sprintf("TcpRtoAlgorithm_UNKNOWN(%d)", a)
+	}
+}
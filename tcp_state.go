@@ -0,0 +1,58 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "fmt"
+
+// MIB_TCP_STATE defined in tcpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/tcpmib/ns-tcpmib-mib_tcprow_lh)
+type TcpState uint32
+
+const (
+	TcpStateClosed    TcpState = 1
+	TcpStateListen    TcpState = 2
+	TcpStateSynSent   TcpState = 3
+	TcpStateSynRcvd   TcpState = 4
+	TcpStateEstab     TcpState = 5
+	TcpStateFinWait1  TcpState = 6
+	TcpStateFinWait2  TcpState = 7
+	TcpStateCloseWait TcpState = 8
+	TcpStateClosing   TcpState = 9
+	TcpStateLastAck   TcpState = 10
+	TcpStateTimeWait  TcpState = 11
+	TcpStateDeleteTcb TcpState = 12
+)
+
+func (s TcpState) String() string {
+	switch s {
+	case TcpStateClosed:
+		return "TcpStateClosed"
+	case TcpStateListen:
+		return "TcpStateListen"
+	case TcpStateSynSent:
+		return "TcpStateSynSent"
+	case TcpStateSynRcvd:
+		return "TcpStateSynRcvd"
+	case TcpStateEstab:
+		return "TcpStateEstab"
+	case TcpStateFinWait1:
+		return "TcpStateFinWait1"
+	case TcpStateFinWait2:
+		return "TcpStateFinWait2"
+	case TcpStateCloseWait:
+		return "TcpStateCloseWait"
+	case TcpStateClosing:
+		return "TcpStateClosing"
+	case TcpStateLastAck:
+		return "TcpStateLastAck"
+	case TcpStateTimeWait:
+		return "TcpStateTimeWait"
+	case TcpStateDeleteTcb:
+		return "TcpStateDeleteTcb"
+	default:
+		return fmt.Sprintf("TcpState_UNKNOWN(%d)", s)
+	}
+}
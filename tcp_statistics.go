@@ -0,0 +1,71 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "fmt"
+
+// Corresponds to MIB_TCPSTATS2 defined in tcpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/tcpmib/ns-tcpmib-mib_tcpstats2).
+type TcpStatistics struct {
+	Family AddressFamily
+
+	RtoAlgorithm TcpRtoAlgorithm
+	RtoMin       uint32
+	RtoMax       uint32
+	// Maximum number of connections. 0xFFFFFFFF means the maximum is dynamic.
+	MaxConn      uint32
+	ActiveOpens  uint32
+	PassiveOpens uint32
+	AttemptFails uint32
+	EstabResets  uint32
+	CurrEstab    uint32
+	InSegs       uint64
+	OutSegs      uint64
+	RetransSegs  uint32
+	InErrs       uint32
+	OutRsts      uint32
+	NumConns     uint32
+}
+
+// Returns TCP statistics for the specified address family, which has to be either AF_INET or AF_INET6. Corresponds to
+// GetTcpStatisticsEx2 function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-gettcpstatisticsex2).
+func GetTcpStatistics(family AddressFamily) (*TcpStatistics, error) {
+
+	stats, err := getWtMibTcpstats2(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.toTcpStatistics(family), nil
+}
+
+func (stats *TcpStatistics) String() string {
+
+	if stats == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf(`Family: %s
+RtoAlgorithm: %s
+RtoMin: %d
+RtoMax: %d
+MaxConn: %d
+ActiveOpens: %d
+PassiveOpens: %d
+AttemptFails: %d
+EstabResets: %d
+CurrEstab: %d
+InSegs: %d
+OutSegs: %d
+RetransSegs: %d
+InErrs: %d
+OutRsts: %d
+NumConns: %d`, stats.Family.String(), stats.RtoAlgorithm.String(), stats.RtoMin, stats.RtoMax, stats.MaxConn,
+		stats.ActiveOpens, stats.PassiveOpens, stats.AttemptFails, stats.EstabResets, stats.CurrEstab, stats.InSegs,
+		stats.OutSegs, stats.RetransSegs, stats.InErrs, stats.OutRsts, stats.NumConns)
+}
@@ -0,0 +1,46 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"testing"
+)
+
+const tcpStatistics_print = false
+
+func TestGetTcpStatistics(t *testing.T) {
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+
+		stats, err := GetTcpStatistics(family)
+
+		if err != nil {
+			t.Errorf("GetTcpStatistics(%s) returned an error: %v", family.String(), err)
+			continue
+		}
+
+		if stats == nil || stats.Family != family {
+			t.Errorf("GetTcpStatistics(%s) returned unexpected result: %v", family.String(), stats)
+			continue
+		}
+
+		if tcpStatistics_print {
+			fmt.Println("======================= TCP STATISTICS OUTPUT START =======================")
+			fmt.Println(stats)
+			fmt.Println("======================== TCP STATISTICS OUTPUT END ========================")
+		}
+	}
+}
+
+func TestGetTcpStatisticsInvalidFamily(t *testing.T) {
+
+	_, err := GetTcpStatistics(AF_UNSPEC)
+
+	if err == nil {
+		t.Error("GetTcpStatistics(AF_UNSPEC) didn't return an error.")
+	}
+}
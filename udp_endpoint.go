@@ -0,0 +1,110 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "fmt"
+
+// Corresponds to MIB_UDPROW_OWNER_MODULE and MIB_UDP6ROW_OWNER_MODULE defined in udpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/udpmib/ns-udpmib-mib_udprow_owner_module).
+type UdpEndpoint struct {
+	Local     SockaddrInet
+	OwningPid uint32
+	// Time the socket was created, as FILETIME.
+	CreateTimestamp  int64
+	SpecificPortBind bool
+
+	// Name and path of the owning module (for service hosted sockets - the service name). Empty if the owning module
+	// couldn't be determined (i.e. for System and Idle processes).
+	ModuleName string
+	ModulePath string
+
+	// LUID of the interface whose unicast address the socket is bound to, or 0 if it's bound to the unspecified
+	// address.
+	InterfaceLuid uint64
+
+	rawRow []byte
+}
+
+// Returns all UDP endpoints together with their owning processes. Argument 'family' can be AF_INET, AF_INET6 or
+// AF_UNSPEC (both). Corresponds to GetExtendedUdpTable function with UDP_TABLE_OWNER_MODULE class
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getextendedudptable).
+func GetUdpEndpoints(family AddressFamily) ([]*UdpEndpoint, error) {
+
+	families, err := socketTableFamilies(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := GetUnicastAddresses(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*UdpEndpoint
+
+	for _, f := range families {
+
+		table, err := getExtendedUdpTableBytes(f)
+
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseUdpTableOwnerModule(f, table)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, endpoint := range parsed {
+			endpoint.ModuleName, endpoint.ModulePath = getUdpOwnerModule(f, endpoint.rawRow)
+			endpoint.InterfaceLuid = socketInterfaceLuid(&endpoint.Local, addresses)
+			endpoint.rawRow = nil
+		}
+
+		endpoints = append(endpoints, parsed...)
+	}
+
+	return endpoints, nil
+}
+
+// Returns UDP endpoints bound to the interface's addresses.
+func (ifc *Interface) GetUdpEndpoints(family AddressFamily) ([]*UdpEndpoint, error) {
+
+	endpoints, err := GetUdpEndpoints(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*UdpEndpoint, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		if endpoint.InterfaceLuid == ifc.Luid {
+			matches = append(matches, endpoint)
+		}
+	}
+
+	return matches, nil
+}
+
+func (e *UdpEndpoint) String() string {
+
+	if e == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf(`Local: %s
+OwningPid: %d
+CreateTimestamp: %d
+SpecificPortBind: %v
+ModuleName: %s
+ModulePath: %s
+InterfaceLuid: %d`, e.Local.String(), e.OwningPid, e.CreateTimestamp, e.SpecificPortBind, e.ModuleName,
+		e.ModulePath, e.InterfaceLuid)
+}
@@ -0,0 +1,49 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import "fmt"
+
+// Corresponds to MIB_UDPSTATS2 defined in udpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/udpmib/ns-udpmib-mib_udpstats2).
+type UdpStatistics struct {
+	Family AddressFamily
+
+	InDatagrams  uint64
+	NoPorts      uint32
+	InErrors     uint32
+	OutDatagrams uint64
+	NumAddrs     uint32
+}
+
+// Returns UDP statistics for the specified address family, which has to be either AF_INET or AF_INET6. Corresponds to
+// GetUdpStatisticsEx2 function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getudpstatisticsex2).
+func GetUdpStatistics(family AddressFamily) (*UdpStatistics, error) {
+
+	stats, err := getWtMibUdpstats2(family)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.toUdpStatistics(family), nil
+}
+
+func (stats *UdpStatistics) String() string {
+
+	if stats == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf(`Family: %s
+InDatagrams: %d
+NoPorts: %d
+InErrors: %d
+OutDatagrams: %d
+NumAddrs: %d`, stats.Family.String(), stats.InDatagrams, stats.NoPorts, stats.InErrors, stats.OutDatagrams,
+		stats.NumAddrs)
+}
@@ -0,0 +1,46 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"fmt"
+	"testing"
+)
+
+const udpStatistics_print = false
+
+func TestGetUdpStatistics(t *testing.T) {
+
+	for _, family := range []AddressFamily{AF_INET, AF_INET6} {
+
+		stats, err := GetUdpStatistics(family)
+
+		if err != nil {
+			t.Errorf("GetUdpStatistics(%s) returned an error: %v", family.String(), err)
+			continue
+		}
+
+		if stats == nil || stats.Family != family {
+			t.Errorf("GetUdpStatistics(%s) returned unexpected result: %v", family.String(), stats)
+			continue
+		}
+
+		if udpStatistics_print {
+			fmt.Println("======================= UDP STATISTICS OUTPUT START =======================")
+			fmt.Println(stats)
+			fmt.Println("======================== UDP STATISTICS OUTPUT END ========================")
+		}
+	}
+}
+
+func TestGetUdpStatisticsInvalidFamily(t *testing.T) {
+
+	_, err := GetUdpStatistics(AF_UNSPEC)
+
+	if err == nil {
+		t.Error("GetUdpStatistics(AF_UNSPEC) didn't return an error.")
+	}
+}
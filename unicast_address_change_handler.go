@@ -8,92 +8,166 @@ package winipcfg
 import (
 	"golang.org/x/sys/windows"
 	"net"
-	"os"
-	"sync"
 	"unsafe"
 )
 
 // Defines function that can be used as a callback.
 type UnicastAddressChangeCallback struct {
-	cb func(notificationType MibNotificationType, interfaceLuid uint64, ip *net.IP)
+	dispatcher *notificationDispatcher
+	subscriber *notificationSubscriber
+}
+
+type unicastAddressChange struct {
+	notificationType MibNotificationType
+	family           AddressFamily
+	interfaceLuid    uint64
+	ip               net.IP
+	// Converted once on the OS notification thread; nil if the OS didn't provide a (valid) row.
+	row *UnicastIpAddressRow
 }
 
 var (
-	unicastAddressChangeMutex     = sync.Mutex{}
-	unicastAddressChangeCallbacks = make(map[*UnicastAddressChangeCallback]bool)
-	unicastAddressChangeHandle    = uintptr(0)
+	unicastAddressChangeHandles    notificationHandles
+	unicastAddressChangeDispatcher *notificationDispatcher
 )
 
+// Initialized here rather than in the var block, since the dispatcher and the OS callback refer to each other.
+func init() {
+	unicastAddressChangeDispatcher = newNotificationDispatcher(registerUnicastAddressChange,
+		func(family AddressFamily) error {
+			return cancelChangeNotification(unicastAddressChangeHandles.of(family))
+		})
+}
+
+// Registers a callback which is called on every unicast IP address change. The callback is called from its own
+// goroutine (never from the OS notification thread), notifications are delivered in order, and the callback may
+// register and unregister callbacks, including itself.
 func RegisterUnicastAddressChangeCallback(
 	callback func(notificationType MibNotificationType, interfaceLuid uint64, ip *net.IP)) (*UnicastAddressChangeCallback, error) {
+	return RegisterUnicastAddressChangeCallbackEx(nil, callback)
+}
 
-	cb := &UnicastAddressChangeCallback{callback}
+// The same as RegisterUnicastAddressChangeCallback(), but the callback receives only changes matching 'filter' (nil
+// matches everything). Filter's DestinationPrefix isn't supported.
+func RegisterUnicastAddressChangeCallbackEx(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, interfaceLuid uint64, ip *net.IP)) (*UnicastAddressChangeCallback, error) {
+	return subscribeUnicastAddressChange(unicastAddressChangeDispatcher, filter, callback)
+}
 
-	unicastAddressChangeMutex.Lock()
-	defer unicastAddressChangeMutex.Unlock()
+// Registers a callback which receives the complete changed row (including DadState, PrefixOrigin and lifetimes), as
+// provided by the OS, so there's no need to query it again. 'filter' is used as in
+// RegisterUnicastAddressChangeCallbackEx(). The row is nil if the OS didn't provide it (e.g. for
+// MibInitialNotification). Each callback receives its own copy of the row.
+func RegisterUnicastIpAddressRowChangeCallback(filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, row *UnicastIpAddressRow)) (*UnicastAddressChangeCallback, error) {
+	return subscribeUnicastIpAddressRowChange(unicastAddressChangeDispatcher, filter, callback)
+}
 
-	unicastAddressChangeCallbacks[cb] = true
+func subscribeUnicastAddressChange(dispatcher *notificationDispatcher, filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, interfaceLuid uint64, ip *net.IP)) (*UnicastAddressChangeCallback, error) {
+	return subscribeUnicastAddressChangeEvents(dispatcher, filter, func(change *unicastAddressChange) {
+		ip := append(net.IP(nil), change.ip...)
+		callback(change.notificationType, change.interfaceLuid, &ip)
+	})
+}
+
+func subscribeUnicastIpAddressRowChange(dispatcher *notificationDispatcher, filter *ChangeFilter,
+	callback func(notificationType MibNotificationType, row *UnicastIpAddressRow)) (*UnicastAddressChangeCallback, error) {
+	return subscribeUnicastAddressChangeEvents(dispatcher, filter, func(change *unicastAddressChange) {
+		callback(change.notificationType, change.row.clone())
+	})
+}
 
-	if unicastAddressChangeHandle == 0 {
+func subscribeUnicastAddressChangeEvents(dispatcher *notificationDispatcher, filter *ChangeFilter,
+	deliver func(change *unicastAddressChange)) (*UnicastAddressChangeCallback, error) {
 
-		result := notifyUnicastIpAddressChange(AF_UNSPEC, windows.NewCallback(unicastAddressChanged), 0,
-			false, unsafe.Pointer(&unicastAddressChangeHandle))
+	compiled, err := filter.compile(false)
 
-		if result != 0 {
-			delete(unicastAddressChangeCallbacks, cb)
-			unicastAddressChangeHandle = 0
-			return nil, os.NewSyscallError("iphlpapi.NotifyUnicastIpAddressChange", windows.Errno(result))
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	return cb, nil
+	subscriber, err := dispatcher.Subscribe(compiled.families(), func(event interface{}) bool {
+		change := event.(*unicastAddressChange)
+		return compiled.matches(change.family, change.interfaceLuid, change.notificationType)
+	}, func(event interface{}) {
+		deliver(event.(*unicastAddressChange))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnicastAddressChangeCallback{dispatcher: dispatcher, subscriber: subscriber}, nil
 }
 
+// Unregisters the callback, waiting for its running call (if any) to return, so the callback won't be called after
+// Unregister returns. Called from the callback itself, Unregister doesn't wait for it.
 func (callback *UnicastAddressChangeCallback) Unregister() error {
+	return callback.dispatcher.Unsubscribe(callback.subscriber)
+}
 
-	unicastAddressChangeMutex.Lock()
-	defer unicastAddressChangeMutex.Unlock()
+// Returns the number of notifications dropped because the callback didn't keep up with them.
+func (callback *UnicastAddressChangeCallback) Dropped() uint64 {
+	return callback.subscriber.Dropped()
+}
+
+func registerUnicastAddressChange(family AddressFamily) error {
 
-	delete(unicastAddressChangeCallbacks, callback)
+	handle := unicastAddressChangeHandles.of(family)
 
-	if len(unicastAddressChangeCallbacks) < 1 && unicastAddressChangeHandle != 0 {
+	if *handle != 0 {
+		// Left over from a failed cancellation.
+		return nil
+	}
 
-		result := cancelMibChangeNotify2(unicastAddressChangeHandle)
+	result := notifyUnicastIpAddressChange(family, windows.NewCallback(unicastAddressChanged), 0, false,
+		unsafe.Pointer(handle))
 
-		if result != 0 {
-			return os.NewSyscallError("iphlpapi.CancelMibChangeNotify2", windows.Errno(result))
-		}
+	if result != 0 {
+		*handle = 0
+		return newOperationError("iphlpapi.NotifyUnicastIpAddressChange", 0, "", windows.Errno(result))
+	}
+
+	return nil
+}
 
-		unicastAddressChangeHandle = 0
+func cancelChangeNotification(handle *uintptr) error {
+
+	if *handle == 0 {
+		return nil
+	}
+
+	result := cancelMibChangeNotify2(*handle)
+
+	if result != 0 {
+		return newOperationError("iphlpapi.CancelMibChangeNotify2", 0, "", windows.Errno(result))
 	}
 
+	*handle = 0
+
 	return nil
 }
 
 func unicastAddressChanged(callerContext unsafe.Pointer, wtUar *wtMibUnicastipaddressRow,
 	notificationType MibNotificationType) uintptr {
 
-	interfaceLuid := uint64(0)
-	var ip net.IP = nil
+	change := &unicastAddressChange{notificationType: notificationType}
 
 	if wtUar != nil {
 
-		interfaceLuid = wtUar.InterfaceLuid
+		change.interfaceLuid = wtUar.InterfaceLuid
 
-		sainet, err := wtUar.Address.toSockaddrInet()
+		row, err := wtUar.toUnicastIpAddressRow()
 
-		if err == nil && sainet != nil {
-			ip = sainet.Address
+		if err == nil && row != nil && row.Address != nil {
+			change.family = row.Address.Family
+			change.ip = row.Address.Address
+			change.row = row
 		}
 	}
 
-	unicastAddressChangeMutex.Lock()
-
-	for cb := range unicastAddressChangeCallbacks {
-		cb.cb(notificationType, interfaceLuid, &ip)
-	}
-
-	unicastAddressChangeMutex.Unlock()
+	unicastAddressChangeDispatcher.Dispatch(change)
 
 	return 0
 }
@@ -41,6 +41,24 @@ func (address *UnicastIpAddressRow) equal(other *UnicastIpAddressRow) bool {
 		address.CreationTimeStamp == other.CreationTimeStamp && address.Address.equal(other.Address)
 }
 
+// Returns a copy of the row which doesn't share any memory with the original.
+func (address *UnicastIpAddressRow) clone() *UnicastIpAddressRow {
+
+	if address == nil {
+		return nil
+	}
+
+	clone := *address
+
+	if address.Address != nil {
+		sainet := *address.Address
+		sainet.Address = append(net.IP(nil), address.Address.Address...)
+		clone.Address = &sainet
+	}
+
+	return &clone
+}
+
 func (address *UnicastIpAddressRow) toWtMibUnicastipaddressRow() (*wtMibUnicastipaddressRow, error) {
 
 	if address == nil {
@@ -65,3 +65,31 @@ func TestGetMatchingUnicastIpAddressRow(t *testing.T) {
 		fmt.Println("====================== UNICAST ADDRESS OUTPUT END ======================")
 	}
 }
+
+func TestUnicastIpAddressRowClone(t *testing.T) {
+
+	if (*UnicastIpAddressRow)(nil).clone() != nil {
+		t.Error("clone() of nil returned non-nil.")
+	}
+
+	row := &UnicastIpAddressRow{
+		Address:           &SockaddrInet{Family: AF_INET, Address: net.IP{10, 0, 0, 1}},
+		InterfaceLuid:     42,
+		PrefixOrigin:      IpPrefixOriginDhcp,
+		ValidLifetime:     600,
+		PreferredLifetime: 300,
+		DadState:          IpDadStatePreferred,
+	}
+
+	clone := row.clone()
+
+	if !clone.equal(row) {
+		t.Errorf("clone() returned %v, although %v is expected.", clone, row)
+	}
+
+	clone.Address.Address[3] = 2
+
+	if !row.Address.Address.Equal(net.IP{10, 0, 0, 1}) {
+		t.Error("clone() returned a row sharing its address with the original.")
+	}
+}
@@ -0,0 +1,148 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Determines what WaitForInterface() function waits for. Values can be combined using bitwise OR.
+type InterfaceReadyCondition uint32
+
+const (
+	// Interface is returned by GetAdaptersAddresses (i.e. InterfaceFromLUID succeeds).
+	InterfaceExists InterfaceReadyCondition = 1 << iota
+	// IP interface (GetIpInterfaceEntry) exists for every requested address family.
+	IpInterfaceExists
+	// IfRow.OperStatus is IfOperStatusUp.
+	InterfaceOperStatusUp
+
+	// All of the above.
+	InterfaceReady = InterfaceExists | IpInterfaceExists | InterfaceOperStatusUp
+)
+
+// Interval between two consecutive polls in WaitForInterface(). Change notifications wake the waiter earlier, but
+// not every relevant change is notified (i.e. OperStatus), so polling is needed as well.
+const waitForInterfacePollInterval = 200 * time.Millisecond
+
+func (c InterfaceReadyCondition) String() string {
+
+	if c == 0 {
+		return "<none>"
+	}
+
+	var names []string
+
+	if c&InterfaceExists != 0 {
+		names = append(names, "InterfaceExists")
+	}
+
+	if c&IpInterfaceExists != 0 {
+		names = append(names, "IpInterfaceExists")
+	}
+
+	if c&InterfaceOperStatusUp != 0 {
+		names = append(names, "InterfaceOperStatusUp")
+	}
+
+	if unknown := c &^ InterfaceReady; unknown != 0 {
+		names = append(names, fmt.Sprintf("InterfaceReadyCondition_UNKNOWN(%d)", uint32(unknown)))
+	}
+
+	return strings.Join(names, "|")
+}
+
+// Waits until the interface with specified LUID satisfies all 'conditions', or until 'ctx' is done. Argument
+// 'families' is used by IpInterfaceExists condition, and its items have to be either AF_INET or AF_INET6; if it's
+// empty, both families are checked. The function combines RegisterInterfaceChangeCallback with polling, so it's
+// suitable for waiting on a freshly created adapter. On success the current Interface is returned; if 'ctx' is done
+// first, the returned error wraps ctx.Err() together with the reason the last check failed. Since the Interface is
+// returned, InterfaceExists condition is always implied.
+func WaitForInterface(ctx context.Context, luid uint64, families []AddressFamily,
+	conditions InterfaceReadyCondition) (*Interface, error) {
+
+	if len(families) == 0 {
+		families = []AddressFamily{AF_INET, AF_INET6}
+	}
+
+	for _, family := range families {
+		if family != AF_INET && family != AF_INET6 {
+			return nil, newKindError(ErrInvalidParameter,
+				"WaitForInterface() - family %s has to be either AF_INET or AF_INET6",
+				family.String())
+		}
+	}
+
+	changed := make(chan struct{}, 1)
+
+	cb, err := RegisterInterfaceChangeCallback(func(notificationType MibNotificationType, interfaceLuid uint64) {
+		if interfaceLuid != luid {
+			return
+		}
+		// Never block the notification thread.
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer cb.Unregister()
+
+	ticker := time.NewTicker(waitForInterfacePollInterval)
+	defer ticker.Stop()
+
+	for {
+
+		ifc, err := checkInterfaceReady(luid, families, conditions)
+
+		if err == nil {
+			return ifc, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("WaitForInterface() - %w (last check: %v)", ctx.Err(), err)
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns a non-nil error describing the first condition that isn't satisfied yet. The cheap per-LUID queries are
+// done first, and GetAdaptersAddresses (which is always needed for the returned Interface) is done last.
+func checkInterfaceReady(luid uint64, families []AddressFamily, conditions InterfaceReadyCondition) (*Interface,
+	error) {
+
+	if conditions&IpInterfaceExists != 0 {
+		for _, family := range families {
+			if _, err := GetIpInterface(luid, family); err != nil {
+				return nil, fmt.Errorf("%s IP interface: %w", family.String(), err)
+			}
+		}
+	}
+
+	if conditions&InterfaceOperStatusUp != 0 {
+
+		row, err := GetIfRow(luid, MibIfEntryNormalWithoutStatistics)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if row.OperStatus != IfOperStatusUp {
+			return nil, fmt.Errorf("OperStatus is %s", row.OperStatus.String())
+		}
+	}
+
+	return InterfaceFromLUID(luid)
+}
@@ -0,0 +1,60 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForInterfaceExisting(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ifc, err := WaitForInterface(ctx, existingLuid, nil, InterfaceExists|IpInterfaceExists)
+
+	if err != nil {
+		t.Errorf("WaitForInterface() returned an error: %v", err)
+		return
+	}
+
+	if ifc == nil || ifc.Luid != existingLuid {
+		t.Errorf("WaitForInterface() returned wrong interface: %v", ifc)
+	}
+}
+
+func TestWaitForInterfaceNonExisting(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := WaitForInterface(ctx, unexistingLuid, []AddressFamily{AF_INET}, InterfaceReady)
+
+	if err == nil {
+		t.Error("WaitForInterface() returned no error for non-existing interface.")
+	} else if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForInterface() returned an error not wrapping context.DeadlineExceeded: %v", err)
+	}
+}
+
+func TestWaitForInterfaceInvalidFamily(t *testing.T) {
+
+	_, err := WaitForInterface(context.Background(), existingLuid, []AddressFamily{AF_UNSPEC}, InterfaceReady)
+
+	if err == nil {
+		t.Error("WaitForInterface() accepted AF_UNSPEC family.")
+	}
+}
+
+func TestInterfaceReadyConditionString(t *testing.T) {
+
+	if s := InterfaceReady.String(); s != "InterfaceExists|IpInterfaceExists|InterfaceOperStatusUp" {
+		t.Errorf("InterfaceReady.String() returned %q", s)
+	}
+}
@@ -37,6 +37,20 @@ package winipcfg
 // https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-convertinterfaceguidtoluid
 //sys	convertInterfaceGuidToLuid(InterfaceGuid *windows.GUID, InterfaceLuid *uint64) (result int32) = iphlpapi.ConvertInterfaceGuidToLuid
 
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getifstacktable
+//sys	getIfStackTable(Table unsafe.Pointer) (result int32) = iphlpapi.GetIfStackTable
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getinvertedifstacktable
+//sys	getInvertedIfStackTable(Table unsafe.Pointer) (result int32) = iphlpapi.GetInvertedIfStackTable
+
+// Network compartment - related functions
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getcurrentthreadcompartmentid
+//sys	getCurrentThreadCompartmentId() (compartmentId uint32) = iphlpapi.GetCurrentThreadCompartmentId
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-setcurrentthreadcompartmentid
+//sys	setCurrentThreadCompartmentId(CompartmentId uint32) (result uint32) = iphlpapi.SetCurrentThreadCompartmentId
+
 // Unicast IP address - related functions
 
 // https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getunicastipaddresstable
@@ -71,6 +85,14 @@ package winipcfg
 // https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-deleteanycastipaddressentry
 //sys	deleteAnycastIpAddressEntry(Row *wtMibAnycastipaddressRow) (result int32) = iphlpapi.DeleteAnycastIpAddressEntry
 
+// Multicast IP address - related functions
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getmulticastipaddresstable
+//sys	getMulticastIpAddressTable(Family AddressFamily, Table unsafe.Pointer) (result int32) = iphlpapi.GetMulticastIpAddressTable
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getmulticastipaddressentry
+//sys	getMulticastIpAddressEntry(Row *wtMibMulticastipaddressRow) (result int32) = iphlpapi.GetMulticastIpAddressEntry
+
 // Routing - related functions
 
 // https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getipforwardtable2
@@ -91,6 +113,59 @@ package winipcfg
 // https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-deleteipforwardentry2
 //sys	deleteIpForwardEntry2(route *wtMibIpforwardRow2) (result int32) = iphlpapi.DeleteIpForwardEntry2
 
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getippathtable
+//sys	getIpPathTable(Family AddressFamily, Table unsafe.Pointer) (result int32) = iphlpapi.GetIpPathTable
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getippathentry
+//sys	getIpPathEntry(Row *wtMibIppathRow) (result int32) = iphlpapi.GetIpPathEntry
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-flushippathtable
+//sys	flushIpPathTable(Family AddressFamily) (result int32) = iphlpapi.FlushIpPathTable
+
+// Address selection - related functions
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-createsortedaddresspairs
+//sys	createSortedAddressPairs(SourceAddressList *wtSockaddrIn6, SourceAddressCount uint32, DestinationAddressList *wtSockaddrIn6, DestinationAddressCount uint32, AddressSortOptions uint32, SortedAddressPairList unsafe.Pointer, SortedAddressPairCount *uint32) (result int32) = iphlpapi.CreateSortedAddressPairs
+
+// Protocol statistics - related functions
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getipstatisticsex
+//sys	getIpStatisticsEx(Statistics *wtMibIpstats, Family AddressFamily) (result uint32) = iphlpapi.GetIpStatisticsEx
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-geticmpstatisticsex
+//sys	getIcmpStatisticsEx(Statistics *wtMibIcmpEx, Family AddressFamily) (result uint32) = iphlpapi.GetIcmpStatisticsEx
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-gettcpstatisticsex2
+//sys	getTcpStatisticsEx2(Statistics *wtMibTcpstats2, Family AddressFamily) (result uint32) = iphlpapi.GetTcpStatisticsEx2
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getudpstatisticsex2
+//sys	getUdpStatisticsEx2(Statistics *wtMibUdpstats2, Family AddressFamily) (result uint32) = iphlpapi.GetUdpStatisticsEx2
+
+// Socket tables - related functions
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getextendedtcptable
+//sys	getExtendedTcpTable(TcpTable unsafe.Pointer, Size *uint32, Order bool, Af uint32, TableClass uint32, Reserved uint32) (result uint32) = iphlpapi.GetExtendedTcpTable
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getextendedudptable
+//sys	getExtendedUdpTable(UdpTable unsafe.Pointer, Size *uint32, Order bool, Af uint32, TableClass uint32, Reserved uint32) (result uint32) = iphlpapi.GetExtendedUdpTable
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getownermodulefromtcpentry
+//sys	getOwnerModuleFromTcpEntry(TcpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) = iphlpapi.GetOwnerModuleFromTcpEntry
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getownermodulefromtcp6entry
+//sys	getOwnerModuleFromTcp6Entry(TcpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) = iphlpapi.GetOwnerModuleFromTcp6Entry
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getownermodulefromudpentry
+//sys	getOwnerModuleFromUdpEntry(UdpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) = iphlpapi.GetOwnerModuleFromUdpEntry
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getownermodulefromudp6entry
+//sys	getOwnerModuleFromUdp6Entry(UdpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) = iphlpapi.GetOwnerModuleFromUdp6Entry
+
+// Teredo - related functions
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getteredoport
+//sys	getTeredoPort(Port *uint16) (result int32) = iphlpapi.GetTeredoPort
+
 // Notifications - related functions
 
 // https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-notifyipinterfacechange
@@ -104,3 +179,11 @@ package winipcfg
 
 // https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-cancelmibchangenotify2
 //sys	cancelMibChangeNotify2(NotificationHandle uintptr) (result int32) = iphlpapi.CancelMibChangeNotify2
+
+// Interface control - related functions
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/combaseapi/nf-combaseapi-cocreateinstance
+//sys	coCreateInstance(Clsid *windows.GUID, Outer uintptr, ClsContext uint32, Iid *windows.GUID, Object unsafe.Pointer) (result uint32) = ole32.CoCreateInstance
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/netcon/nf-netcon-ncfreenetconproperties
+//sys	ncFreeNetconProperties(Props *wtNetconProperties) = netshell.NcFreeNetconProperties
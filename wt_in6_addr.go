@@ -6,7 +6,6 @@
 package winipcfg
 
 import (
-	"fmt"
 	"net"
 )
 
@@ -81,7 +80,7 @@ func (addr *wtIn6Addr) matches(ip *net.IP) bool {
 func netIpToWtIn6Addr(ip net.IP) (*wtIn6Addr, error) {
 
 	if len(ip) != net.IPv6len || ip.To4() != nil {
-		return nil, fmt.Errorf("netIpToWtIn6Addr() requires IPv6 addresses")
+		return nil, newKindError(ErrInvalidParameter, "netIpToWtIn6Addr() requires IPv6 addresses")
 	}
 
 	in6_addr := wtIn6Addr{}
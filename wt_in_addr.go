@@ -6,7 +6,6 @@
 package winipcfg
 
 import (
-	"fmt"
 	"net"
 )
 
@@ -57,7 +56,7 @@ func netIpToWtInAddr(ip net.IP) (*wtInAddr, error) {
 	ip4 := ip.To4()
 
 	if ip4 == nil {
-		return nil, fmt.Errorf("Input IP isn't a valid IPv4 address.")
+		return nil, newKindError(ErrInvalidParameter, "Input IP isn't a valid IPv4 address.")
 	}
 
 	firstByte := 0
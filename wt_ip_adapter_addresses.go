@@ -8,7 +8,6 @@ package winipcfg
 import (
 	"golang.org/x/sys/windows"
 	"net"
-	"os"
 	"unsafe"
 )
 
@@ -41,6 +40,13 @@ type wtIpAdapterAddresses wtIpAdapterAddressesLh
 // Corresponds to GetAdaptersAddresses function
 // (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getadaptersaddresses)
 func getWtIpAdapterAddresses(gaaFlags getAdapterAddressesFlagsBytes) ([]*wtIpAdapterAddresses, error) {
+	return getWtIpAdapterAddressesEx(AF_UNSPEC, gaaFlags)
+}
+
+// The same as getWtIpAdapterAddresses(), but returns only adapters with 'family' enabled (and only their addresses of
+// that family), unless 'family' is AF_UNSPEC.
+func getWtIpAdapterAddressesEx(family AddressFamily, gaaFlags getAdapterAddressesFlagsBytes) ([]*wtIpAdapterAddresses,
+	error) {
 
 	var b []byte
 
@@ -50,7 +56,7 @@ func getWtIpAdapterAddresses(gaaFlags getAdapterAddressesFlagsBytes) ([]*wtIpAda
 
 		b = make([]byte, size)
 
-		result := getAdaptersAddresses(windows.AF_UNSPEC, uint32(gaaFlags), 0,
+		result := getAdaptersAddresses(uint32(family), uint32(gaaFlags), 0,
 			(*wtIpAdapterAddresses)(unsafe.Pointer(&b[0])), &size)
 
 		if result == 0 {
@@ -58,11 +64,11 @@ func getWtIpAdapterAddresses(gaaFlags getAdapterAddressesFlagsBytes) ([]*wtIpAda
 		}
 
 		if result != uint32(windows.ERROR_BUFFER_OVERFLOW) {
-			return nil, os.NewSyscallError("iphlpapi.GetAdaptersAddresses", windows.Errno(result))
+			return nil, newOperationError("iphlpapi.GetAdaptersAddresses", 0, "", windows.Errno(result))
 		}
 
 		if size <= uint32(len(b)) {
-			return nil, os.NewSyscallError("iphlpapi.GetAdaptersAddresses", windows.Errno(result))
+			return nil, newOperationError("iphlpapi.GetAdaptersAddresses", 0, "", windows.Errno(result))
 		}
 	}
 
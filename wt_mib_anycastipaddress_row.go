@@ -8,7 +8,6 @@ package winipcfg
 import (
 	"golang.org/x/sys/windows"
 	"net"
-	"os"
 	"unsafe"
 )
 
@@ -25,7 +24,7 @@ func getWtMibAnycastipaddressRows(family AddressFamily) ([]*wtMibAnycastipaddres
 	}
 
 	if result != 0 {
-		return nil, os.NewSyscallError("iphlpapi.GetAnycastIpAddressTable", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetAnycastIpAddressTable", 0, "", windows.Errno(result))
 	}
 
 	addresses := make([]*wtMibAnycastipaddressRow, pTable.NumEntries, pTable.NumEntries)
@@ -67,7 +66,8 @@ func getWtMibAnycastipaddressRow(interfaceLuid uint64, wtsainet *wtSockaddrInet)
 	if result == 0 {
 		return row, nil
 	} else {
-		return nil, os.NewSyscallError("iphlpapi.GetAnycastIpAddressEntry", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetAnycastIpAddressEntry", interfaceLuid, row.Address.String(),
+			windows.Errno(result))
 	}
 }
 
@@ -80,7 +80,8 @@ func (wtaia *wtMibAnycastipaddressRow) add() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.CreateAnycastIpAddressEntry", windows.Errno(result))
+		return newOperationError("iphlpapi.CreateAnycastIpAddressEntry", wtaia.InterfaceLuid, wtaia.Address.String(),
+			windows.Errno(result))
 	}
 }
 
@@ -93,7 +94,8 @@ func (wtaia *wtMibAnycastipaddressRow) delete() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.DeleteAnycastIpAddressEntry", windows.Errno(result))
+		return newOperationError("iphlpapi.DeleteAnycastIpAddressEntry", wtaia.InterfaceLuid, wtaia.Address.String(),
+			windows.Errno(result))
 	}
 }
 
@@ -0,0 +1,65 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// MIBICMPSTATS_EX_XPSP1 defined in ipmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/ipmib/ns-ipmib-mibicmpstats_ex_xpsp1)
+type wtMibIcmpstatsEx struct {
+	Msgs      uint32      // Windows type: DWORD
+	Errors    uint32      // Windows type: DWORD
+	TypeCount [256]uint32 // Windows type: DWORD[256]
+}
+
+// MIB_ICMP_EX_XPSP1 defined in ipmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/ipmib/ns-ipmib-mib_icmp_ex_xpsp1)
+type wtMibIcmpEx struct {
+	icmpInStats  wtMibIcmpstatsEx
+	icmpOutStats wtMibIcmpstatsEx
+}
+
+// Uses GetIcmpStatisticsEx function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-geticmpstatisticsex).
+func getWtMibIcmpEx(family AddressFamily) (*wtMibIcmpEx, error) {
+
+	if err := checkStatisticsFamily(family); err != nil {
+		return nil, err
+	}
+
+	stats := wtMibIcmpEx{}
+
+	result := getIcmpStatisticsEx(&stats, family)
+
+	if result == 0 {
+		return &stats, nil
+	} else {
+		return nil, newOperationError("iphlpapi.GetIcmpStatisticsEx", 0, "", windows.Errno(result))
+	}
+}
+
+func (stats *wtMibIcmpstatsEx) toIcmpStats() IcmpStats {
+	return IcmpStats{
+		Msgs:      stats.Msgs,
+		Errors:    stats.Errors,
+		TypeCount: stats.TypeCount,
+	}
+}
+
+func (stats *wtMibIcmpEx) toIcmpStatistics(family AddressFamily) *IcmpStatistics {
+
+	if stats == nil {
+		return nil
+	}
+
+	return &IcmpStatistics{
+		Family: family,
+		In:     stats.icmpInStats.toIcmpStats(),
+		Out:    stats.icmpOutStats.toIcmpStats(),
+	}
+}
@@ -0,0 +1,67 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibIcmpExSize(t *testing.T) {
+
+	const actualWtMibIcmpExSize = unsafe.Sizeof(wtMibIcmpEx{})
+
+	if actualWtMibIcmpExSize != wtMibIcmpEx_Size {
+		t.Errorf("Size of wtMibIcmpEx is %d, although %d is expected.", actualWtMibIcmpExSize,
+			wtMibIcmpEx_Size)
+	}
+}
+
+func TestWtMibIcmpExOffsets(t *testing.T) {
+
+	s := wtMibIcmpEx{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.icmpOutStats)) - sp
+
+	if offset != wtMibIcmpEx_icmpOutStats_Offset {
+		t.Errorf("wtMibIcmpEx.icmpOutStats offset is %d although %d is expected", offset,
+			wtMibIcmpEx_icmpOutStats_Offset)
+		return
+	}
+}
+
+func TestWtMibIcmpstatsExSize(t *testing.T) {
+
+	const actualWtMibIcmpstatsExSize = unsafe.Sizeof(wtMibIcmpstatsEx{})
+
+	if actualWtMibIcmpstatsExSize != wtMibIcmpstatsEx_Size {
+		t.Errorf("Size of wtMibIcmpstatsEx is %d, although %d is expected.", actualWtMibIcmpstatsExSize,
+			wtMibIcmpstatsEx_Size)
+	}
+}
+
+func TestWtMibIcmpstatsExOffsets(t *testing.T) {
+
+	s := wtMibIcmpstatsEx{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.Errors)) - sp
+
+	if offset != wtMibIcmpstatsEx_Errors_Offset {
+		t.Errorf("wtMibIcmpstatsEx.Errors offset is %d although %d is expected", offset,
+			wtMibIcmpstatsEx_Errors_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.TypeCount)) - sp
+
+	if offset != wtMibIcmpstatsEx_TypeCount_Offset {
+		t.Errorf("wtMibIcmpstatsEx.TypeCount offset is %d although %d is expected", offset,
+			wtMibIcmpstatsEx_TypeCount_Offset)
+		return
+	}
+}
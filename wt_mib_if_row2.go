@@ -7,7 +7,7 @@ package winipcfg
 
 import (
 	"golang.org/x/sys/windows"
-	"os"
+	"net"
 	"unsafe"
 )
 
@@ -55,7 +55,7 @@ func getWtMibIfRow2s(level MibIfEntryLevel) ([]*wtMibIfRow2, error) {
 	}
 
 	if result != 0 {
-		return nil, os.NewSyscallError("iphlpapi.GetIfTable2Ex", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetIfTable2Ex", 0, "", windows.Errno(result))
 	}
 
 	rows := make([]*wtMibIfRow2, pTable.NumEntries, pTable.NumEntries)
@@ -83,7 +83,7 @@ func getWtMibIfRow(interfaceLuid uint64, level MibIfEntryLevel) (*wtMibIfRow2, e
 	if result == 0 {
 		return &row, nil
 	} else {
-		return nil, os.NewSyscallError("iphlpapi.GetIfEntry2Ex", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetIfEntry2Ex", interfaceLuid, "", windows.Errno(result))
 	}
 }
 
@@ -93,7 +93,7 @@ func (row *wtMibIfRow2) toIfRow() *IfRow {
 		return nil
 	}
 
-	return &IfRow{
+	ifr := &IfRow{
 		InterfaceLuid:               row.InterfaceLuid,
 		InterfaceIndex:              row.InterfaceIndex,
 		InterfaceGuid:               row.InterfaceGuid,
@@ -135,4 +135,10 @@ func (row *wtMibIfRow2) toIfRow() *IfRow {
 		OutBroadcastOctets:          row.OutBroadcastOctets,
 		OutQLen:                     row.OutQLen,
 	}
+
+	if row.PhysicalAddressLength > 0 && row.PhysicalAddressLength <= if_max_phys_address_length {
+		ifr.physicalAddress = net.HardwareAddr(append([]byte(nil), row.PhysicalAddress[:row.PhysicalAddressLength]...))
+	}
+
+	return ifr
 }
@@ -0,0 +1,96 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// MIB_IFSTACK_ROW defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_ifstack_row)
+type wtMibIfstackRow struct {
+	HigherLayerInterfaceIndex uint32 // Windows type: NET_IFINDEX
+	LowerLayerInterfaceIndex  uint32 // Windows type: NET_IFINDEX
+}
+
+// MIB_IFSTACK_TABLE defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_ifstack_table)
+type wtMibIfstackTable struct {
+	NumEntries uint32 // Windows type: ULONG
+	Table      [anySize]wtMibIfstackRow
+}
+
+// MIB_INVERTEDIFSTACK_ROW defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_invertedifstack_row)
+type wtMibInvertedifstackRow struct {
+	LowerLayerInterfaceIndex  uint32 // Windows type: NET_IFINDEX
+	HigherLayerInterfaceIndex uint32 // Windows type: NET_IFINDEX
+}
+
+// MIB_INVERTEDIFSTACK_TABLE defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_invertedifstack_table)
+type wtMibInvertedifstackTable struct {
+	NumEntries uint32 // Windows type: ULONG
+	Table      [anySize]wtMibInvertedifstackRow
+}
+
+// Uses GetIfStackTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getifstacktable).
+func getWtMibIfstackRows() ([]wtMibIfstackRow, error) {
+
+	var pTable *wtMibIfstackTable = nil
+
+	result := getIfStackTable(unsafe.Pointer(&pTable))
+
+	if pTable != nil {
+		defer freeMibTable(unsafe.Pointer(pTable))
+	}
+
+	if result != 0 {
+		return nil, newOperationError("iphlpapi.GetIfStackTable", 0, "", windows.Errno(result))
+	}
+
+	rows := make([]wtMibIfstackRow, pTable.NumEntries, pTable.NumEntries)
+
+	pFirstRow := uintptr(unsafe.Pointer(&pTable.Table[0]))
+	rowSize := uintptr(wtMibIfstackRow_Size) // Should be equal to unsafe.Sizeof(pTable.Table[0])
+
+	for i := uint32(0); i < pTable.NumEntries; i++ {
+		rows[i] = *(*wtMibIfstackRow)(unsafe.Pointer(pFirstRow + rowSize*uintptr(i)))
+	}
+
+	return rows, nil
+}
+
+// Uses GetInvertedIfStackTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getinvertedifstacktable).
+func getWtMibInvertedifstackRows() ([]wtMibInvertedifstackRow, error) {
+
+	var pTable *wtMibInvertedifstackTable = nil
+
+	result := getInvertedIfStackTable(unsafe.Pointer(&pTable))
+
+	if pTable != nil {
+		defer freeMibTable(unsafe.Pointer(pTable))
+	}
+
+	if result != 0 {
+		return nil, newOperationError("iphlpapi.GetInvertedIfStackTable", 0, "", windows.Errno(result))
+	}
+
+	rows := make([]wtMibInvertedifstackRow, pTable.NumEntries, pTable.NumEntries)
+
+	pFirstRow := uintptr(unsafe.Pointer(&pTable.Table[0]))
+	rowSize := uintptr(wtMibInvertedifstackRow_Size) // Should be equal to unsafe.Sizeof(pTable.Table[0])
+
+	for i := uint32(0); i < pTable.NumEntries; i++ {
+		rows[i] = *(*wtMibInvertedifstackRow)(unsafe.Pointer(pFirstRow + rowSize*uintptr(i)))
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,107 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibIfstackRowSize(t *testing.T) {
+
+	const actualWtMibIfstackRowSize = unsafe.Sizeof(wtMibIfstackRow{})
+
+	if actualWtMibIfstackRowSize != wtMibIfstackRow_Size {
+		t.Errorf("Size of wtMibIfstackRow is %d, although %d is expected.", actualWtMibIfstackRowSize,
+			wtMibIfstackRow_Size)
+	}
+}
+
+func TestWtMibIfstackRowOffsets(t *testing.T) {
+
+	s := wtMibIfstackRow{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.LowerLayerInterfaceIndex)) - sp
+
+	if offset != wtMibIfstackRow_LowerLayerInterfaceIndex_Offset {
+		t.Errorf("wtMibIfstackRow.LowerLayerInterfaceIndex offset is %d although %d is expected", offset,
+			wtMibIfstackRow_LowerLayerInterfaceIndex_Offset)
+		return
+	}
+}
+
+func TestWtMibIfstackTableSize(t *testing.T) {
+
+	const actualWtMibIfstackTableSize = unsafe.Sizeof(wtMibIfstackTable{})
+
+	if actualWtMibIfstackTableSize != wtMibIfstackTable_Size {
+		t.Errorf("Size of wtMibIfstackTable is %d, although %d is expected.", actualWtMibIfstackTableSize,
+			wtMibIfstackTable_Size)
+	}
+}
+
+func TestWtMibIfstackTableOffsets(t *testing.T) {
+
+	s := wtMibIfstackTable{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.Table)) - sp
+
+	if offset != wtMibIfstackTable_Table_Offset {
+		t.Errorf("wtMibIfstackTable.Table offset is %d although %d is expected", offset,
+			wtMibIfstackTable_Table_Offset)
+		return
+	}
+}
+
+func TestWtMibInvertedifstackRowSize(t *testing.T) {
+
+	const actualWtMibInvertedifstackRowSize = unsafe.Sizeof(wtMibInvertedifstackRow{})
+
+	if actualWtMibInvertedifstackRowSize != wtMibInvertedifstackRow_Size {
+		t.Errorf("Size of wtMibInvertedifstackRow is %d, although %d is expected.", actualWtMibInvertedifstackRowSize,
+			wtMibInvertedifstackRow_Size)
+	}
+}
+
+func TestWtMibInvertedifstackRowOffsets(t *testing.T) {
+
+	s := wtMibInvertedifstackRow{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.HigherLayerInterfaceIndex)) - sp
+
+	if offset != wtMibInvertedifstackRow_HigherLayerInterfaceIndex_Offset {
+		t.Errorf("wtMibInvertedifstackRow.HigherLayerInterfaceIndex offset is %d although %d is expected", offset,
+			wtMibInvertedifstackRow_HigherLayerInterfaceIndex_Offset)
+		return
+	}
+}
+
+func TestWtMibInvertedifstackTableSize(t *testing.T) {
+
+	const actualWtMibInvertedifstackTableSize = unsafe.Sizeof(wtMibInvertedifstackTable{})
+
+	if actualWtMibInvertedifstackTableSize != wtMibInvertedifstackTable_Size {
+		t.Errorf("Size of wtMibInvertedifstackTable is %d, although %d is expected.", actualWtMibInvertedifstackTableSize,
+			wtMibInvertedifstackTable_Size)
+	}
+}
+
+func TestWtMibInvertedifstackTableOffsets(t *testing.T) {
+
+	s := wtMibInvertedifstackTable{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.Table)) - sp
+
+	if offset != wtMibInvertedifstackTable_Table_Offset {
+		t.Errorf("wtMibInvertedifstackTable.Table offset is %d although %d is expected", offset,
+			wtMibInvertedifstackTable_Table_Offset)
+		return
+	}
+}
@@ -8,7 +8,6 @@ package winipcfg
 import (
 	"fmt"
 	"net"
-	"os"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
@@ -59,7 +58,7 @@ func getWtMibIpforwardRow2s(family AddressFamily) ([]*wtMibIpforwardRow2, error)
 	}
 
 	if result != 0 {
-		return nil, os.NewSyscallError("iphlpapi.GetIpForwardTable2", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetIpForwardTable2", 0, "", windows.Errno(result))
 	}
 
 	rows := make([]*wtMibIpforwardRow2, pTable.NumEntries, pTable.NumEntries)
@@ -122,22 +121,34 @@ func getWtMibIpforwardRow2(interfaceLuid uint64, destination *wtIpAddressPrefix,
 	if result == 0 {
 		return row, nil
 	} else {
-		return nil, os.NewSyscallError("iphlpapi.GetIpForwardEntry2", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetIpForwardEntry2", interfaceLuid, row.description(), windows.Errno(result))
 	}
 }
 
 func createAndAddWtMibIpforwardRow2(interfaceLuid uint64, routeData *RouteData) error {
 
-	wtdest, err := createWtIpAddressPrefix(&routeData.Destination)
+	row, err := newWtMibIpforwardRow2(interfaceLuid, routeData)
 
 	if err != nil {
 		return err
 	}
 
+	return row.add()
+}
+
+// Returns initialized row of the route described by 'routeData'.
+func newWtMibIpforwardRow2(interfaceLuid uint64, routeData *RouteData) (*wtMibIpforwardRow2, error) {
+
+	wtdest, err := createWtIpAddressPrefix(&routeData.Destination)
+
+	if err != nil {
+		return nil, err
+	}
+
 	wtsaNextHop, err := createWtSockaddrInet(&routeData.NextHop, 0)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	row := getInitializedWtMibIpforwardRow2(interfaceLuid)
@@ -146,7 +157,7 @@ func createAndAddWtMibIpforwardRow2(interfaceLuid uint64, routeData *RouteData)
 	row.NextHop = *wtsaNextHop
 	row.Metric = routeData.Metric
 
-	return row.add()
+	return row, nil
 }
 
 // Uses CreateIpForwardEntry2 function
@@ -158,7 +169,7 @@ func (r *wtMibIpforwardRow2) add() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.CreateIpForwardEntry2", windows.Errno(result))
+		return newOperationError("iphlpapi.CreateIpForwardEntry2", r.InterfaceLuid, r.description(), windows.Errno(result))
 	}
 }
 
@@ -171,7 +182,7 @@ func (r *wtMibIpforwardRow2) set() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.SetIpForwardEntry2", windows.Errno(result))
+		return newOperationError("iphlpapi.SetIpForwardEntry2", r.InterfaceLuid, r.description(), windows.Errno(result))
 	}
 }
 
@@ -184,7 +195,7 @@ func (r *wtMibIpforwardRow2) delete() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.DeleteIpForwardEntry2", windows.Errno(result))
+		return newOperationError("iphlpapi.DeleteIpForwardEntry2", r.InterfaceLuid, r.description(), windows.Errno(result))
 	}
 }
 
@@ -238,6 +249,11 @@ func (r *Route) ToRouteData() (*RouteData, error) {
 	}, nil
 }
 
+// Short description of the route, used in errors.
+func (r *wtMibIpforwardRow2) description() string {
+	return fmt.Sprintf("%s via %s", r.DestinationPrefix.String(), r.NextHop.String())
+}
+
 func (r *wtMibIpforwardRow2) String() string {
 
 	if r == nil {
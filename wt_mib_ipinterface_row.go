@@ -6,9 +6,7 @@
 package winipcfg
 
 import (
-	"fmt"
 	"golang.org/x/sys/windows"
-	"os"
 	"unsafe"
 )
 
@@ -25,7 +23,7 @@ func getWtMibIpinterfaceRows(family AddressFamily) ([]*wtMibIpinterfaceRow, erro
 	}
 
 	if result != 0 {
-		return nil, os.NewSyscallError("iphlpapi.GetIpInterfaceTable", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetIpInterfaceTable", 0, "", windows.Errno(result))
 	}
 
 	ipifcs := make([]*wtMibIpinterfaceRow, pTable.NumEntries, pTable.NumEntries)
@@ -47,7 +45,7 @@ func getWtMibIpinterfaceRows(family AddressFamily) ([]*wtMibIpinterfaceRow, erro
 func getWtMibIpinterfaceRow(interfaceLuid uint64, family AddressFamily) (*wtMibIpinterfaceRow, error) {
 
 	if family != AF_INET && family != AF_INET6 {
-		return nil, fmt.Errorf("argument 'family' has to be either AF_INET or AF_INET6")
+		return nil, newKindError(ErrInvalidParameter, "argument 'family' has to be either AF_INET or AF_INET6")
 	}
 
 	wtrow := wtMibIpinterfaceRow{InterfaceLuid: interfaceLuid, Family: family}
@@ -62,7 +60,7 @@ func getWtMibIpinterfaceRow(interfaceLuid uint64, family AddressFamily) (*wtMibI
 	if result == 0 {
 		return &wtrow, nil
 	} else {
-		return nil, os.NewSyscallError("iphlpapi.GetIpInterfaceEntry", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetIpInterfaceEntry", interfaceLuid, family.String(), windows.Errno(result))
 	}
 }
 
@@ -75,7 +73,8 @@ func (wtipifc *wtMibIpinterfaceRow) set() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.SetIpInterfaceEntry", windows.Errno(result))
+		return newOperationError("iphlpapi.SetIpInterfaceEntry", wtipifc.InterfaceLuid, wtipifc.Family.String(),
+			windows.Errno(result))
 	}
 }
 
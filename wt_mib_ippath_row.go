@@ -0,0 +1,107 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Uses GetIpPathTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getippathtable).
+func getWtMibIppathRows(family AddressFamily) ([]*wtMibIppathRow, error) {
+
+	var pTable *wtMibIppathTable = nil
+
+	result := getIpPathTable(family, unsafe.Pointer(&pTable))
+
+	if pTable != nil {
+		defer freeMibTable(unsafe.Pointer(pTable))
+	}
+
+	if result != 0 {
+		return nil, newOperationError("iphlpapi.GetIpPathTable", 0, "", windows.Errno(result))
+	}
+
+	paths := make([]*wtMibIppathRow, pTable.NumEntries, pTable.NumEntries)
+
+	pFirstRow := uintptr(unsafe.Pointer(&pTable.Table[0]))
+	rowSize := uintptr(wtMibIppathRow_Size) // Should be equal to unsafe.Sizeof(pTable.Table[0])
+
+	for i := uint32(0); i < pTable.NumEntries; i++ {
+		// Dereferencing and rereferencing in order to force copying.
+		row := *(*wtMibIppathRow)(unsafe.Pointer(pFirstRow + rowSize*uintptr(i)))
+		paths[i] = &row
+	}
+
+	return paths, nil
+}
+
+// Uses GetIpPathEntry function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getippathentry).
+func getWtMibIppathRow(interfaceLuid uint64, destination *wtSockaddrInet) (*wtMibIppathRow, error) {
+
+	row := &wtMibIppathRow{
+		Destination:   *destination,
+		InterfaceLuid: interfaceLuid,
+	}
+
+	result := getIpPathEntry(row)
+
+	if result == 0 {
+		return row, nil
+	} else {
+		return nil, newOperationError("iphlpapi.GetIpPathEntry", interfaceLuid, destination.String(), windows.Errno(result))
+	}
+}
+
+func (wtpath *wtMibIppathRow) toPath() (*Path, error) {
+
+	if wtpath == nil {
+		return nil, nil
+	}
+
+	source, err := wtpath.Source.toSockaddrInet()
+
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := wtpath.Destination.toSockaddrInet()
+
+	if err != nil {
+		return nil, err
+	}
+
+	nextHop, err := wtpath.CurrentNextHop.toSockaddrInet()
+
+	if err != nil {
+		return nil, err
+	}
+
+	path := &Path{
+		Source:            *source,
+		Destination:       *destination,
+		InterfaceLuid:     wtpath.InterfaceLuid,
+		InterfaceIndex:    wtpath.InterfaceIndex,
+		CurrentNextHop:    *nextHop,
+		PathMtu:           wtpath.PathMtu,
+		RttMean:           wtpath.RttMean,
+		RttDeviation:      wtpath.RttDeviation,
+		IsReachable:       uint8ToBool(wtpath.IsReachable),
+		LinkTransmitSpeed: wtpath.LinkTransmitSpeed,
+		LinkReceiveSpeed:  wtpath.LinkReceiveSpeed,
+	}
+
+	if path.IsReachable {
+		path.LastReachable = wtpath.LastReachable
+	} else {
+		path.LastUnreachable = wtpath.LastReachable
+	}
+
+	return path, nil
+}
@@ -0,0 +1,26 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+// MIB_IPPATH_ROW defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_ippath_row).
+type wtMibIppathRow struct {
+	Source         wtSockaddrInet
+	Destination    wtSockaddrInet
+	InterfaceLuid  uint64 // Windows type: NET_LUID
+	InterfaceIndex uint32 // Windows type: NET_IFINDEX
+	CurrentNextHop wtSockaddrInet
+	PathMtu        uint32 // Windows type: ULONG
+	RttMean        uint32 // Windows type: ULONG
+	RttDeviation   uint32 // Windows type: ULONG
+	LastReachable  uint32 // Windows type: ULONG. Union with LastUnreachable.
+	IsReachable    uint8  // Windows type: BOOLEAN
+
+	offset1 [7]byte // Layout correction field
+
+	LinkTransmitSpeed uint64 // Windows type: ULONG64
+	LinkReceiveSpeed  uint64 // Windows type: ULONG64
+}
@@ -0,0 +1,115 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibIppathRowSize(t *testing.T) {
+
+	const actualWtMibIppathRowSize = unsafe.Sizeof(wtMibIppathRow{})
+
+	if actualWtMibIppathRowSize != wtMibIppathRow_Size {
+		t.Errorf("Size of wtMibIppathRow is %d, although %d is expected.", actualWtMibIppathRowSize,
+			wtMibIppathRow_Size)
+	}
+}
+
+func TestWtMibIppathRowOffsets(t *testing.T) {
+
+	s := wtMibIppathRow{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.Destination)) - sp
+
+	if offset != wtMibIppathRow_Destination_Offset {
+		t.Errorf("wtMibIppathRow.Destination offset is %d although %d is expected", offset,
+			wtMibIppathRow_Destination_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.InterfaceLuid)) - sp
+
+	if offset != wtMibIppathRow_InterfaceLuid_Offset {
+		t.Errorf("wtMibIppathRow.InterfaceLuid offset is %d although %d is expected", offset,
+			wtMibIppathRow_InterfaceLuid_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.InterfaceIndex)) - sp
+
+	if offset != wtMibIppathRow_InterfaceIndex_Offset {
+		t.Errorf("wtMibIppathRow.InterfaceIndex offset is %d although %d is expected", offset,
+			wtMibIppathRow_InterfaceIndex_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.CurrentNextHop)) - sp
+
+	if offset != wtMibIppathRow_CurrentNextHop_Offset {
+		t.Errorf("wtMibIppathRow.CurrentNextHop offset is %d although %d is expected", offset,
+			wtMibIppathRow_CurrentNextHop_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.PathMtu)) - sp
+
+	if offset != wtMibIppathRow_PathMtu_Offset {
+		t.Errorf("wtMibIppathRow.PathMtu offset is %d although %d is expected", offset,
+			wtMibIppathRow_PathMtu_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.RttMean)) - sp
+
+	if offset != wtMibIppathRow_RttMean_Offset {
+		t.Errorf("wtMibIppathRow.RttMean offset is %d although %d is expected", offset,
+			wtMibIppathRow_RttMean_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.RttDeviation)) - sp
+
+	if offset != wtMibIppathRow_RttDeviation_Offset {
+		t.Errorf("wtMibIppathRow.RttDeviation offset is %d although %d is expected", offset,
+			wtMibIppathRow_RttDeviation_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.LastReachable)) - sp
+
+	if offset != wtMibIppathRow_LastReachable_Offset {
+		t.Errorf("wtMibIppathRow.LastReachable offset is %d although %d is expected", offset,
+			wtMibIppathRow_LastReachable_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.IsReachable)) - sp
+
+	if offset != wtMibIppathRow_IsReachable_Offset {
+		t.Errorf("wtMibIppathRow.IsReachable offset is %d although %d is expected", offset,
+			wtMibIppathRow_IsReachable_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.LinkTransmitSpeed)) - sp
+
+	if offset != wtMibIppathRow_LinkTransmitSpeed_Offset {
+		t.Errorf("wtMibIppathRow.LinkTransmitSpeed offset is %d although %d is expected", offset,
+			wtMibIppathRow_LinkTransmitSpeed_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.LinkReceiveSpeed)) - sp
+
+	if offset != wtMibIppathRow_LinkReceiveSpeed_Offset {
+		t.Errorf("wtMibIppathRow.LinkReceiveSpeed offset is %d although %d is expected", offset,
+			wtMibIppathRow_LinkReceiveSpeed_Offset)
+		return
+	}
+}
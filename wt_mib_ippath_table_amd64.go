@@ -0,0 +1,13 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+// MIB_IPPATH_TABLE defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_ippath_table)
+type wtMibIppathTable struct {
+	NumEntries uint32 // Windows type: ULONG
+	Table      [anySize]wtMibIppathRow
+}
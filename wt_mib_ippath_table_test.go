@@ -0,0 +1,35 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibIppathTableSize(t *testing.T) {
+
+	const actualWtMibIppathTableSize = unsafe.Sizeof(wtMibIppathTable{})
+
+	if actualWtMibIppathTableSize != wtMibIppathTable_Size {
+		t.Errorf("Size of wtMibIppathTable is %d, although %d is expected.", actualWtMibIppathTableSize,
+			wtMibIppathTable_Size)
+	}
+}
+
+func TestWtMibIppathTableOffsets(t *testing.T) {
+
+	s := wtMibIppathTable{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.Table)) - sp
+
+	if offset != wtMibIppathTable_Table_Offset {
+		t.Errorf("wtMibIppathTable.Table offset is %d although %d is expected", offset,
+			wtMibIppathTable_Table_Offset)
+		return
+	}
+}
@@ -0,0 +1,104 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// MIB_IPSTATS_LH defined in ipmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/ipmib/ns-ipmib-mib_ipstats_lh)
+type wtMibIpstats struct {
+	Forwarding      uint32 // Windows type: DWORD (union with MIB_IPSTATS_FORWARDING)
+	DefaultTTL      uint32 // Windows type: DWORD
+	InReceives      uint32 // Windows type: DWORD
+	InHdrErrors     uint32 // Windows type: DWORD
+	InAddrErrors    uint32 // Windows type: DWORD
+	ForwDatagrams   uint32 // Windows type: DWORD
+	InUnknownProtos uint32 // Windows type: DWORD
+	InDiscards      uint32 // Windows type: DWORD
+	InDelivers      uint32 // Windows type: DWORD
+	OutRequests     uint32 // Windows type: DWORD
+	RoutingDiscards uint32 // Windows type: DWORD
+	OutDiscards     uint32 // Windows type: DWORD
+	OutNoRoutes     uint32 // Windows type: DWORD
+	ReasmTimeout    uint32 // Windows type: DWORD
+	ReasmReqds      uint32 // Windows type: DWORD
+	ReasmOks        uint32 // Windows type: DWORD
+	ReasmFails      uint32 // Windows type: DWORD
+	FragOks         uint32 // Windows type: DWORD
+	FragFails       uint32 // Windows type: DWORD
+	FragCreates     uint32 // Windows type: DWORD
+	NumIf           uint32 // Windows type: DWORD
+	NumAddr         uint32 // Windows type: DWORD
+	NumRoutes       uint32 // Windows type: DWORD
+}
+
+// MIB_IPSTATS_FORWARDING defined in ipmib.h
+const (
+	mibIpForwarding    = 1
+	mibIpNotForwarding = 2
+)
+
+func checkStatisticsFamily(family AddressFamily) error {
+	if family != AF_INET && family != AF_INET6 {
+		return newKindError(ErrInvalidParameter, "argument 'family' has to be either AF_INET or AF_INET6")
+	}
+	return nil
+}
+
+// Uses GetIpStatisticsEx function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getipstatisticsex).
+func getWtMibIpstats(family AddressFamily) (*wtMibIpstats, error) {
+
+	if err := checkStatisticsFamily(family); err != nil {
+		return nil, err
+	}
+
+	stats := wtMibIpstats{}
+
+	result := getIpStatisticsEx(&stats, family)
+
+	if result == 0 {
+		return &stats, nil
+	} else {
+		return nil, newOperationError("iphlpapi.GetIpStatisticsEx", 0, "", windows.Errno(result))
+	}
+}
+
+func (stats *wtMibIpstats) toIpStatistics(family AddressFamily) *IpStatistics {
+
+	if stats == nil {
+		return nil
+	}
+
+	return &IpStatistics{
+		Family:          family,
+		Forwarding:      stats.Forwarding == mibIpForwarding,
+		DefaultTTL:      stats.DefaultTTL,
+		InReceives:      stats.InReceives,
+		InHdrErrors:     stats.InHdrErrors,
+		InAddrErrors:    stats.InAddrErrors,
+		ForwDatagrams:   stats.ForwDatagrams,
+		InUnknownProtos: stats.InUnknownProtos,
+		InDiscards:      stats.InDiscards,
+		InDelivers:      stats.InDelivers,
+		OutRequests:     stats.OutRequests,
+		RoutingDiscards: stats.RoutingDiscards,
+		OutDiscards:     stats.OutDiscards,
+		OutNoRoutes:     stats.OutNoRoutes,
+		ReasmTimeout:    stats.ReasmTimeout,
+		ReasmReqds:      stats.ReasmReqds,
+		ReasmOks:        stats.ReasmOks,
+		ReasmFails:      stats.ReasmFails,
+		FragOks:         stats.FragOks,
+		FragFails:       stats.FragFails,
+		FragCreates:     stats.FragCreates,
+		NumIf:           stats.NumIf,
+		NumAddr:         stats.NumAddr,
+		NumRoutes:       stats.NumRoutes,
+	}
+}
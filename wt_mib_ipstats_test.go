@@ -0,0 +1,91 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibIpstatsSize(t *testing.T) {
+
+	const actualWtMibIpstatsSize = unsafe.Sizeof(wtMibIpstats{})
+
+	if actualWtMibIpstatsSize != wtMibIpstats_Size {
+		t.Errorf("Size of wtMibIpstats is %d, although %d is expected.", actualWtMibIpstatsSize,
+			wtMibIpstats_Size)
+	}
+}
+
+func TestWtMibIpstatsOffsets(t *testing.T) {
+
+	s := wtMibIpstats{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.DefaultTTL)) - sp
+
+	if offset != wtMibIpstats_DefaultTTL_Offset {
+		t.Errorf("wtMibIpstats.DefaultTTL offset is %d although %d is expected", offset,
+			wtMibIpstats_DefaultTTL_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.InReceives)) - sp
+
+	if offset != wtMibIpstats_InReceives_Offset {
+		t.Errorf("wtMibIpstats.InReceives offset is %d although %d is expected", offset,
+			wtMibIpstats_InReceives_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.ForwDatagrams)) - sp
+
+	if offset != wtMibIpstats_ForwDatagrams_Offset {
+		t.Errorf("wtMibIpstats.ForwDatagrams offset is %d although %d is expected", offset,
+			wtMibIpstats_ForwDatagrams_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.OutRequests)) - sp
+
+	if offset != wtMibIpstats_OutRequests_Offset {
+		t.Errorf("wtMibIpstats.OutRequests offset is %d although %d is expected", offset,
+			wtMibIpstats_OutRequests_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.ReasmTimeout)) - sp
+
+	if offset != wtMibIpstats_ReasmTimeout_Offset {
+		t.Errorf("wtMibIpstats.ReasmTimeout offset is %d although %d is expected", offset,
+			wtMibIpstats_ReasmTimeout_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.ReasmFails)) - sp
+
+	if offset != wtMibIpstats_ReasmFails_Offset {
+		t.Errorf("wtMibIpstats.ReasmFails offset is %d although %d is expected", offset,
+			wtMibIpstats_ReasmFails_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.FragCreates)) - sp
+
+	if offset != wtMibIpstats_FragCreates_Offset {
+		t.Errorf("wtMibIpstats.FragCreates offset is %d although %d is expected", offset,
+			wtMibIpstats_FragCreates_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.NumRoutes)) - sp
+
+	if offset != wtMibIpstats_NumRoutes_Offset {
+		t.Errorf("wtMibIpstats.NumRoutes offset is %d although %d is expected", offset,
+			wtMibIpstats_NumRoutes_Offset)
+		return
+	}
+}
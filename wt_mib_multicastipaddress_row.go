@@ -0,0 +1,93 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"golang.org/x/sys/windows"
+	"net"
+	"unsafe"
+)
+
+// Uses GetMulticastIpAddressTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getmulticastipaddresstable)
+func getWtMibMulticastipaddressRows(family AddressFamily) ([]*wtMibMulticastipaddressRow, error) {
+
+	var pTable *wtMibMulticastipaddressTable = nil
+
+	result := getMulticastIpAddressTable(family, unsafe.Pointer(&pTable))
+
+	if pTable != nil {
+		defer freeMibTable(unsafe.Pointer(pTable))
+	}
+
+	if result != 0 {
+		return nil, newOperationError("iphlpapi.GetMulticastIpAddressTable", 0, "", windows.Errno(result))
+	}
+
+	addresses := make([]*wtMibMulticastipaddressRow, pTable.NumEntries, pTable.NumEntries)
+
+	pFirstRow := uintptr(unsafe.Pointer(&pTable.Table[0]))
+	rowSize := uintptr(wtMibMulticastipaddressRow_Size) // Should be equal to unsafe.Sizeof(pTable.Table[0])
+
+	for i := uint32(0); i < pTable.NumEntries; i++ {
+		// Dereferencing and rereferencing in order to force copying.
+		row := *(*wtMibMulticastipaddressRow)(unsafe.Pointer(pFirstRow + rowSize*uintptr(i)))
+		addresses[i] = &row
+	}
+
+	return addresses, nil
+}
+
+func getWtMibMulticastipaddressRowAlt(interfaceLuid uint64, ip *net.IP) (*wtMibMulticastipaddressRow, error) {
+
+	wtsainet, err := createWtSockaddrInet(ip, 0)
+
+	if err == nil {
+		return getWtMibMulticastipaddressRow(interfaceLuid, wtsainet)
+	} else {
+		return nil, err
+	}
+}
+
+// Corresponds to GetMulticastIpAddressEntry function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/nf-netioapi-getmulticastipaddressentry)
+func getWtMibMulticastipaddressRow(interfaceLuid uint64, wtsainet *wtSockaddrInet) (*wtMibMulticastipaddressRow,
+	error) {
+
+	row := &wtMibMulticastipaddressRow{
+		Address:       *wtsainet,
+		InterfaceLuid: interfaceLuid,
+	}
+
+	result := getMulticastIpAddressEntry(row)
+
+	if result == 0 {
+		return row, nil
+	} else {
+		return nil, newOperationError("iphlpapi.GetMulticastIpAddressEntry", interfaceLuid, wtsainet.String(),
+			windows.Errno(result))
+	}
+}
+
+func (wtmia *wtMibMulticastipaddressRow) toMulticastIpAddressRow() (*MulticastIpAddressRow, error) {
+
+	if wtmia == nil {
+		return nil, nil
+	}
+
+	sainet, err := wtmia.Address.toSockaddrInet()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &MulticastIpAddressRow{
+		Address:        *sainet,
+		InterfaceLuid:  wtmia.InterfaceLuid,
+		InterfaceIndex: wtmia.InterfaceIndex,
+		ScopeId:        wtmia.ScopeId,
+	}, nil
+}
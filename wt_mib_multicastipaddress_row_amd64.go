@@ -0,0 +1,22 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+// MIB_MULTICASTIPADDRESS_ROW defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-_mib_multicastipaddress_row).
+type wtMibMulticastipaddressRow struct {
+	//
+	// Key Structure.
+	//
+	Address        wtSockaddrInet
+	InterfaceIndex uint32 // Windows type: NET_IFINDEX
+	InterfaceLuid  uint64 // Windows type: NET_LUID
+
+	//
+	// Read-Only Fields.
+	//
+	ScopeId uint32 // Windows type: SCOPE_ID
+}
@@ -0,0 +1,51 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibMulticastipaddressRowSize(t *testing.T) {
+
+	const actualWtMibMulticastipaddressRowSize = unsafe.Sizeof(wtMibMulticastipaddressRow{})
+
+	if actualWtMibMulticastipaddressRowSize != wtMibMulticastipaddressRow_Size {
+		t.Errorf("Size of wtMibMulticastipaddressRow is %d, although %d is expected.", actualWtMibMulticastipaddressRowSize,
+			wtMibMulticastipaddressRow_Size)
+	}
+}
+
+func TestWtMibMulticastipaddressRowOffsets(t *testing.T) {
+
+	s := wtMibMulticastipaddressRow{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.InterfaceIndex)) - sp
+
+	if offset != wtMibMulticastipaddressRow_InterfaceIndex_Offset {
+		t.Errorf("wtMibMulticastipaddressRow.InterfaceIndex offset is %d although %d is expected", offset,
+			wtMibMulticastipaddressRow_InterfaceIndex_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.InterfaceLuid)) - sp
+
+	if offset != wtMibMulticastipaddressRow_InterfaceLuid_Offset {
+		t.Errorf("wtMibMulticastipaddressRow.InterfaceLuid offset is %d although %d is expected", offset,
+			wtMibMulticastipaddressRow_InterfaceLuid_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.ScopeId)) - sp
+
+	if offset != wtMibMulticastipaddressRow_ScopeId_Offset {
+		t.Errorf("wtMibMulticastipaddressRow.ScopeId offset is %d although %d is expected", offset,
+			wtMibMulticastipaddressRow_ScopeId_Offset)
+		return
+	}
+}
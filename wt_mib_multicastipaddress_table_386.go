@@ -0,0 +1,16 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+// MIB_MULTICASTIPADDRESS_TABLE defined in netioapi.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netioapi/ns-netioapi-mib_multicastipaddress_table)
+type wtMibMulticastipaddressTable struct {
+	NumEntries uint32 // Windows type: ULONG
+
+	offset1 [4]byte // Layout correction field
+
+	Table [anySize]wtMibMulticastipaddressRow
+}
@@ -0,0 +1,35 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibMulticastipaddressTableSize(t *testing.T) {
+
+	const actualWtMibMulticastipaddressTableSize = unsafe.Sizeof(wtMibMulticastipaddressTable{})
+
+	if actualWtMibMulticastipaddressTableSize != wtMibMulticastipaddressTable_Size {
+		t.Errorf("Size of wtMibMulticastipaddressTable is %d, although %d is expected.", actualWtMibMulticastipaddressTableSize,
+			wtMibMulticastipaddressTable_Size)
+	}
+}
+
+func TestWtMibMulticastipaddressTableOffsets(t *testing.T) {
+
+	s := wtMibMulticastipaddressTable{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.Table)) - sp
+
+	if offset != wtMibMulticastipaddressTable_Table_Offset {
+		t.Errorf("wtMibMulticastipaddressTable.Table offset is %d although %d is expected", offset,
+			wtMibMulticastipaddressTable_Table_Offset)
+		return
+	}
+}
@@ -0,0 +1,55 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// Uses GetTcpStatisticsEx2 function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-gettcpstatisticsex2).
+func getWtMibTcpstats2(family AddressFamily) (*wtMibTcpstats2, error) {
+
+	if err := checkStatisticsFamily(family); err != nil {
+		return nil, err
+	}
+
+	stats := wtMibTcpstats2{}
+
+	result := getTcpStatisticsEx2(&stats, family)
+
+	if result == 0 {
+		return &stats, nil
+	} else {
+		return nil, newOperationError("iphlpapi.GetTcpStatisticsEx2", 0, "", windows.Errno(result))
+	}
+}
+
+func (stats *wtMibTcpstats2) toTcpStatistics(family AddressFamily) *TcpStatistics {
+
+	if stats == nil {
+		return nil
+	}
+
+	return &TcpStatistics{
+		Family:       family,
+		RtoAlgorithm: stats.RtoAlgorithm,
+		RtoMin:       stats.RtoMin,
+		RtoMax:       stats.RtoMax,
+		MaxConn:      stats.MaxConn,
+		ActiveOpens:  stats.ActiveOpens,
+		PassiveOpens: stats.PassiveOpens,
+		AttemptFails: stats.AttemptFails,
+		EstabResets:  stats.EstabResets,
+		CurrEstab:    stats.CurrEstab,
+		InSegs:       stats.InSegs,
+		OutSegs:      stats.OutSegs,
+		RetransSegs:  stats.RetransSegs,
+		InErrs:       stats.InErrs,
+		OutRsts:      stats.OutRsts,
+		NumConns:     stats.NumConns,
+	}
+}
@@ -0,0 +1,29 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+// MIB_TCPSTATS2 defined in tcpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/tcpmib/ns-tcpmib-mib_tcpstats2)
+type wtMibTcpstats2 struct {
+	RtoAlgorithm TcpRtoAlgorithm
+	RtoMin       uint32 // Windows type: DWORD
+	RtoMax       uint32 // Windows type: DWORD
+	MaxConn      uint32 // Windows type: DWORD
+	ActiveOpens  uint32 // Windows type: DWORD
+	PassiveOpens uint32 // Windows type: DWORD
+	AttemptFails uint32 // Windows type: DWORD
+	EstabResets  uint32 // Windows type: DWORD
+	CurrEstab    uint32 // Windows type: DWORD
+
+	offset1 [4]uint8 // Layout correction field
+
+	InSegs      uint64 // Windows type: DWORD64
+	OutSegs     uint64 // Windows type: DWORD64
+	RetransSegs uint32 // Windows type: DWORD
+	InErrs      uint32 // Windows type: DWORD
+	OutRsts     uint32 // Windows type: DWORD
+	NumConns    uint32 // Windows type: DWORD
+}
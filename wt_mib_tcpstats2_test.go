@@ -0,0 +1,75 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibTcpstats2Size(t *testing.T) {
+
+	const actualWtMibTcpstats2Size = unsafe.Sizeof(wtMibTcpstats2{})
+
+	if actualWtMibTcpstats2Size != wtMibTcpstats2_Size {
+		t.Errorf("Size of wtMibTcpstats2 is %d, although %d is expected.", actualWtMibTcpstats2Size,
+			wtMibTcpstats2_Size)
+	}
+}
+
+func TestWtMibTcpstats2Offsets(t *testing.T) {
+
+	s := wtMibTcpstats2{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.RtoMin)) - sp
+
+	if offset != wtMibTcpstats2_RtoMin_Offset {
+		t.Errorf("wtMibTcpstats2.RtoMin offset is %d although %d is expected", offset,
+			wtMibTcpstats2_RtoMin_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.CurrEstab)) - sp
+
+	if offset != wtMibTcpstats2_CurrEstab_Offset {
+		t.Errorf("wtMibTcpstats2.CurrEstab offset is %d although %d is expected", offset,
+			wtMibTcpstats2_CurrEstab_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.InSegs)) - sp
+
+	if offset != wtMibTcpstats2_InSegs_Offset {
+		t.Errorf("wtMibTcpstats2.InSegs offset is %d although %d is expected", offset,
+			wtMibTcpstats2_InSegs_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.OutSegs)) - sp
+
+	if offset != wtMibTcpstats2_OutSegs_Offset {
+		t.Errorf("wtMibTcpstats2.OutSegs offset is %d although %d is expected", offset,
+			wtMibTcpstats2_OutSegs_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.RetransSegs)) - sp
+
+	if offset != wtMibTcpstats2_RetransSegs_Offset {
+		t.Errorf("wtMibTcpstats2.RetransSegs offset is %d although %d is expected", offset,
+			wtMibTcpstats2_RetransSegs_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.NumConns)) - sp
+
+	if offset != wtMibTcpstats2_NumConns_Offset {
+		t.Errorf("wtMibTcpstats2.NumConns offset is %d although %d is expected", offset,
+			wtMibTcpstats2_NumConns_Offset)
+		return
+	}
+}
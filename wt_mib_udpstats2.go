@@ -0,0 +1,45 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// Uses GetUdpStatisticsEx2 function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getudpstatisticsex2).
+func getWtMibUdpstats2(family AddressFamily) (*wtMibUdpstats2, error) {
+
+	if err := checkStatisticsFamily(family); err != nil {
+		return nil, err
+	}
+
+	stats := wtMibUdpstats2{}
+
+	result := getUdpStatisticsEx2(&stats, family)
+
+	if result == 0 {
+		return &stats, nil
+	} else {
+		return nil, newOperationError("iphlpapi.GetUdpStatisticsEx2", 0, "", windows.Errno(result))
+	}
+}
+
+func (stats *wtMibUdpstats2) toUdpStatistics(family AddressFamily) *UdpStatistics {
+
+	if stats == nil {
+		return nil
+	}
+
+	return &UdpStatistics{
+		Family:       family,
+		InDatagrams:  stats.InDatagrams,
+		NoPorts:      stats.NoPorts,
+		InErrors:     stats.InErrors,
+		OutDatagrams: stats.OutDatagrams,
+		NumAddrs:     stats.NumAddrs,
+	}
+}
@@ -0,0 +1,16 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+// MIB_UDPSTATS2 defined in udpmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/udpmib/ns-udpmib-mib_udpstats2)
+type wtMibUdpstats2 struct {
+	InDatagrams  uint64 // Windows type: DWORD64
+	NoPorts      uint32 // Windows type: DWORD
+	InErrors     uint32 // Windows type: DWORD
+	OutDatagrams uint64 // Windows type: DWORD64
+	NumAddrs     uint32 // Windows type: DWORD
+}
@@ -0,0 +1,59 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtMibUdpstats2Size(t *testing.T) {
+
+	const actualWtMibUdpstats2Size = unsafe.Sizeof(wtMibUdpstats2{})
+
+	if actualWtMibUdpstats2Size != wtMibUdpstats2_Size {
+		t.Errorf("Size of wtMibUdpstats2 is %d, although %d is expected.", actualWtMibUdpstats2Size,
+			wtMibUdpstats2_Size)
+	}
+}
+
+func TestWtMibUdpstats2Offsets(t *testing.T) {
+
+	s := wtMibUdpstats2{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.NoPorts)) - sp
+
+	if offset != wtMibUdpstats2_NoPorts_Offset {
+		t.Errorf("wtMibUdpstats2.NoPorts offset is %d although %d is expected", offset,
+			wtMibUdpstats2_NoPorts_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.InErrors)) - sp
+
+	if offset != wtMibUdpstats2_InErrors_Offset {
+		t.Errorf("wtMibUdpstats2.InErrors offset is %d although %d is expected", offset,
+			wtMibUdpstats2_InErrors_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.OutDatagrams)) - sp
+
+	if offset != wtMibUdpstats2_OutDatagrams_Offset {
+		t.Errorf("wtMibUdpstats2.OutDatagrams offset is %d although %d is expected", offset,
+			wtMibUdpstats2_OutDatagrams_Offset)
+		return
+	}
+
+	offset = uintptr(unsafe.Pointer(&s.NumAddrs)) - sp
+
+	if offset != wtMibUdpstats2_NumAddrs_Offset {
+		t.Errorf("wtMibUdpstats2.NumAddrs offset is %d although %d is expected", offset,
+			wtMibUdpstats2_NumAddrs_Offset)
+		return
+	}
+}
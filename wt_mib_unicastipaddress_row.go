@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"golang.org/x/sys/windows"
 	"net"
-	"os"
 	"unsafe"
 )
 
@@ -26,7 +25,7 @@ func getWtMibUnicastipaddressRows(family AddressFamily) ([]*wtMibUnicastipaddres
 	}
 
 	if result != 0 {
-		return nil, os.NewSyscallError("iphlpapi.GetUnicastIpAddressTable", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetUnicastIpAddressTable", 0, "", windows.Errno(result))
 	}
 
 	addresses := make([]*wtMibUnicastipaddressRow, pTable.NumEntries, pTable.NumEntries)
@@ -60,7 +59,8 @@ func getWtMibUnicastipaddressRow(interfaceLuid uint64, ip *net.IP) (*wtMibUnicas
 	if result == 0 {
 		return &row, nil
 	} else {
-		return nil, os.NewSyscallError("iphlpapi.GetUnicastIpAddressEntry", windows.Errno(result))
+		return nil, newOperationError("iphlpapi.GetUnicastIpAddressEntry", interfaceLuid, wtsainet.String(),
+			windows.Errno(result))
 	}
 }
 
@@ -95,13 +95,31 @@ func getInitializedWtMibUnicastipaddressRow(interfaceLuid uint64) *wtMibUnicasti
 }
 
 func createAndAddWtMibUnicastipaddressRow(interfaceLuid uint64, ipnet *net.IPNet) error {
+	return createAndAddWtMibUnicastipaddressRowWithOptions(interfaceLuid, ipnet, nil)
+}
 
-	wtsainet, err := createWtSockaddrInet(&ipnet.IP, 0)
+func createAndAddWtMibUnicastipaddressRowWithOptions(interfaceLuid uint64, ipnet *net.IPNet,
+	options *AddressOptions) error {
+
+	row, err := newWtMibUnicastipaddressRow(interfaceLuid, ipnet, options)
 
 	if err != nil {
 		return err
 	}
 
+	return row.add()
+}
+
+// Returns initialized row of 'ipnet' address with 'options' (which may be nil) applied.
+func newWtMibUnicastipaddressRow(interfaceLuid uint64, ipnet *net.IPNet,
+	options *AddressOptions) (*wtMibUnicastipaddressRow, error) {
+
+	wtsainet, err := createWtSockaddrInet(&ipnet.IP, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
 	row := getInitializedWtMibUnicastipaddressRow(interfaceLuid)
 
 	row.Address = *wtsainet
@@ -110,7 +128,13 @@ func createAndAddWtMibUnicastipaddressRow(interfaceLuid uint64, ipnet *net.IPNet
 
 	row.OnLinkPrefixLength = uint8(ones)
 
-	return row.add()
+	if options != nil {
+		if err := options.copyTo(row); err != nil {
+			return nil, err
+		}
+	}
+
+	return row, nil
 }
 
 // Uses CreateUnicastIpAddressEntry function
@@ -122,7 +146,8 @@ func (row *wtMibUnicastipaddressRow) add() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.CreateUnicastIpAddressEntry: "+row.Address.String(), windows.Errno(result))
+		return newOperationError("iphlpapi.CreateUnicastIpAddressEntry", row.InterfaceLuid, row.Address.String(),
+			windows.Errno(result))
 	}
 }
 
@@ -135,7 +160,8 @@ func (row *wtMibUnicastipaddressRow) set() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.SetUnicastIpAddressEntry", windows.Errno(result))
+		return newOperationError("iphlpapi.SetUnicastIpAddressEntry", row.InterfaceLuid, row.Address.String(),
+			windows.Errno(result))
 	}
 }
 
@@ -148,7 +174,8 @@ func (row *wtMibUnicastipaddressRow) delete() error {
 	if result == 0 {
 		return nil
 	} else {
-		return os.NewSyscallError("iphlpapi.DeleteUnicastIpAddressEntry", windows.Errno(result))
+		return newOperationError("iphlpapi.DeleteUnicastIpAddressEntry", row.InterfaceLuid, row.Address.String(),
+			windows.Errno(result))
 	}
 }
 
@@ -0,0 +1,179 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// CLSID_ConnectionManager and IID_INetConnectionManager defined in netcon.h
+var (
+	clsidConnectionManager = windows.GUID{
+		Data1: 0xba126ad1,
+		Data2: 0x2166,
+		Data3: 0x11d1,
+		Data4: [8]byte{0xb1, 0xd0, 0x00, 0x80, 0x5f, 0xc1, 0x27, 0x0e},
+	}
+	iidINetConnectionManager = windows.GUID{
+		Data1: 0xc08956a2,
+		Data2: 0x1cd3,
+		Data3: 0x11d1,
+		Data4: [8]byte{0xb1, 0xc5, 0x00, 0x80, 0x5f, 0xc1, 0x27, 0x0e},
+	}
+)
+
+const (
+	clsctx_all   = 0x17 // CLSCTX_ALL defined in combaseapi.h
+	ncme_default = 0    // NCME_DEFAULT defined in netcon.h
+
+	// Virtual table indexes of the methods used, as declared in netcon.h (the first three are IUnknown's).
+	iUnknown_Release                      = 2
+	iNetConnectionManager_EnumConnections = 3
+	iEnumNetConnection_Next               = 3
+	iNetConnection_GetProperties          = 7
+	iNetConnection_Rename                 = 9
+)
+
+// NETCON_PROPERTIES defined in netcon.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netcon/ns-netcon-netcon_properties).
+type wtNetconProperties struct {
+	GuidId          windows.GUID
+	Name            *uint16 // Windows type: LPWSTR
+	DeviceName      *uint16 // Windows type: LPWSTR
+	Status          uint32  // Windows type: NETCON_STATUS
+	MediaType       uint32  // Windows type: NETCON_MEDIATYPE
+	Character       uint32  // Windows type: DWORD
+	ClsidThisObject windows.GUID
+	ClsidUiObject   windows.GUID
+}
+
+// COM interface pointer, i.e. pointer to the pointer to the interface's virtual table. None of the interfaces used has
+// more than 10 methods.
+type wtComObject struct {
+	vtable *[10]uintptr
+}
+
+// Calls the method at 'index' of the virtual table, and returns its HRESULT.
+func (obj *wtComObject) call(index int, args ...uintptr) uint32 {
+
+	var a [5]uintptr
+	copy(a[:], args)
+
+	r0, _, _ := syscall.Syscall6(obj.vtable[index], uintptr(len(args)+1), uintptr(unsafe.Pointer(obj)), a[0], a[1],
+		a[2], a[3], a[4])
+
+	return uint32(r0)
+}
+
+func (obj *wtComObject) release() {
+	obj.call(iUnknown_Release)
+}
+
+// Returns OperationError of a failed COM call. HRESULTs wrapping Windows errors (FACILITY_WIN32) are unwrapped, so the
+// error matches the Err... kinds (e.g. E_ACCESSDENIED matches ErrAccessDenied).
+func hresultError(op string, interfaceLuid uint64, hr uint32) error {
+
+	if hr&0xffff0000 == 0x80070000 {
+		hr &= 0xffff
+	}
+
+	return newOperationError(op, interfaceLuid, "", windows.Errno(hr))
+}
+
+// Renames the network connection (i.e. changes alias of the interface) with specified GUID through
+// INetConnection::Rename, which is what the network connections folder does
+// (https://docs.microsoft.com/en-us/windows/desktop/api/netcon/nf-netcon-inetconnection-rename).
+func renameNetConnection(interfaceLuid uint64, guid *windows.GUID, name string) error {
+
+	name16, err := windows.UTF16PtrFromString(name)
+
+	if err != nil {
+		return err
+	}
+
+	// COM is initialized per OS thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	err = windows.CoInitializeEx(0, windows.COINIT_APARTMENTTHREADED)
+
+	switch err {
+	case nil, syscall.Errno(windows.S_FALSE):
+		defer windows.CoUninitialize()
+	case syscall.Errno(windows.RPC_E_CHANGED_MODE):
+		// Already initialized by someone else, with the other concurrency model, which works as well.
+	default:
+		return hresultError("ole32.CoInitializeEx", interfaceLuid, uint32(err.(syscall.Errno)))
+	}
+
+	var manager *wtComObject
+
+	hr := coCreateInstance(&clsidConnectionManager, 0, clsctx_all, &iidINetConnectionManager,
+		unsafe.Pointer(&manager))
+
+	if hr != 0 {
+		return hresultError("ole32.CoCreateInstance", interfaceLuid, hr)
+	}
+
+	defer manager.release()
+
+	var enum *wtComObject
+
+	if hr := manager.call(iNetConnectionManager_EnumConnections, ncme_default,
+		uintptr(unsafe.Pointer(&enum))); hr != 0 {
+		return hresultError("INetConnectionManager::EnumConnections", interfaceLuid, hr)
+	}
+
+	defer enum.release()
+
+	for {
+
+		var connection *wtComObject
+		fetched := uint32(0)
+
+		// S_FALSE means there are no more connections.
+		hr := enum.call(iEnumNetConnection_Next, 1, uintptr(unsafe.Pointer(&connection)),
+			uintptr(unsafe.Pointer(&fetched)))
+
+		if hr != 0 || fetched == 0 {
+			break
+		}
+
+		if !netConnectionHasGuid(connection, guid) {
+			connection.release()
+			continue
+		}
+
+		hr = connection.call(iNetConnection_Rename, uintptr(unsafe.Pointer(name16)))
+		connection.release()
+
+		if hr != 0 {
+			return hresultError("INetConnection::Rename", interfaceLuid, hr)
+		}
+
+		return nil
+	}
+
+	return newKindError(ErrNotFound, "renameNetConnection() - network connection %s not found", guidToString(guid))
+}
+
+// Returns whether the connection's GUID is 'guid'; false if its properties can't be read.
+func netConnectionHasGuid(connection *wtComObject, guid *windows.GUID) bool {
+
+	var props *wtNetconProperties
+
+	if hr := connection.call(iNetConnection_GetProperties, uintptr(unsafe.Pointer(&props))); hr != 0 || props == nil {
+		return false
+	}
+
+	defer ncFreeNetconProperties(props)
+
+	return guidsEqual(&props.GuidId, guid)
+}
@@ -0,0 +1,13 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+// https://docs.microsoft.com/en-us/windows/desktop/api/ws2ipdef/ns-ws2ipdef-sockaddr_in6_pair
+// SOCKADDR_IN6_PAIR defined in ws2ipdef.h
+type wtSockaddrIn6Pair struct {
+	SourceAddress      *wtSockaddrIn6
+	DestinationAddress *wtSockaddrIn6
+}
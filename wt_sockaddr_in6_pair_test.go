@@ -0,0 +1,35 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWtSockaddrIn6PairSize(t *testing.T) {
+
+	const actualWtSockaddrIn6PairSize = unsafe.Sizeof(wtSockaddrIn6Pair{})
+
+	if actualWtSockaddrIn6PairSize != wtSockaddrIn6Pair_Size {
+		t.Errorf("Size of wtSockaddrIn6Pair is %d, although %d is expected.", actualWtSockaddrIn6PairSize,
+			wtSockaddrIn6Pair_Size)
+	}
+}
+
+func TestWtSockaddrIn6PairOffsets(t *testing.T) {
+
+	s := wtSockaddrIn6Pair{}
+	sp := uintptr(unsafe.Pointer(&s))
+
+	offset := uintptr(unsafe.Pointer(&s.DestinationAddress)) - sp
+
+	if offset != wtSockaddrIn6Pair_DestinationAddress_Offset {
+		t.Errorf("wtSockaddrIn6Pair.DestinationAddress offset is %d although %d is expected", offset,
+			wtSockaddrIn6Pair_DestinationAddress_Offset)
+		return
+	}
+}
@@ -226,7 +226,7 @@ func (addr *wtSockaddrInet) toWtSockaddrIn() (*wtSockaddrIn, error) {
 	if addr.isIPv4() {
 		return (*wtSockaddrIn)(unsafe.Pointer(addr)), nil
 	} else {
-		return nil, fmt.Errorf("toWtSockaddrIn() requires IPv4 input arguments")
+		return nil, newKindError(ErrInvalidParameter, "toWtSockaddrIn() requires IPv4 input arguments")
 	}
 }
 
@@ -239,7 +239,7 @@ func (addr *wtSockaddrInet) toWtSockaddrIn6() (*wtSockaddrIn6, error) {
 	if addr.isIPv6() {
 		return (*wtSockaddrIn6)(unsafe.Pointer(addr)), nil
 	} else {
-		return nil, fmt.Errorf("toWtSockaddrIn6() requires IPv6 input arguments")
+		return nil, newKindError(ErrInvalidParameter, "toWtSockaddrIn6() requires IPv6 input arguments")
 	}
 }
 
@@ -279,7 +279,7 @@ func (wtsa *wtSockaddrInet) toSockaddrInet() (*SockaddrInet, error) {
 		return &sainet, nil
 	}
 
-	return nil, fmt.Errorf("toSockaddrInet() requires IPv4 or IPv6 input argument")
+	return nil, newKindError(ErrInvalidParameter, "toSockaddrInet() requires IPv4 or IPv6 input argument")
 }
 
 func createWtSockaddrInet(address *net.IP, port uint16) (*wtSockaddrInet, error) {
@@ -302,7 +302,8 @@ func createWtSockaddrInet(address *net.IP, port uint16) (*wtSockaddrInet, error)
 	ipv6 := address.To16()
 
 	if ipv6 == nil {
-		return nil, fmt.Errorf("createWtSockaddrInet() input argument doesn't represent a valid IP address")
+		return nil, newKindError(ErrInvalidParameter,
+			"createWtSockaddrInet() input argument doesn't represent a valid IP address")
 	}
 
 	in6_addr, _ := netIpToWtIn6Addr(ipv6)
@@ -6,7 +6,6 @@
 package winipcfg
 
 import (
-	"fmt"
 	"unsafe"
 )
 
@@ -31,7 +30,8 @@ func (sa *wtSocketAddress) getWtSockaddrInet() (*wtSockaddrInet, error) {
 	}
 
 	if sa.lpSockaddr.sa_family != AF_INET && sa.lpSockaddr.sa_family != AF_INET6 {
-		return nil, fmt.Errorf("getWtSockaddrInet() - receiver's argument family has to be AF_INET or AF_INET6")
+		return nil, newKindError(ErrInvalidParameter,
+			"getWtSockaddrInet() - receiver's argument family has to be AF_INET or AF_INET6")
 	}
 
 	return (*wtSockaddrInet)(unsafe.Pointer(sa.lpSockaddr)), nil
@@ -0,0 +1,126 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2019 WireGuard LLC. All Rights Reserved.
+ */
+
+package winipcfg
+
+import (
+	"unsafe"
+
+	"github.com/starvpn/winipcfg-go/internal/sockettable"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	tcp_table_owner_module_all    = 8 // TCP_TABLE_OWNER_MODULE_ALL defined in iprtrmib.h
+	udp_table_owner_module        = 2 // UDP_TABLE_OWNER_MODULE defined in iprtrmib.h
+	tcpip_owner_module_info_basic = 0 // TCPIP_OWNER_MODULE_INFO_BASIC defined in iprtrmib.h
+)
+
+// TCPIP_OWNER_MODULE_BASIC_INFO defined in iprtrmib.h
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iprtrmib/ns-iprtrmib-tcpip_owner_module_basic_info)
+type wtTcpipOwnerModuleBasicInfo struct {
+	pModuleName *uint16 // Windows type: PWCHAR
+	pModulePath *uint16 // Windows type: PWCHAR
+}
+
+// Calls GetExtendedTcpTable or GetExtendedUdpTable (passed as 'get') until the buffer is large enough, and returns
+// the raw table.
+func getExtendedTableBytes(name string, get func(table unsafe.Pointer, size *uint32) uint32) ([]byte, error) {
+
+	size := uint32(0)
+
+	for {
+
+		var b []byte
+		var p unsafe.Pointer
+
+		if size > 0 {
+			b = make([]byte, size)
+			p = unsafe.Pointer(&b[0])
+		}
+
+		result := get(p, &size)
+
+		if result == 0 && b != nil {
+			return b, nil
+		}
+
+		if result != 0 && result != uint32(windows.ERROR_INSUFFICIENT_BUFFER) {
+			return nil, newOperationError(name, 0, "", windows.Errno(result))
+		}
+
+		if size < sockettable.HeaderSize {
+			size = sockettable.HeaderSize
+		}
+
+		// The table can grow between two calls, so leave some room.
+		size += size / 8
+	}
+}
+
+// Uses GetExtendedTcpTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getextendedtcptable).
+func getExtendedTcpTableBytes(family AddressFamily) ([]byte, error) {
+	return getExtendedTableBytes("iphlpapi.GetExtendedTcpTable", func(table unsafe.Pointer, size *uint32) uint32 {
+		return getExtendedTcpTable(table, size, false, uint32(family), tcp_table_owner_module_all, 0)
+	})
+}
+
+// Uses GetExtendedUdpTable function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getextendedudptable).
+func getExtendedUdpTableBytes(family AddressFamily) ([]byte, error) {
+	return getExtendedTableBytes("iphlpapi.GetExtendedUdpTable", func(table unsafe.Pointer, size *uint32) uint32 {
+		return getExtendedUdpTable(table, size, false, uint32(family), udp_table_owner_module, 0)
+	})
+}
+
+// Calls one of GetOwnerModuleFrom*Entry functions (passed as 'get') for the raw row. Returns empty strings if the
+// owning module cannot be determined.
+func getOwnerModule(rawRow []byte, get func(entry unsafe.Pointer, class uint32, buffer unsafe.Pointer,
+	size *uint32) uint32) (name, path string) {
+
+	if len(rawRow) == 0 {
+		return "", ""
+	}
+
+	size := uint32(unsafe.Sizeof(wtTcpipOwnerModuleBasicInfo{})) + 512
+
+	for {
+
+		b := make([]byte, size)
+
+		result := get(unsafe.Pointer(&rawRow[0]), tcpip_owner_module_info_basic, unsafe.Pointer(&b[0]), &size)
+
+		if result == uint32(windows.ERROR_INSUFFICIENT_BUFFER) && size > uint32(len(b)) {
+			continue
+		}
+
+		if result != 0 {
+			return "", ""
+		}
+
+		info := (*wtTcpipOwnerModuleBasicInfo)(unsafe.Pointer(&b[0]))
+
+		return windows.UTF16PtrToString(info.pModuleName), windows.UTF16PtrToString(info.pModulePath)
+	}
+}
+
+// Uses GetOwnerModuleFromTcpEntry or GetOwnerModuleFromTcp6Entry function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getownermodulefromtcpentry).
+func getTcpOwnerModule(family AddressFamily, rawRow []byte) (name, path string) {
+	if family == AF_INET {
+		return getOwnerModule(rawRow, getOwnerModuleFromTcpEntry)
+	}
+	return getOwnerModule(rawRow, getOwnerModuleFromTcp6Entry)
+}
+
+// Uses GetOwnerModuleFromUdpEntry or GetOwnerModuleFromUdp6Entry function
+// (https://docs.microsoft.com/en-us/windows/desktop/api/iphlpapi/nf-iphlpapi-getownermodulefromudpentry).
+func getUdpOwnerModule(family AddressFamily, rawRow []byte) (name, path string) {
+	if family == AF_INET {
+		return getOwnerModule(rawRow, getOwnerModuleFromUdpEntry)
+	}
+	return getOwnerModule(rawRow, getOwnerModuleFromUdp6Entry)
+}
@@ -38,6 +38,8 @@ func errnoErr(e syscall.Errno) error {
 
 var (
 	modiphlpapi = windows.NewLazySystemDLL("iphlpapi.dll")
+	modole32    = windows.NewLazySystemDLL("ole32.dll")
+	modnetshell = windows.NewLazySystemDLL("netshell.dll")
 
 	procGetAdaptersAddresses            = modiphlpapi.NewProc("GetAdaptersAddresses")
 	procInitializeIpInterfaceEntry      = modiphlpapi.NewProc("InitializeIpInterfaceEntry")
@@ -49,6 +51,10 @@ var (
 	procGetIfTable2Ex                   = modiphlpapi.NewProc("GetIfTable2Ex")
 	procConvertInterfaceLuidToGuid      = modiphlpapi.NewProc("ConvertInterfaceLuidToGuid")
 	procConvertInterfaceGuidToLuid      = modiphlpapi.NewProc("ConvertInterfaceGuidToLuid")
+	procGetIfStackTable                 = modiphlpapi.NewProc("GetIfStackTable")
+	procGetInvertedIfStackTable         = modiphlpapi.NewProc("GetInvertedIfStackTable")
+	procGetCurrentThreadCompartmentId   = modiphlpapi.NewProc("GetCurrentThreadCompartmentId")
+	procSetCurrentThreadCompartmentId   = modiphlpapi.NewProc("SetCurrentThreadCompartmentId")
 	procGetUnicastIpAddressTable        = modiphlpapi.NewProc("GetUnicastIpAddressTable")
 	procGetUnicastIpAddressEntry        = modiphlpapi.NewProc("GetUnicastIpAddressEntry")
 	procSetUnicastIpAddressEntry        = modiphlpapi.NewProc("SetUnicastIpAddressEntry")
@@ -59,16 +65,35 @@ var (
 	procGetAnycastIpAddressEntry        = modiphlpapi.NewProc("GetAnycastIpAddressEntry")
 	procCreateAnycastIpAddressEntry     = modiphlpapi.NewProc("CreateAnycastIpAddressEntry")
 	procDeleteAnycastIpAddressEntry     = modiphlpapi.NewProc("DeleteAnycastIpAddressEntry")
+	procGetMulticastIpAddressTable      = modiphlpapi.NewProc("GetMulticastIpAddressTable")
+	procGetMulticastIpAddressEntry      = modiphlpapi.NewProc("GetMulticastIpAddressEntry")
 	procGetIpForwardTable2              = modiphlpapi.NewProc("GetIpForwardTable2")
 	procGetIpForwardEntry2              = modiphlpapi.NewProc("GetIpForwardEntry2")
 	procInitializeIpForwardEntry        = modiphlpapi.NewProc("InitializeIpForwardEntry")
 	procCreateIpForwardEntry2           = modiphlpapi.NewProc("CreateIpForwardEntry2")
 	procSetIpForwardEntry2              = modiphlpapi.NewProc("SetIpForwardEntry2")
 	procDeleteIpForwardEntry2           = modiphlpapi.NewProc("DeleteIpForwardEntry2")
+	procGetIpPathTable                  = modiphlpapi.NewProc("GetIpPathTable")
+	procGetIpPathEntry                  = modiphlpapi.NewProc("GetIpPathEntry")
+	procFlushIpPathTable                = modiphlpapi.NewProc("FlushIpPathTable")
+	procCreateSortedAddressPairs        = modiphlpapi.NewProc("CreateSortedAddressPairs")
+	procGetIpStatisticsEx               = modiphlpapi.NewProc("GetIpStatisticsEx")
+	procGetIcmpStatisticsEx             = modiphlpapi.NewProc("GetIcmpStatisticsEx")
+	procGetTcpStatisticsEx2             = modiphlpapi.NewProc("GetTcpStatisticsEx2")
+	procGetUdpStatisticsEx2             = modiphlpapi.NewProc("GetUdpStatisticsEx2")
+	procGetExtendedTcpTable             = modiphlpapi.NewProc("GetExtendedTcpTable")
+	procGetExtendedUdpTable             = modiphlpapi.NewProc("GetExtendedUdpTable")
+	procGetOwnerModuleFromTcpEntry      = modiphlpapi.NewProc("GetOwnerModuleFromTcpEntry")
+	procGetOwnerModuleFromTcp6Entry     = modiphlpapi.NewProc("GetOwnerModuleFromTcp6Entry")
+	procGetOwnerModuleFromUdpEntry      = modiphlpapi.NewProc("GetOwnerModuleFromUdpEntry")
+	procGetOwnerModuleFromUdp6Entry     = modiphlpapi.NewProc("GetOwnerModuleFromUdp6Entry")
+	procGetTeredoPort                   = modiphlpapi.NewProc("GetTeredoPort")
 	procNotifyIpInterfaceChange         = modiphlpapi.NewProc("NotifyIpInterfaceChange")
 	procNotifyUnicastIpAddressChange    = modiphlpapi.NewProc("NotifyUnicastIpAddressChange")
 	procNotifyRouteChange2              = modiphlpapi.NewProc("NotifyRouteChange2")
 	procCancelMibChangeNotify2          = modiphlpapi.NewProc("CancelMibChangeNotify2")
+	procCoCreateInstance                = modole32.NewProc("CoCreateInstance")
+	procNcFreeNetconProperties          = modnetshell.NewProc("NcFreeNetconProperties")
 )
 
 func getAdaptersAddresses(Family uint32, Flags uint32, Reserved uintptr, AdapterAddresses *wtIpAdapterAddresses, SizePointer *uint32) (result uint32) {
@@ -130,6 +155,30 @@ func convertInterfaceGuidToLuid(InterfaceGuid *windows.GUID, InterfaceLuid *uint
 	return
 }
 
+func getIfStackTable(Table unsafe.Pointer) (result int32) {
+	r0, _, _ := syscall.Syscall(procGetIfStackTable.Addr(), 1, uintptr(Table), 0, 0)
+	result = int32(r0)
+	return
+}
+
+func getInvertedIfStackTable(Table unsafe.Pointer) (result int32) {
+	r0, _, _ := syscall.Syscall(procGetInvertedIfStackTable.Addr(), 1, uintptr(Table), 0, 0)
+	result = int32(r0)
+	return
+}
+
+func getCurrentThreadCompartmentId() (compartmentId uint32) {
+	r0, _, _ := syscall.Syscall(procGetCurrentThreadCompartmentId.Addr(), 0, 0, 0, 0)
+	compartmentId = uint32(r0)
+	return
+}
+
+func setCurrentThreadCompartmentId(CompartmentId uint32) (result uint32) {
+	r0, _, _ := syscall.Syscall(procSetCurrentThreadCompartmentId.Addr(), 1, uintptr(CompartmentId), 0, 0)
+	result = uint32(r0)
+	return
+}
+
 func getUnicastIpAddressTable(Family AddressFamily, Table unsafe.Pointer) (result int32) {
 	r0, _, _ := syscall.Syscall(procGetUnicastIpAddressTable.Addr(), 2, uintptr(Family), uintptr(Table), 0)
 	result = int32(r0)
@@ -190,6 +239,18 @@ func deleteAnycastIpAddressEntry(Row *wtMibAnycastipaddressRow) (result int32) {
 	return
 }
 
+func getMulticastIpAddressTable(Family AddressFamily, Table unsafe.Pointer) (result int32) {
+	r0, _, _ := syscall.Syscall(procGetMulticastIpAddressTable.Addr(), 2, uintptr(Family), uintptr(Table), 0)
+	result = int32(r0)
+	return
+}
+
+func getMulticastIpAddressEntry(Row *wtMibMulticastipaddressRow) (result int32) {
+	r0, _, _ := syscall.Syscall(procGetMulticastIpAddressEntry.Addr(), 1, uintptr(unsafe.Pointer(Row)), 0, 0)
+	result = int32(r0)
+	return
+}
+
 func getIpForwardTable2(family AddressFamily, table unsafe.Pointer) (result int32) {
 	r0, _, _ := syscall.Syscall(procGetIpForwardTable2.Addr(), 2, uintptr(family), uintptr(table), 0)
 	result = int32(r0)
@@ -226,6 +287,108 @@ func deleteIpForwardEntry2(route *wtMibIpforwardRow2) (result int32) {
 	return
 }
 
+func getIpPathTable(Family AddressFamily, Table unsafe.Pointer) (result int32) {
+	r0, _, _ := syscall.Syscall(procGetIpPathTable.Addr(), 2, uintptr(Family), uintptr(Table), 0)
+	result = int32(r0)
+	return
+}
+
+func getIpPathEntry(Row *wtMibIppathRow) (result int32) {
+	r0, _, _ := syscall.Syscall(procGetIpPathEntry.Addr(), 1, uintptr(unsafe.Pointer(Row)), 0, 0)
+	result = int32(r0)
+	return
+}
+
+func flushIpPathTable(Family AddressFamily) (result int32) {
+	r0, _, _ := syscall.Syscall(procFlushIpPathTable.Addr(), 1, uintptr(Family), 0, 0)
+	result = int32(r0)
+	return
+}
+
+func createSortedAddressPairs(SourceAddressList *wtSockaddrIn6, SourceAddressCount uint32, DestinationAddressList *wtSockaddrIn6, DestinationAddressCount uint32, AddressSortOptions uint32, SortedAddressPairList unsafe.Pointer, SortedAddressPairCount *uint32) (result int32) {
+	r0, _, _ := syscall.Syscall9(procCreateSortedAddressPairs.Addr(), 7, uintptr(unsafe.Pointer(SourceAddressList)), uintptr(SourceAddressCount), uintptr(unsafe.Pointer(DestinationAddressList)), uintptr(DestinationAddressCount), uintptr(AddressSortOptions), uintptr(SortedAddressPairList), uintptr(unsafe.Pointer(SortedAddressPairCount)), 0, 0)
+	result = int32(r0)
+	return
+}
+
+func getIpStatisticsEx(Statistics *wtMibIpstats, Family AddressFamily) (result uint32) {
+	r0, _, _ := syscall.Syscall(procGetIpStatisticsEx.Addr(), 2, uintptr(unsafe.Pointer(Statistics)), uintptr(Family), 0)
+	result = uint32(r0)
+	return
+}
+
+func getIcmpStatisticsEx(Statistics *wtMibIcmpEx, Family AddressFamily) (result uint32) {
+	r0, _, _ := syscall.Syscall(procGetIcmpStatisticsEx.Addr(), 2, uintptr(unsafe.Pointer(Statistics)), uintptr(Family), 0)
+	result = uint32(r0)
+	return
+}
+
+func getTcpStatisticsEx2(Statistics *wtMibTcpstats2, Family AddressFamily) (result uint32) {
+	r0, _, _ := syscall.Syscall(procGetTcpStatisticsEx2.Addr(), 2, uintptr(unsafe.Pointer(Statistics)), uintptr(Family), 0)
+	result = uint32(r0)
+	return
+}
+
+func getUdpStatisticsEx2(Statistics *wtMibUdpstats2, Family AddressFamily) (result uint32) {
+	r0, _, _ := syscall.Syscall(procGetUdpStatisticsEx2.Addr(), 2, uintptr(unsafe.Pointer(Statistics)), uintptr(Family), 0)
+	result = uint32(r0)
+	return
+}
+
+func getExtendedTcpTable(TcpTable unsafe.Pointer, Size *uint32, Order bool, Af uint32, TableClass uint32, Reserved uint32) (result uint32) {
+	var _p0 uint32
+	if Order {
+		_p0 = 1
+	} else {
+		_p0 = 0
+	}
+	r0, _, _ := syscall.Syscall6(procGetExtendedTcpTable.Addr(), 6, uintptr(TcpTable), uintptr(unsafe.Pointer(Size)), uintptr(_p0), uintptr(Af), uintptr(TableClass), uintptr(Reserved))
+	result = uint32(r0)
+	return
+}
+
+func getExtendedUdpTable(UdpTable unsafe.Pointer, Size *uint32, Order bool, Af uint32, TableClass uint32, Reserved uint32) (result uint32) {
+	var _p0 uint32
+	if Order {
+		_p0 = 1
+	} else {
+		_p0 = 0
+	}
+	r0, _, _ := syscall.Syscall6(procGetExtendedUdpTable.Addr(), 6, uintptr(UdpTable), uintptr(unsafe.Pointer(Size)), uintptr(_p0), uintptr(Af), uintptr(TableClass), uintptr(Reserved))
+	result = uint32(r0)
+	return
+}
+
+func getOwnerModuleFromTcpEntry(TcpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) {
+	r0, _, _ := syscall.Syscall6(procGetOwnerModuleFromTcpEntry.Addr(), 4, uintptr(TcpEntry), uintptr(Class), uintptr(Buffer), uintptr(unsafe.Pointer(Size)), 0, 0)
+	result = uint32(r0)
+	return
+}
+
+func getOwnerModuleFromTcp6Entry(TcpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) {
+	r0, _, _ := syscall.Syscall6(procGetOwnerModuleFromTcp6Entry.Addr(), 4, uintptr(TcpEntry), uintptr(Class), uintptr(Buffer), uintptr(unsafe.Pointer(Size)), 0, 0)
+	result = uint32(r0)
+	return
+}
+
+func getOwnerModuleFromUdpEntry(UdpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) {
+	r0, _, _ := syscall.Syscall6(procGetOwnerModuleFromUdpEntry.Addr(), 4, uintptr(UdpEntry), uintptr(Class), uintptr(Buffer), uintptr(unsafe.Pointer(Size)), 0, 0)
+	result = uint32(r0)
+	return
+}
+
+func getOwnerModuleFromUdp6Entry(UdpEntry unsafe.Pointer, Class uint32, Buffer unsafe.Pointer, Size *uint32) (result uint32) {
+	r0, _, _ := syscall.Syscall6(procGetOwnerModuleFromUdp6Entry.Addr(), 4, uintptr(UdpEntry), uintptr(Class), uintptr(Buffer), uintptr(unsafe.Pointer(Size)), 0, 0)
+	result = uint32(r0)
+	return
+}
+
+func getTeredoPort(Port *uint16) (result int32) {
+	r0, _, _ := syscall.Syscall(procGetTeredoPort.Addr(), 1, uintptr(unsafe.Pointer(Port)), 0, 0)
+	result = int32(r0)
+	return
+}
+
 func notifyIpInterfaceChange(Family AddressFamily, Callback uintptr, CallerContext uintptr, InitialNotification bool, NotificationHandle unsafe.Pointer) (result int32) {
 	var _p0 uint32
 	if InitialNotification {
@@ -267,3 +430,14 @@ func cancelMibChangeNotify2(NotificationHandle uintptr) (result int32) {
 	result = int32(r0)
 	return
 }
+
+func coCreateInstance(Clsid *windows.GUID, Outer uintptr, ClsContext uint32, Iid *windows.GUID, Object unsafe.Pointer) (result uint32) {
+	r0, _, _ := syscall.Syscall6(procCoCreateInstance.Addr(), 5, uintptr(unsafe.Pointer(Clsid)), uintptr(Outer), uintptr(ClsContext), uintptr(unsafe.Pointer(Iid)), uintptr(Object), 0)
+	result = uint32(r0)
+	return
+}
+
+func ncFreeNetconProperties(Props *wtNetconProperties) {
+	syscall.Syscall(procNcFreeNetconProperties.Addr(), 1, uintptr(unsafe.Pointer(Props)), 0, 0)
+	return
+}